@@ -0,0 +1,419 @@
+package epaxos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bbengfort/epaxos/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// QuotaBucketLimit is the number of client identities tracked by the quotas before
+// the buckets of idle clients, then of the least recently used clients, are discarded.
+const QuotaBucketLimit = 4096
+
+// Metadata key and scheme used by clients to send their token with each request.
+const (
+	metadataAuthorization = "authorization"
+	bearerScheme          = "Bearer "
+)
+
+// ClientPolicy defines the token that identifies a client and the operations that
+// the client is allowed to propose, as well as the rate at which it may propose them.
+type ClientPolicy struct {
+	Identity string   `json:"identity"`           // the name of the client for authorization and logging
+	Token    string   `json:"token"`              // secret the client sends to authenticate itself
+	Prefixes []string `json:"prefixes,omitempty"` // key prefixes the client may access, all keys if empty
+	Access   []string `json:"access,omitempty"`   // access types the client may propose, all types if empty
+	Rate     float64  `json:"rate,omitempty"`     // proposals per second, overrides client_rate if set
+	Burst    int      `json:"burst,omitempty"`    // maximum burst of proposals, overrides client_burst if set
+	Admin    bool     `json:"admin,omitempty"`    // the client may use the admin API, pause replicas and change the membership
+}
+
+// Validate that the policy has an identity and token and that access types exist.
+func (p *ClientPolicy) Validate() error {
+	if p.Identity == "" || p.Token == "" {
+		return errors.New("client policies require an identity and a token")
+	}
+
+	for _, access := range p.Access {
+		if _, ok := pb.AccessType_value[strings.ToUpper(access)]; !ok {
+			return fmt.Errorf("unknown access type %q for client %q", access, p.Identity)
+		}
+	}
+
+	if p.Rate < 0 || p.Burst < 0 {
+		return fmt.Errorf("rate and burst for client %q must not be negative", p.Identity)
+	}
+
+	return nil
+}
+
+//===========================================================================
+// Authentication and Authorization
+//===========================================================================
+
+// Authenticator identifies the client making a propose request from the request
+// context, e.g. from a token in the gRPC metadata or the client's TLS certificate.
+// Authenticators are called concurrently by the gRPC handlers and must be thread safe.
+type Authenticator interface {
+	Authenticate(ctx context.Context, req *pb.ProposeRequest) (identity string, err error)
+}
+
+// Authorizer allows or denies an operation proposed by an authenticated client, or the
+// use of the admin API, by returning an error if the client is not allowed to do so.
+// Authorizers are called concurrently by the gRPC handlers and must be thread safe.
+type Authorizer interface {
+	Authorize(identity string, op *pb.Operation) error
+	AuthorizeAdmin(identity string) error
+}
+
+// SetAuthenticator specifies how clients are identified, replacing the authenticator
+// created from the configuration. Must be called before the replica starts listening.
+func (r *Replica) SetAuthenticator(authn Authenticator) {
+	r.authn = authn
+}
+
+// SetAuthorizer specifies which operations clients may propose, replacing the
+// authorizer created from the configuration. Must be called before the replica
+// starts listening.
+func (r *Replica) SetAuthorizer(authz Authorizer) {
+	r.authz = authz
+}
+
+// Authenticate and authorize the propose request and check the client's quota before
+// the request is dispatched to the event loop. Requests to replicas that have been
+// removed from the quorum are unavailable so that clients fail over to a member. The
+// identity of the request is prefixed with the authenticated identity so that the
+// replica does not trust the client's claim, while clients that share an identity still
+// have distinct sessions.
+func (r *Replica) admit(ctx context.Context, req *pb.ProposeRequest) (err error) {
+	r.members.RLock()
+	_, member := r.config.lookupPeer(r.Name)
+	r.members.RUnlock()
+
+	if !member {
+		return status.Error(codes.Unavailable, ErrRemoved.Error())
+	}
+
+	var identity string
+	if identity, err = r.authn.Authenticate(ctx, req); err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	ops := req.Txn
+	if len(ops) == 0 {
+		if req.Op == nil {
+			return status.Error(codes.InvalidArgument, "propose request does not contain an operation")
+		}
+		ops = []*pb.Operation{req.Op}
+	}
+
+	for _, op := range ops {
+		if op == nil || op.Type == pb.AccessType_EXPIRE {
+			return status.Error(codes.InvalidArgument, "propose request contains an invalid operation")
+		}
+
+		// Membership changes cannot be part of a transaction
+		if op.Type == pb.AccessType_RECONFIGURE && len(ops) > 1 {
+			return status.Error(codes.InvalidArgument, "membership changes cannot be proposed in a transaction")
+		}
+
+		if err = r.authz.Authorize(identity, op); err != nil {
+			return status.Error(codes.PermissionDenied, err.Error())
+		}
+	}
+
+	if !r.quotas.Allow(identity) {
+		return status.Errorf(codes.ResourceExhausted, "client %q has exceeded its proposal quota", identity)
+	}
+
+	if req.Identity != identity {
+		req.Identity = identity + "/" + req.Identity
+	}
+	return nil
+}
+
+// Authenticate the client of an admin request and check that it may use the admin API.
+func (r *Replica) admitAdmin(ctx context.Context) error {
+	identity, err := r.authn.Authenticate(ctx, &pb.ProposeRequest{})
+	if err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if err = r.authz.AuthorizeAdmin(identity); err != nil {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return nil
+}
+
+// NewPolicies creates the authenticator and authorizer for the client policies in the
+// configuration. If no policies are configured, clients are trusted to identify
+// themselves and are allowed to propose any operation.
+func NewPolicies(config *Config) (*Policies, error) {
+	policies := &Policies{tokens: make(map[string]*ClientPolicy, len(config.Clients))}
+	for idx := range config.Clients {
+		policy := &config.Clients[idx]
+		if err := policy.Validate(); err != nil {
+			return nil, err
+		}
+
+		if _, ok := policies.tokens[policy.Token]; ok {
+			return nil, fmt.Errorf("client %q does not have a unique token", policy.Identity)
+		}
+		policies.tokens[policy.Token] = policy
+	}
+
+	policies.identities = make(map[string]*ClientPolicy, len(policies.tokens))
+	for _, policy := range policies.tokens {
+		policies.identities[policy.Identity] = policy
+	}
+
+	return policies, nil
+}
+
+// Policies implements the Authenticator and Authorizer interfaces using the client
+// policies defined in the configuration. Clients authenticate by sending their token
+// as a bearer token in the authorization metadata of the request.
+type Policies struct {
+	tokens     map[string]*ClientPolicy // client policies by token
+	identities map[string]*ClientPolicy // client policies by identity
+}
+
+// Authenticate the client by its bearer token. If there are no policies, the identity
+// claimed by the client in the request is returned.
+func (p *Policies) Authenticate(ctx context.Context, req *pb.ProposeRequest) (string, error) {
+	if len(p.tokens) == 0 {
+		return req.Identity, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	token := first(md, metadataAuthorization)
+	if !strings.HasPrefix(token, bearerScheme) {
+		return "", errors.New("request does not contain a bearer token")
+	}
+
+	policy, ok := p.tokens[strings.TrimPrefix(token, bearerScheme)]
+	if !ok {
+		return "", errors.New("invalid bearer token")
+	}
+	return policy.Identity, nil
+}
+
+// Authorize the operation if the policy for the identity allows the access type and
+// the key has one of the allowed prefixes. If there are no policies, all operations
+// are allowed, except PAUSE operations, which require a policy that grants admin access,
+// and RECONFIGURE operations, which require admin access or an explicit grant.
+func (p *Policies) Authorize(identity string, op *pb.Operation) error {
+	switch op.Type {
+	case pb.AccessType_PAUSE:
+		// Pausing a replica delays the requests of every client
+		return p.AuthorizeAdmin(identity)
+	case pb.AccessType_RECONFIGURE:
+		// The key of a membership change is the action rather than a key of the store
+		return p.authorizeReconfigure(identity)
+	}
+
+	if len(p.tokens) == 0 {
+		return nil
+	}
+
+	policy, ok := p.identities[identity]
+	if !ok {
+		return fmt.Errorf("no policy for client %q", identity)
+	}
+
+	if len(policy.Access) > 0 {
+		allowed := false
+		for _, access := range policy.Access {
+			if strings.ToUpper(access) == op.Type.String() {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
+			return fmt.Errorf("client %q is not allowed to %s", identity, op.Type)
+		}
+	}
+
+	if len(policy.Prefixes) > 0 {
+		allowed := false
+		for _, prefix := range policy.Prefixes {
+			if strings.HasPrefix(op.Key, prefix) {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
+			return fmt.Errorf("client %q is not allowed to access key %q", identity, op.Key)
+		}
+	}
+
+	return nil
+}
+
+// AuthorizeAdmin allows the client to use the admin API if its policy grants admin
+// access. If there are no policies, clients cannot be identified, so it is denied.
+func (p *Policies) AuthorizeAdmin(identity string) error {
+	if len(p.tokens) == 0 {
+		return errors.New("admin access requires a client policy that grants it")
+	}
+
+	policy, ok := p.identities[identity]
+	if !ok {
+		return fmt.Errorf("no policy for client %q", identity)
+	}
+
+	if !policy.Admin {
+		return fmt.Errorf("client %q is not allowed to administer the cluster", identity)
+	}
+	return nil
+}
+
+// Membership changes are only allowed if the policy for the identity grants admin
+// access or explicitly lists the RECONFIGURE access type; an empty list of access
+// types does not allow them. If there are no policies, they are denied.
+func (p *Policies) authorizeReconfigure(identity string) error {
+	if len(p.tokens) == 0 {
+		return errors.New("membership changes require a client policy that grants them")
+	}
+
+	policy, ok := p.identities[identity]
+	if !ok {
+		return fmt.Errorf("no policy for client %q", identity)
+	}
+
+	if policy.Admin {
+		return nil
+	}
+
+	for _, access := range policy.Access {
+		if strings.ToUpper(access) == pb.AccessType_RECONFIGURE.String() {
+			return nil
+		}
+	}
+	return fmt.Errorf("client %q is not allowed to %s", identity, pb.AccessType_RECONFIGURE)
+}
+
+//===========================================================================
+// Client Quotas
+//===========================================================================
+
+// newQuotas creates per-identity rate limiters with the default rate and burst from
+// the configuration, overridden by the rate and burst of any client policies.
+func newQuotas(config *Config) *quotas {
+	q := &quotas{
+		rate:      config.ClientRate,
+		burst:     config.ClientBurst,
+		overrides: make(map[string]*ClientPolicy),
+		buckets:   make(map[string]*bucket),
+	}
+
+	for idx := range config.Clients {
+		policy := &config.Clients[idx]
+		if policy.Rate > 0 || policy.Burst > 0 {
+			q.overrides[policy.Identity] = policy
+		}
+	}
+
+	return q
+}
+
+// quotas limits the rate of proposals from each client identity using token buckets
+// so that a single client cannot overwhelm the event loop. A rate of zero is unlimited.
+type quotas struct {
+	sync.Mutex
+	rate      float64                  // default proposals per second per identity
+	burst     int                      // default maximum burst per identity
+	overrides map[string]*ClientPolicy // policies that override the rate or burst
+	buckets   map[string]*bucket       // the current bucket for each identity
+}
+
+// bucket is a token bucket that is refilled at the rate up to the burst.
+type bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// Allow returns true if the identity has a token available and consumes it.
+func (q *quotas) Allow(identity string) bool {
+	q.Lock()
+	defer q.Unlock()
+
+	b, ok := q.buckets[identity]
+	if !ok {
+		rate, burst := q.rate, q.burst
+		if policy, ok := q.overrides[identity]; ok {
+			if policy.Rate > 0 {
+				rate = policy.Rate
+			}
+			if policy.Burst > 0 {
+				burst = policy.Burst
+			}
+		}
+
+		if rate <= 0 {
+			return true
+		}
+
+		if len(q.buckets) >= QuotaBucketLimit {
+			q.sweep()
+		}
+
+		// The burst must allow at least one proposal
+		if burst < 1 {
+			burst = 1
+		}
+
+		b = &bucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+		q.buckets[identity] = b
+	}
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+// Discard the buckets that have refilled completely since they are equivalent to a
+// new bucket for the identity. If every bucket is still refilling, the bucket that was
+// used least recently is discarded so that the number of buckets stays bounded. Must
+// be called while holding the lock.
+func (q *quotas) sweep() {
+	now := time.Now()
+	for identity, b := range q.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*b.rate >= b.burst {
+			delete(q.buckets, identity)
+		}
+	}
+
+	for len(q.buckets) >= QuotaBucketLimit {
+		var oldest string
+		var last time.Time
+		for identity, b := range q.buckets {
+			if last.IsZero() || b.last.Before(last) {
+				oldest, last = identity, b.last
+			}
+		}
+		delete(q.buckets, oldest)
+	}
+}
@@ -0,0 +1,173 @@
+package epaxos_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/bbengfort/epaxos"
+	"github.com/bbengfort/epaxos/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var _ = Describe("Client Access", func() {
+
+	var config *Config
+
+	BeforeEach(func() {
+		config = &Config{
+			Clients: []ClientPolicy{
+				{Identity: "reader", Token: "r3ad", Access: []string{"read"}},
+				{Identity: "tenant", Token: "t3nant", Prefixes: []string{"tenant/"}, Rate: 1, Burst: 1},
+			},
+		}
+	})
+
+	Describe("Policies", func() {
+
+		var policies *Policies
+
+		// Creates an incoming request context with the bearer token.
+		bearer := func(token string) context.Context {
+			return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+		}
+
+		BeforeEach(func() {
+			var err error
+			policies, err = NewPolicies(config)
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should trust clients if no policies are configured", func() {
+			policies, err := NewPolicies(&Config{})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			req := &pb.ProposeRequest{Identity: "anonymous", Op: &pb.Operation{Type: pb.AccessType_DELETE, Key: "foo"}}
+			Ω(policies.Authenticate(context.Background(), req)).Should(Equal("anonymous"))
+			Ω(policies.Authorize("anonymous", req.Op)).Should(Succeed())
+		})
+
+		It("should authenticate clients by bearer token", func() {
+			req := &pb.ProposeRequest{Identity: "tenant"}
+			Ω(policies.Authenticate(bearer("r3ad"), req)).Should(Equal("reader"))
+
+			_, err := policies.Authenticate(context.Background(), req)
+			Ω(err).Should(HaveOccurred())
+
+			_, err = policies.Authenticate(bearer("wrong"), req)
+			Ω(err).Should(HaveOccurred())
+		})
+
+		It("should authorize operations by access type", func() {
+			Ω(policies.Authorize("reader", &pb.Operation{Type: pb.AccessType_READ, Key: "foo"})).Should(Succeed())
+			Ω(policies.Authorize("reader", &pb.Operation{Type: pb.AccessType_WRITE, Key: "foo"})).ShouldNot(Succeed())
+		})
+
+		It("should authorize operations by key prefix", func() {
+			Ω(policies.Authorize("tenant", &pb.Operation{Type: pb.AccessType_WRITE, Key: "tenant/foo"})).Should(Succeed())
+			Ω(policies.Authorize("tenant", &pb.Operation{Type: pb.AccessType_WRITE, Key: "other/foo"})).ShouldNot(Succeed())
+			Ω(policies.Authorize("unknown", &pb.Operation{Type: pb.AccessType_READ, Key: "tenant/foo"})).ShouldNot(Succeed())
+		})
+
+		It("should only allow membership changes that are explicitly granted", func() {
+			add := &pb.Operation{Type: pb.AccessType_RECONFIGURE, Key: MembershipAdd}
+			config.Clients = append(config.Clients,
+				ClientPolicy{Identity: "any", Token: "4ny", Prefixes: []string{"a", ""}},
+				ClientPolicy{Identity: "operator", Token: "0p", Access: []string{"reconfigure"}, Prefixes: []string{"tenant/"}},
+				ClientPolicy{Identity: "admin", Token: "4dm1n", Admin: true},
+			)
+
+			policies, err := NewPolicies(config)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(policies.Authorize("any", add)).Should(MatchError(`client "any" is not allowed to RECONFIGURE`))
+			Ω(policies.Authorize("tenant", add)).ShouldNot(Succeed())
+			Ω(policies.Authorize("operator", add)).Should(Succeed())
+			Ω(policies.Authorize("admin", add)).Should(Succeed())
+
+			// Membership changes cannot be proposed if clients cannot be identified
+			policies, err = NewPolicies(&Config{})
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(policies.Authorize("anonymous", add)).ShouldNot(Succeed())
+		})
+
+		It("should not validate unknown access types", func() {
+			config.Clients[0].Access = []string{"EXPLODE"}
+			Ω(config.Validate()).Should(HaveOccurred())
+
+			_, err := NewPolicies(config)
+			Ω(err).Should(HaveOccurred())
+		})
+
+		It("should require unique tokens", func() {
+			config.Clients[1].Token = config.Clients[0].Token
+			_, err := NewPolicies(config)
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+
+	Describe("Propose", func() {
+
+		var (
+			port = 44266
+			conn *grpc.ClientConn
+		)
+
+		// Proposes the operation to the replica with the bearer token.
+		propose := func(token string, op *pb.Operation) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+			_, err := pb.NewEpaxosClient(conn).Propose(ctx, &pb.ProposeRequest{Identity: "liar", Op: op})
+			return err
+		}
+
+		BeforeEach(func() {
+			data, err := ioutil.ReadFile("testdata/config.json")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(json.Unmarshal(data, &config)).Should(Succeed())
+			config.Peers = config.Peers[:3]
+			config.LogLevel = int(LogSilent)
+			config.Aggregate = false
+			config.Peers[1].Port = uint16(port)
+
+			replica, err := New(config)
+			Ω(err).ShouldNot(HaveOccurred())
+			serve(replica)
+
+			conn, err = grpc.Dial(fmt.Sprintf("127.0.0.1:%d", port), grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(2*time.Second))
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			conn.Close()
+		})
+
+		It("should reject unauthenticated and unauthorized clients", func() {
+			err := propose("wrong", &pb.Operation{Type: pb.AccessType_READ, Key: "foo"})
+			Ω(status.Code(err)).Should(Equal(codes.Unauthenticated))
+
+			err = propose("r3ad", &pb.Operation{Type: pb.AccessType_WRITE, Key: "foo"})
+			Ω(status.Code(err)).Should(Equal(codes.PermissionDenied))
+		})
+
+		It("should limit the rate of proposals per identity", func() {
+			// The first proposal is admitted but cannot commit without a quorum
+			op := &pb.Operation{Type: pb.AccessType_WRITE, Key: "tenant/foo"}
+			Ω(status.Code(propose("t3nant", op))).Should(Equal(codes.DeadlineExceeded))
+			Ω(status.Code(propose("t3nant", op))).Should(Equal(codes.ResourceExhausted))
+
+			// Other clients are not limited by the noisy tenant
+			op = &pb.Operation{Type: pb.AccessType_READ, Key: "foo"}
+			Ω(status.Code(propose("r3ad", op))).Should(Equal(codes.DeadlineExceeded))
+		})
+	})
+})
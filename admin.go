@@ -0,0 +1,238 @@
+package epaxos
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/bbengfort/epaxos/pb"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//===========================================================================
+// Admin RPC Handlers
+//===========================================================================
+
+// Admin requests expose the operations and values of every instance, so they are only
+// served to clients whose policy grants admin access.
+
+// Status returns the replica's view of the quorum and the state of its 2D log. The
+// status is created by the event loop so that the log is not read while it's updated.
+func (r *Replica) Status(ctx context.Context, in *pb.StatusRequest) (*pb.StatusReply, error) {
+	if err := r.admitAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	source := make(chan *pb.StatusReply, 1)
+	if err := r.Dispatch(&event{etype: StatusRequestEvent, source: source, value: in}); err != nil {
+		return nil, err
+	}
+
+	select {
+	case out := <-source:
+		return out, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-r.done:
+		return nil, ErrNotListening
+	}
+}
+
+// Fetch returns a copy of the instance in the specified replica's log and slot.
+func (r *Replica) Fetch(ctx context.Context, in *pb.FetchRequest) (*pb.Instance, error) {
+	if err := r.admitAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	source := make(chan *fetched, 1)
+	if err := r.Dispatch(&event{etype: FetchRequestEvent, source: source, value: in}); err != nil {
+		return nil, err
+	}
+
+	var out *fetched
+	select {
+	case out = <-source:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-r.done:
+		return nil, ErrNotListening
+	}
+
+	// Truncated instances are reported with a distinct code so clients can skip them
+	if out.err == ErrTruncated {
+		return nil, status.Error(codes.OutOfRange, ErrTruncated.Error())
+	}
+	return out.inst, out.err
+}
+
+// The instance fetched from the log by the event loop or the reason it was not found.
+type fetched struct {
+	inst *pb.Instance
+	err  error
+}
+
+// Watch streams instances to the admin client every time an instance changes state on
+// the replica until the client disconnects or the replica stops. If the client cannot
+// keep up with the replica, notifications are dropped rather than blocking the event
+// loop.
+func (r *Replica) Watch(in *pb.WatchRequest, stream pb.Admin_WatchServer) (err error) {
+	if err = r.admitAdmin(stream.Context()); err != nil {
+		return err
+	}
+
+	watcher := make(chan *pb.Instance, MessageBufferSize)
+	if err = r.Dispatch(&event{etype: WatchRequestEvent, source: watcher, value: in}); err != nil {
+		return err
+	}
+
+	// The watcher is only removed by the event loop if the replica is still running
+	defer r.Dispatch(&event{etype: UnwatchRequestEvent, source: watcher})
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-r.done:
+			return ErrNotListening
+		case inst := <-watcher:
+			if err = stream.Send(inst); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Graph returns the dependency graph reachable from the specified instance as a
+// Graphviz DOT document to debug why instances are not being executed.
+func (r *Replica) Graph(ctx context.Context, in *pb.FetchRequest) (*pb.GraphReply, error) {
+	if err := r.admitAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	source := make(chan *pb.GraphReply, 1)
+	if err := r.Dispatch(&event{etype: GraphRequestEvent, source: source, value: in}); err != nil {
+		return nil, err
+	}
+
+	var out *pb.GraphReply
+	select {
+	case out = <-source:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-r.done:
+		return nil, ErrNotListening
+	}
+
+	if out == nil {
+		return nil, fmt.Errorf("no instance found for replica PID %d in slot %d", in.Replica, in.Slot)
+	}
+	return out, nil
+}
+
+//===========================================================================
+// Admin Event Handlers
+//===========================================================================
+
+func (r *Replica) onStatusRequest(e Event) (err error) {
+	source := e.Source().(chan *pb.StatusReply)
+	source <- &pb.StatusReply{
+		Pid:      r.PID,
+		Name:     r.Name,
+		Quorum:   r.quorum,
+		Thrifty:  r.thrifty,
+		Sequence: r.logs.Sequence(),
+		Slots:    r.logs.NextSlots(),
+		Executed: r.logs.Executed(),
+		Epoch:    r.epoch,
+		Members:  r.memberPIDs(),
+		Offsets:  r.logs.Offsets(),
+	}
+	return nil
+}
+
+func (r *Replica) onFetchRequest(e Event) (err error) {
+	req := e.Value().(*pb.FetchRequest)
+	source := e.Source().(chan *fetched)
+
+	// Do not return the error to the event loop, the client handles missing instances.
+	var inst *pb.Instance
+	if inst, err = r.logs.Get(req.Replica, req.Slot); err != nil {
+		source <- &fetched{err: err}
+		return nil
+	}
+
+	source <- &fetched{inst: proto.Clone(inst).(*pb.Instance)}
+	return nil
+}
+
+func (r *Replica) onGraphRequest(e Event) (err error) {
+	req := e.Value().(*pb.FetchRequest)
+	source := e.Source().(chan *pb.GraphReply)
+
+	// Do not return the error to the event loop, the client handles missing instances.
+	buf := new(bytes.Buffer)
+	if err = r.logs.WriteDOT(buf, req.Replica, req.Slot); err != nil {
+		source <- nil
+		return nil
+	}
+
+	source <- &pb.GraphReply{Dot: buf.String()}
+	return nil
+}
+
+func (r *Replica) onWatchRequest(e Event) (err error) {
+	r.watchers[e.Source().(chan *pb.Instance)] = e.Value().(*pb.WatchRequest)
+	return nil
+}
+
+func (r *Replica) onUnwatchRequest(e Event) (err error) {
+	delete(r.watchers, e.Source().(chan *pb.Instance))
+	return nil
+}
+
+//===========================================================================
+// Watcher Notifications
+//===========================================================================
+
+// Notify all watchers of the instance's log that the instance has changed state. The
+// instance is cloned once so that it can be marshaled by the watch streams outside of
+// the event loop without racing with further updates to the instance.
+func (r *Replica) notify(inst *pb.Instance) {
+	if len(r.watchers) == 0 {
+		return
+	}
+
+	var clone *pb.Instance
+	for watcher, req := range r.watchers {
+		if !watching(req, inst.Replica) {
+			continue
+		}
+
+		if clone == nil {
+			clone = proto.Clone(inst).(*pb.Instance)
+		}
+
+		select {
+		case watcher <- clone:
+		default:
+			r.log.Caution("dropped instance notification to slow admin watcher")
+		}
+	}
+}
+
+// returns true if the watch request includes the specified replica log.
+func watching(req *pb.WatchRequest, replica uint32) bool {
+	if len(req.Replicas) == 0 {
+		return true
+	}
+
+	for _, pid := range req.Replicas {
+		if pid == replica {
+			return true
+		}
+	}
+	return false
+}
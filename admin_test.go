@@ -0,0 +1,84 @@
+package epaxos_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/bbengfort/epaxos"
+	"github.com/bbengfort/epaxos/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var _ = Describe("Admin", func() {
+
+	It("should only serve clients whose policy grants admin access", func() {
+		network := runNetwork(58264, withAdmin, func(conf *Config) {
+			conf.Clients = append(conf.Clients, ClientPolicy{Identity: "tenant", Token: "t3nant", Prefixes: []string{""}})
+		})
+
+		client, err := NewClient("alpha", &Config{Timeout: "2s", Token: adminToken, Peers: network})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(client.Put("foo", []byte("bar"), false)).Should(Succeed())
+		Ω(client.Status()).ShouldNot(BeNil())
+
+		tenant, err := NewClient("alpha", &Config{Timeout: "2s", Token: "t3nant", Peers: network})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(tenant.Get("foo")).Should(Equal([]byte("bar")))
+
+		_, err = tenant.Status()
+		Ω(err).Should(MatchError(ContainSubstring(`client "tenant" is not allowed to administer the cluster`)))
+		_, err = tenant.Fetch(1, 0)
+		Ω(err).Should(MatchError(ContainSubstring("PermissionDenied")))
+		_, err = tenant.Graph(1, 0)
+		Ω(err).Should(MatchError(ContainSubstring("PermissionDenied")))
+	})
+
+	It("should deny admin access if there are no client policies", func() {
+		network := runNetwork(59264)
+		client, err := NewClient("alpha", &Config{Timeout: "2s", Peers: network})
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Eventually(func() error {
+			_, err := client.Status()
+			return err
+		}, "3s").Should(MatchError(ContainSubstring("admin access requires a client policy that grants it")))
+	})
+
+	It("should stop admin requests when the replica is closed", func() {
+		network := makeNetwork(57264, 3)
+		conf := &Config{Name: "alpha", Timeout: "500ms", LogLevel: int(LogSilent), Peers: network}
+		withAdmin(conf)
+		replica, err := New(conf)
+		Ω(err).ShouldNot(HaveOccurred())
+		stop := serve(replica)
+
+		conn, err := grpc.Dial(fmt.Sprintf("127.0.0.1:%d", network[0].Port), grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(2*time.Second))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer conn.Close()
+
+		admin := pb.NewAdminClient(conn)
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+adminToken)
+		stream, err := admin.Watch(ctx, &pb.WatchRequest{})
+		Ω(err).ShouldNot(HaveOccurred())
+
+		_, err = admin.Status(ctx, &pb.StatusRequest{})
+		Ω(err).ShouldNot(HaveOccurred())
+
+		// The watch stream ends and the deferred unwatch does not block or panic
+		Ω(replica.Close()).Should(Succeed())
+		_, err = stream.Recv()
+		Ω(err).Should(HaveOccurred())
+
+		// The replica stops serving requests once it has stopped listening
+		stop()
+		_, err = admin.Status(ctx, &pb.StatusRequest{})
+		Ω(status.Code(err)).Should(Equal(codes.Unavailable))
+	})
+})
@@ -0,0 +1,101 @@
+package epaxos
+
+import (
+	"context"
+
+	"github.com/bbengfort/epaxos/pb"
+)
+
+// DefaultWindow is the default maximum number of outstanding requests of a client,
+// including synchronous requests made concurrently. The window must not exceed the
+// number of results cached by client sessions.
+const DefaultWindow = 64
+
+// Future is the eventual reply to an asynchronous proposal. Futures are resolved
+// exactly once, when the proposal is executed or fails, and are safe to wait on
+// concurrently.
+type Future struct {
+	req   *pb.ProposeRequest // the request that was proposed
+	done  chan struct{}      // closed when the future is resolved
+	reply *pb.ProposeReply   // the reply from the replica, if successful
+	err   error              // the error proposing the request, if any
+}
+
+// Done returns a channel that is closed when the proposal has completed.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait for the proposal to complete and return the reply. If the context is done
+// before the proposal completes, the context's error is returned but the proposal
+// continues until the context used to propose it is done.
+func (f *Future) Wait(ctx context.Context) (*pb.ProposeReply, error) {
+	select {
+	case <-f.done:
+		return f.reply, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Result blocks until the proposal completes and returns the reply.
+func (f *Future) Result() (*pb.ProposeReply, error) {
+	<-f.done
+	return f.reply, f.err
+}
+
+// Sequence returns the client sequence number assigned to the proposal.
+func (f *Future) Sequence() uint64 {
+	return f.req.Sequence
+}
+
+// Resolve the future with the reply or error.
+func (f *Future) resolve(reply *pb.ProposeReply, err error) {
+	f.reply, f.err = reply, err
+	close(f.done)
+}
+
+//===========================================================================
+// Asynchronous Request API
+//===========================================================================
+
+// ProposeAsync proposes an operation without waiting for it to be executed and returns
+// a future for the reply. Requests are pipelined over the client's connection, so many
+// requests can be outstanding at once; if the configured window of outstanding requests
+// is full, ProposeAsync blocks until a request completes or the context is done. The
+// context applies to all attempts of the request, each of which is also limited by the
+// configured timeout.
+func (c *Client) ProposeAsync(ctx context.Context, access pb.AccessType, key string, value []byte) (*Future, error) {
+	// Wait for space in the window of outstanding requests
+	future := &Future{req: c.request(access, key, value), done: make(chan struct{})}
+	if err := c.acquire(ctx, future.req); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer c.release()
+		future.resolve(c.send(ctx, future.req))
+	}()
+
+	return future, nil
+}
+
+// GetAsync asynchronously reads the value of a key.
+func (c *Client) GetAsync(ctx context.Context, key string) (*Future, error) {
+	return c.ProposeAsync(ctx, pb.AccessType_READ, key, nil)
+}
+
+// PutAsync asynchronously writes the value of a key.
+func (c *Client) PutAsync(ctx context.Context, key string, value []byte) (*Future, error) {
+	return c.ProposeAsync(ctx, pb.AccessType_WRITE, key, value)
+}
+
+// DelAsync asynchronously deletes a key.
+func (c *Client) DelAsync(ctx context.Context, key string) (*Future, error) {
+	return c.ProposeAsync(ctx, pb.AccessType_DELETE, key, nil)
+}
+
+// Outstanding returns the number of requests that have not completed.
+func (c *Client) Outstanding() int {
+	return len(c.window)
+}
@@ -0,0 +1,80 @@
+package epaxos_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/bbengfort/epaxos"
+	"github.com/bbengfort/epaxos/pb"
+	"github.com/bbengfort/x/peers"
+)
+
+var _ = Describe("Async Client", func() {
+
+	It("should pipeline requests within the window", func() {
+		network := runNetwork(46264)
+		client, err := NewClient("alpha", &Config{Timeout: "2s", Window: 8, Peers: network})
+		Ω(err).ShouldNot(HaveOccurred())
+
+		futures := make([]*Future, 0, 100)
+		for i := 0; i < 100; i++ {
+			future, err := client.PutAsync(context.Background(), fmt.Sprintf("key%d", i%4), []byte(fmt.Sprintf("%d", i)))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(client.Outstanding()).Should(BeNumerically("<=", 8))
+			futures = append(futures, future)
+		}
+
+		for _, future := range futures {
+			rep, err := future.Result()
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(rep.Success).Should(BeTrue())
+		}
+
+		Ω(client.Outstanding()).Should(BeZero())
+		future, err := client.GetAsync(context.Background(), "key3")
+		Ω(err).ShouldNot(HaveOccurred())
+		rep, err := future.Wait(context.Background())
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(rep.Value).Should(Equal([]byte("99")))
+	})
+
+	It("should respect context cancellation", func() {
+		// A replica that never replies so that requests are outstanding until canceled
+		sock, err := net.Listen("tcp", "127.0.0.1:46299")
+		Ω(err).ShouldNot(HaveOccurred())
+		defer sock.Close()
+
+		network := []peers.Peer{{PID: 1, Name: "alpha", IPAddr: "127.0.0.1", Port: 46299}}
+		client, err := NewClient("alpha", &Config{Timeout: "100ms", Window: 1, Peers: network})
+		Ω(err).ShouldNot(HaveOccurred())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		future, err := client.ProposeAsync(ctx, pb.AccessType_WRITE, "foo", []byte("bar"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(client.Outstanding()).Should(Equal(1))
+
+		// The window is full, so the next proposal waits until its context is done
+		wctx, wcancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer wcancel()
+		_, err = client.ProposeAsync(wctx, pb.AccessType_WRITE, "foo", []byte("baz"))
+		Ω(err).Should(Equal(context.DeadlineExceeded))
+
+		// Synchronous proposals also wait for space in the window
+		_, err = client.ProposeContext(wctx, pb.AccessType_WRITE, "foo", []byte("baz"))
+		Ω(err).Should(Equal(context.DeadlineExceeded))
+
+		_, err = future.Wait(wctx)
+		Ω(err).Should(Equal(context.DeadlineExceeded))
+
+		cancel()
+		Eventually(future.Done()).Should(BeClosed())
+		_, err = future.Result()
+		Ω(err).Should(HaveOccurred())
+		Eventually(client.Outstanding).Should(BeZero())
+	})
+})
@@ -0,0 +1,206 @@
+package epaxos
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/bbengfort/epaxos/pb"
+	"github.com/bbengfort/x/peers"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// AuthenticationWindow is the maximum difference between the clocks of two replicas
+// for an HMAC signed consensus stream to be accepted. Tokens outside of this window
+// are rejected to limit the replay of captured tokens.
+const AuthenticationWindow = 30 * time.Second
+
+// Metadata keys used to authenticate consensus streams with the cluster key.
+const (
+	metadataPeer = "epaxos-peer"
+	metadataTime = "epaxos-time"
+	metadataMAC  = "epaxos-mac"
+)
+
+//===========================================================================
+// Peer Authentication
+//===========================================================================
+
+// Returns the name of the peer that opened the consensus stream. If TLS is enabled,
+// the peer is identified by its verified client certificate. Otherwise, if a cluster
+// key is configured, the peer is identified by the HMAC token in the stream metadata.
+// If neither is configured, peers cannot be authenticated and an empty string is
+// returned without error. Replicas that have been removed are authenticated while the
+// instances of the epochs they were members of have not been committed, see pending.
+func (r *Replica) authenticate(ctx context.Context) (string, error) {
+	r.members.RLock()
+	defer r.members.RUnlock()
+
+	if r.config.TLS.Enabled() {
+		p, ok := peer.FromContext(ctx)
+		if !ok {
+			return "", ErrUnauthenticated
+		}
+
+		info, ok := p.AuthInfo.(credentials.TLSInfo)
+		if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+			return "", ErrUnauthenticated
+		}
+
+		cert := info.State.VerifiedChains[0][0]
+		for _, name := range certNames(cert) {
+			if _, ok := r.pendingMember(name); ok {
+				return name, nil
+			}
+		}
+		return "", fmt.Errorf("certificate for %q does not identify a configured peer", cert.Subject.CommonName)
+	}
+
+	if r.config.ClusterKey != "" {
+		md, ok := metadata.FromIncomingContext(ctx)
+		if !ok {
+			return "", ErrUnauthenticated
+		}
+
+		sender, err := r.config.verifyToken(md)
+		if err != nil {
+			return "", err
+		}
+
+		if _, ok := r.pendingMember(sender); !ok {
+			return "", fmt.Errorf("unknown peer %q is not in the configuration", sender)
+		}
+		return sender, nil
+	}
+
+	return "", nil
+}
+
+// Verify that the peer request was sent by a configured peer that matches the
+// authenticated identity of the stream (if any) and that instances in the request
+// are led by the sender, e.g. a peer cannot propose instances on behalf of another.
+// Replicas that have been removed from the quorum may still send the instances they
+// led in the epochs that they were members of so that those instances are committed.
+func (r *Replica) verifyPeerRequest(identity string, in *pb.PeerRequest) error {
+	r.members.RLock()
+	defer r.members.RUnlock()
+
+	if identity != "" && identity != in.Sender {
+		return fmt.Errorf("peer authenticated as %q cannot send messages as %q", identity, in.Sender)
+	}
+
+	sender, member := r.config.lookupPeer(in.Sender)
+
+	var inst *pb.Instance
+	switch in.Type {
+	case pb.Type_PREACCEPT:
+		inst = in.GetPreaccept().GetInst()
+	case pb.Type_ACCEPT:
+		inst = in.GetAccept().GetInst()
+	case pb.Type_COMMIT:
+		inst = in.GetCommit().GetInst()
+	case pb.Type_BEACON:
+		// Beacons are sent whenever a removed replica reconnects to finish its instances
+		if !member {
+			_, member = r.formerMember(in.Sender)
+		}
+		fallthrough
+	default:
+		if !member {
+			return fmt.Errorf("unknown peer %q is not in the configuration", in.Sender)
+		}
+		return nil
+	}
+
+	if inst == nil {
+		return fmt.Errorf("%s message from %q does not contain an instance", in.Type, in.Sender)
+	}
+
+	if !member {
+		if sender, member = r.memberOf(inst.Epoch, in.Sender); !member {
+			return fmt.Errorf("unknown peer %q is not in the configuration of epoch %d", in.Sender, inst.Epoch)
+		}
+	}
+
+	if inst.Replica != sender.PID {
+		return fmt.Errorf("peer %q (PID %d) cannot lead instances for replica %d", in.Sender, sender.PID, inst.Replica)
+	}
+
+	return nil
+}
+
+//===========================================================================
+// Cluster Key Tokens
+//===========================================================================
+
+// Returns the metadata that authenticates the named peer with the cluster key when
+// opening a consensus stream, or nil if no cluster key is configured.
+func (c *Config) peerToken(sender string, ts time.Time) metadata.MD {
+	if c.ClusterKey == "" {
+		return nil
+	}
+
+	nanos := strconv.FormatInt(ts.UnixNano(), 10)
+	return metadata.Pairs(
+		metadataPeer, sender,
+		metadataTime, nanos,
+		metadataMAC, c.sign(sender, nanos),
+	)
+}
+
+// Verifies the HMAC token in the metadata and returns the name of the peer that signed
+// it. The caller must check that the peer is one of the replicas.
+func (c *Config) verifyToken(md metadata.MD) (string, error) {
+	sender, nanos, mac := first(md, metadataPeer), first(md, metadataTime), first(md, metadataMAC)
+	if sender == "" || nanos == "" || mac == "" {
+		return "", ErrUnauthenticated
+	}
+
+	if !hmac.Equal([]byte(mac), []byte(c.sign(sender, nanos))) {
+		return "", fmt.Errorf("invalid cluster key signature from %q", sender)
+	}
+
+	ts, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid token timestamp from %q", sender)
+	}
+
+	if skew := time.Since(time.Unix(0, ts)); skew > AuthenticationWindow || skew < -AuthenticationWindow {
+		return "", fmt.Errorf("token from %q is outside of the authentication window", sender)
+	}
+
+	return sender, nil
+}
+
+// Computes the hex encoded HMAC-SHA256 of the sender and timestamp.
+func (c *Config) sign(sender, nanos string) string {
+	mac := hmac.New(sha256.New, []byte(c.ClusterKey))
+	mac.Write([]byte(sender))
+	mac.Write([]byte{0})
+	mac.Write([]byte(nanos))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Returns the configured peer with the specified name.
+func (c *Config) lookupPeer(name string) (peers.Peer, bool) {
+	for _, peer := range c.Peers {
+		if peer.Name == name {
+			return peer, true
+		}
+	}
+	return peers.Peer{}, false
+}
+
+// Returns the first value of the metadata key or an empty string.
+func first(md metadata.MD, key string) string {
+	if vals := md.Get(key); len(vals) > 0 {
+		return vals[0]
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package epaxos_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/bbengfort/epaxos"
+	"github.com/bbengfort/epaxos/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var _ = Describe("Peer Authentication", func() {
+
+	var (
+		port   = 43266
+		config *Config
+		conn   *grpc.ClientConn
+	)
+
+	// Sends the request on a new consensus stream and returns the reply or error.
+	send := func(req *pb.PeerRequest) (*pb.PeerReply, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		stream, err := pb.NewEpaxosClient(conn).Consensus(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = stream.Send(req); err != nil {
+			return nil, err
+		}
+		return stream.Recv()
+	}
+
+	BeforeEach(func() {
+		data, err := ioutil.ReadFile("testdata/config.json")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(json.Unmarshal(data, &config)).Should(Succeed())
+		config.Peers = config.Peers[:3]
+		config.LogLevel = int(LogSilent)
+		config.Aggregate = false
+		config.Peers[1].Port = uint16(port)
+	})
+
+	JustBeforeEach(func() {
+		replica, err := New(config)
+		Ω(err).ShouldNot(HaveOccurred())
+		serve(replica)
+
+		conn, err = grpc.Dial(fmt.Sprintf("127.0.0.1:%d", port), grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(2*time.Second))
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		conn.Close()
+	})
+
+	It("should accept messages from configured peers", func() {
+		rep, err := send(pb.WrapBeaconRequest("alpha", &pb.BeaconRequest{}))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(rep.Sender).Should(Equal("bravo"))
+	})
+
+	It("should reject messages from unknown peers", func() {
+		_, err := send(pb.WrapBeaconRequest("mallory", &pb.BeaconRequest{}))
+		Ω(status.Code(err)).Should(Equal(codes.PermissionDenied))
+		Ω(err.Error()).Should(ContainSubstring("unknown peer \"mallory\""))
+	})
+
+	It("should reject instances led by another replica", func() {
+		inst := &pb.Instance{Replica: 3, Slot: 0, Seq: 1, Deps: map[uint32]uint64{}}
+		_, err := send(pb.WrapPreacceptRequest("alpha", &pb.PreacceptRequest{Inst: inst}))
+		Ω(status.Code(err)).Should(Equal(codes.PermissionDenied))
+		Ω(err.Error()).Should(ContainSubstring("cannot lead instances for replica 3"))
+	})
+
+	Context("with a cluster key", func() {
+
+		BeforeEach(func() {
+			config.ClusterKey = "supersecret"
+		})
+
+		It("should reject streams without a token", func() {
+			_, err := send(pb.WrapBeaconRequest("alpha", &pb.BeaconRequest{}))
+			Ω(status.Code(err)).Should(Equal(codes.Unauthenticated))
+		})
+
+	})
+})
@@ -0,0 +1,554 @@
+package epaxos
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bbengfort/epaxos/pb"
+)
+
+// Key distributions of generated workloads.
+const (
+	UniformDistribution = "uniform"
+	ZipfianDistribution = "zipfian"
+	HotspotDistribution = "hotspot"
+)
+
+// Defaults of generated workloads.
+const (
+	DefaultBenchmarkKeys = 1000
+	DefaultZipfianSkew   = 1.1
+	DefaultHotKeys       = 0.2
+	DefaultHotOps        = 0.8
+)
+
+// Workload describes the operations proposed by a benchmark and how they are issued.
+// Operations are either generated from the key distribution and the ratios of the
+// workload, or replayed from a trace of operations. Closed-loop clients propose the
+// next operation as soon as the previous one completes; if a rate is specified the
+// operations arrive open-loop at that rate regardless of how quickly they complete.
+type Workload struct {
+	Clients      int           `json:"clients"`            // number of concurrent clients
+	Requests     uint64        `json:"requests,omitempty"` // operations per client, unless a duration is specified
+	Duration     time.Duration `json:"duration,omitempty"` // run for a fixed time, unless requests are specified
+	Rate         float64       `json:"rate,omitempty"`     // target operations per second of all clients, closed-loop if zero
+	ReadRatio    float64       `json:"read_ratio"`         // fraction of generated operations that are reads
+	Conflict     float64       `json:"conflict"`           // fraction of generated operations on keys shared by the clients
+	Distribution string        `json:"distribution"`       // uniform, zipfian or hotspot
+	Keys         int           `json:"keys"`               // number of keys to choose from
+	Skew         float64       `json:"skew,omitempty"`     // exponent of the zipfian distribution, must be greater than 1
+	HotKeys      float64       `json:"hot_keys,omitempty"` // fraction of the keys that are hot in the hotspot distribution
+	HotOps       float64       `json:"hot_ops,omitempty"`  // fraction of the operations on the hot keys
+	ValueSize    int           `json:"value_size"`         // number of bytes of each written value
+	Seed         int64         `json:"seed"`               // seed of the random operations of the first client
+	Trace        string        `json:"trace,omitempty"`    // path of a JSONL trace of operations to replay
+}
+
+// SetDefaults sets the parameters of the key distribution that are not specified.
+func (w *Workload) SetDefaults() {
+	if w.Clients == 0 {
+		w.Clients = 1
+	}
+
+	if w.Distribution == "" {
+		w.Distribution = UniformDistribution
+	}
+
+	if w.Keys == 0 {
+		w.Keys = DefaultBenchmarkKeys
+	}
+
+	switch w.Distribution {
+	case ZipfianDistribution:
+		if w.Skew == 0 {
+			w.Skew = DefaultZipfianSkew
+		}
+	case HotspotDistribution:
+		if w.HotKeys == 0 {
+			w.HotKeys = DefaultHotKeys
+		}
+		if w.HotOps == 0 {
+			w.HotOps = DefaultHotOps
+		}
+	}
+}
+
+// Validate the workload, which must specify either a number of requests or a duration
+// unless it replays a trace, which ends when all of its operations are proposed.
+func (w *Workload) Validate() error {
+	if w.Requests > 0 && w.Duration > 0 {
+		return ErrBenchmarkMode
+	}
+
+	if w.Trace == "" && w.Requests == 0 && w.Duration <= 0 {
+		return ErrBenchmarkMode
+	}
+
+	if w.Clients < 1 {
+		return errors.New("benchmarks require at least one client")
+	}
+
+	if w.Rate < 0 || math.IsInf(w.Rate, 0) || math.IsNaN(w.Rate) {
+		return fmt.Errorf("invalid rate %v", w.Rate)
+	}
+
+	return w.validateOperations()
+}
+
+// Validate the parameters of the generated operations.
+func (w *Workload) validateOperations() error {
+	fractions := []struct {
+		name  string
+		value float64
+	}{
+		{"read ratio", w.ReadRatio}, {"conflict", w.Conflict}, {"hot keys", w.HotKeys}, {"hot ops", w.HotOps},
+	}
+
+	for _, fraction := range fractions {
+		if fraction.value < 0 || fraction.value > 1 {
+			return fmt.Errorf("%s must be between 0 and 1", fraction.name)
+		}
+	}
+
+	if w.Keys < 1 {
+		return errors.New("workloads require at least one key")
+	}
+
+	if w.ValueSize < 0 {
+		return errors.New("value size must not be negative")
+	}
+
+	switch w.Distribution {
+	case UniformDistribution, HotspotDistribution:
+	case ZipfianDistribution:
+		if w.Skew <= 1 {
+			return errors.New("the skew of the zipfian distribution must be greater than 1")
+		}
+	default:
+		return fmt.Errorf("unknown key distribution %q", w.Distribution)
+	}
+
+	return nil
+}
+
+//===========================================================================
+// Operation Generators
+//===========================================================================
+
+// Generator generates the operations of a client of a workload. Conflicting operations
+// are on keys shared by all of the clients, the other operations are on keys that only
+// the client accesses, so that they never interfere with the operations of the other
+// clients. Generators are not safe for concurrent use.
+type Generator struct {
+	workload *Workload
+	client   int
+	rand     *rand.Rand
+	zipf     *rand.Zipf
+	value    []byte
+}
+
+// NewGenerator creates the generator of the operations of the client of the workload,
+// which is seeded with the seed of the workload plus the index of the client.
+func NewGenerator(workload *Workload, client int) (*Generator, error) {
+	if err := workload.validateOperations(); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{
+		workload: workload,
+		client:   client,
+		rand:     rand.New(rand.NewSource(workload.Seed + int64(client))),
+		value:    make([]byte, workload.ValueSize),
+	}
+
+	if workload.Distribution == ZipfianDistribution {
+		g.zipf = rand.NewZipf(g.rand, workload.Skew, 1, uint64(workload.Keys-1))
+	}
+	return g, nil
+}
+
+// Next returns the next operation of the client.
+func (g *Generator) Next() *pb.Operation {
+	key := g.key()
+	if g.rand.Float64() < g.workload.ReadRatio {
+		return &pb.Operation{Type: pb.AccessType_READ, Key: key}
+	}
+
+	g.rand.Read(g.value)
+	value := make([]byte, len(g.value))
+	copy(value, g.value)
+	return &pb.Operation{Type: pb.AccessType_WRITE, Key: key, Value: value}
+}
+
+// Choose a key from the distribution and decide whether it is shared by the clients.
+func (g *Generator) key() string {
+	var idx int
+	switch g.workload.Distribution {
+	case ZipfianDistribution:
+		idx = int(g.zipf.Uint64())
+	case HotspotDistribution:
+		hot := int(math.Ceil(g.workload.HotKeys * float64(g.workload.Keys)))
+		switch {
+		case hot >= g.workload.Keys:
+			idx = g.rand.Intn(g.workload.Keys)
+		case hot > 0 && g.rand.Float64() < g.workload.HotOps:
+			idx = g.rand.Intn(hot)
+		default:
+			idx = hot + g.rand.Intn(g.workload.Keys-hot)
+		}
+	default:
+		idx = g.rand.Intn(g.workload.Keys)
+	}
+
+	if g.rand.Float64() < g.workload.Conflict {
+		return fmt.Sprintf("key%d", idx)
+	}
+	return fmt.Sprintf("client%d/key%d", g.client, idx)
+}
+
+//===========================================================================
+// Traces
+//===========================================================================
+
+// WorkloadOperation is a line of a JSONL trace of operations to replay, for example
+// {"op": "put", "key": "foo", "value": "bar"}.
+type WorkloadOperation struct {
+	Op    string `json:"op"`              // name of the access type, e.g. get, put or writeread
+	Key   string `json:"key"`             // the key of the operation
+	Value string `json:"value,omitempty"` // the value of the operation, if any
+}
+
+// ReadWorkloadTrace reads the operations of a JSONL workload trace, skipping blank lines.
+func ReadWorkloadTrace(r io.Reader) ([]*pb.Operation, error) {
+	ops := make([]*pb.Operation, 0)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var op WorkloadOperation
+		if err := json.Unmarshal(scanner.Bytes(), &op); err != nil {
+			return nil, fmt.Errorf("could not parse line %d of trace: %s", line, err)
+		}
+
+		access, err := ParseAccess(op.Op)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse line %d of trace: %s", line, err)
+		}
+
+		ops = append(ops, &pb.Operation{Type: access, Key: op.Key, Value: []byte(op.Value)})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+// Names of the operations that can be proposed in addition to the access types.
+var accessNames = map[string]pb.AccessType{
+	"get":  pb.AccessType_READ,
+	"put":  pb.AccessType_WRITE,
+	"del":  pb.AccessType_DELETE,
+	"incr": pb.AccessType_INCREMENT,
+}
+
+// ParseAccess parses the access type by name, e.g. put, writeread or put-if-absent,
+// or by number.
+func ParseAccess(name string) (pb.AccessType, error) {
+	if access, ok := accessNames[strings.ToLower(name)]; ok {
+		return access, nil
+	}
+
+	if access, ok := pb.AccessType_value[strings.ToUpper(strings.Replace(name, "-", "_", -1))]; ok {
+		return pb.AccessType(access), nil
+	}
+
+	if num, err := strconv.Atoi(name); err == nil {
+		if _, ok := pb.AccessType_name[int32(num)]; ok {
+			return pb.AccessType(num), nil
+		}
+	}
+
+	return pb.AccessType_NULL, fmt.Errorf("unknown operation %q", name)
+}
+
+//===========================================================================
+// Benchmarks
+//===========================================================================
+
+// Benchmark proposes the operations of a workload to the cluster with concurrent
+// clients and measures the throughput and the latency of the operations.
+type Benchmark struct {
+	sync.Mutex
+	workload  *Workload
+	addr      string
+	options   *Config
+	trace     []*pb.Operation // the operations to replay, if any
+	next      int             // the index of the next operation of the trace
+	latencies map[pb.AccessType][]time.Duration
+	results   *BenchmarkResults
+}
+
+// NewBenchmark creates a benchmark of the workload, whose clients connect to the
+// replica with the name or address, or select a replica if it is empty.
+func NewBenchmark(workload *Workload, addr string, options *Config) (*Benchmark, error) {
+	workload.SetDefaults()
+	if err := workload.Validate(); err != nil {
+		return nil, err
+	}
+
+	b := &Benchmark{workload: workload, addr: addr, options: options}
+	if workload.Trace != "" {
+		f, err := os.Open(workload.Trace)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		if b.trace, err = ReadWorkloadTrace(f); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// Run the benchmark until every client has proposed its operations, the trace has been
+// replayed, the duration has elapsed or the context is done. Operations that are not
+// complete when the benchmark ends are not counted. A benchmark can only be run once.
+func (b *Benchmark) Run(ctx context.Context) (*BenchmarkResults, error) {
+	b.Lock()
+	if b.results != nil {
+		b.Unlock()
+		return nil, ErrBenchmarkRun
+	}
+	b.results = &BenchmarkResults{Workload: b.workload}
+	b.latencies = make(map[pb.AccessType][]time.Duration)
+	b.Unlock()
+
+	// Connect the clients and create their generators before the benchmark starts
+	clients := make([]*Client, b.workload.Clients)
+	generators := make([]*Generator, b.workload.Clients)
+	for idx := range clients {
+		var err error
+		if clients[idx], err = NewClient(b.addr, b.options); err != nil {
+			return nil, err
+		}
+		defer clients[idx].close()
+
+		if generators[idx], err = NewGenerator(b.workload, idx); err != nil {
+			return nil, err
+		}
+	}
+
+	if b.workload.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.workload.Duration)
+		defer cancel()
+	}
+
+	b.results.Started = time.Now()
+	if b.workload.Rate > 0 {
+		b.openLoop(ctx, clients, generators)
+	} else {
+		b.closedLoop(ctx, clients, generators)
+	}
+
+	b.Lock()
+	defer b.Unlock()
+	b.results.summarize(time.Since(b.results.Started), b.latencies)
+	return b.results, nil
+}
+
+// Each client proposes its next operation when the previous one completes.
+func (b *Benchmark) closedLoop(ctx context.Context, clients []*Client, generators []*Generator) {
+	wg := new(sync.WaitGroup)
+	for idx := range clients {
+		wg.Add(1)
+		go func(client *Client, generator *Generator) {
+			defer wg.Done()
+			for sent := uint64(0); ctx.Err() == nil; sent++ {
+				op := b.operation(generator, sent)
+				if op == nil {
+					return
+				}
+
+				start := time.Now()
+				_, err := client.ProposeContext(ctx, op.Type, op.Key, op.Value)
+				b.record(ctx, op.Type, time.Since(start), err)
+			}
+		}(clients[idx], generators[idx])
+	}
+	wg.Wait()
+}
+
+// Operations are proposed at the rate of the workload by the clients in turn without
+// waiting for earlier operations to complete. Latency is measured from the time the
+// operation was scheduled to arrive, so that the time spent waiting for the window of
+// outstanding requests of the client to open is included.
+func (b *Benchmark) openLoop(ctx context.Context, clients []*Client, generators []*Generator) {
+	wg := new(sync.WaitGroup)
+	defer wg.Wait()
+
+	interval := float64(time.Second) / b.workload.Rate
+	for idx := 0; ; idx++ {
+		client := idx % len(clients)
+		op := b.operation(generators[client], uint64(idx/len(clients)))
+		if op == nil {
+			return
+		}
+
+		scheduled := b.results.Started.Add(time.Duration(float64(idx) * interval))
+		timer := time.NewTimer(time.Until(scheduled))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+
+		future, err := clients[client].ProposeAsync(ctx, op.Type, op.Key, op.Value)
+		if err != nil {
+			b.record(ctx, op.Type, time.Since(scheduled), err)
+			continue
+		}
+
+		wg.Add(1)
+		go func(access pb.AccessType) {
+			defer wg.Done()
+			_, err := future.Result()
+			b.record(ctx, access, time.Since(scheduled), err)
+		}(op.Type)
+	}
+}
+
+// Returns the next operation of the trace, or of the generator if the client has not
+// yet proposed the requests of the workload, otherwise nil.
+func (b *Benchmark) operation(generator *Generator, sent uint64) *pb.Operation {
+	if b.trace != nil {
+		b.Lock()
+		defer b.Unlock()
+		if b.next >= len(b.trace) {
+			return nil
+		}
+		b.next++
+		return b.trace[b.next-1]
+	}
+
+	if b.workload.Requests > 0 && sent >= b.workload.Requests {
+		return nil
+	}
+	return generator.Next()
+}
+
+// Record the outcome of an operation unless it was interrupted by the end of the
+// benchmark.
+func (b *Benchmark) record(ctx context.Context, access pb.AccessType, latency time.Duration, err error) {
+	b.Lock()
+	defer b.Unlock()
+
+	switch {
+	case err == nil:
+		b.results.Succeeded++
+	case IsRejected(err):
+		b.results.Rejected++
+	case ctx.Err() != nil:
+		return
+	default:
+		b.results.Failed++
+		return
+	}
+
+	b.latencies[access] = append(b.latencies[access], latency)
+}
+
+//===========================================================================
+// Results
+//===========================================================================
+
+// BenchmarkResults are the measurements of a benchmark, which are written as JSON so
+// that runs can be compared. Operations that were rejected by the replicas, e.g. a
+// compare-and-swap whose condition was not met, are complete and count towards the
+// throughput and latency; operations that failed do not.
+type BenchmarkResults struct {
+	Workload   *Workload                  `json:"workload"`   // the workload that was benchmarked
+	Started    time.Time                  `json:"started"`    // the time the first operation was proposed
+	Elapsed    float64                    `json:"elapsed"`    // seconds from the start until the last operation completed
+	Operations uint64                     `json:"operations"` // the number of operations that completed or failed
+	Succeeded  uint64                     `json:"succeeded"`  // the number of operations that were applied
+	Rejected   uint64                     `json:"rejected"`   // the number of operations that were rejected by the replicas
+	Failed     uint64                     `json:"failed"`     // the number of operations that could not be proposed
+	Throughput float64                    `json:"throughput"` // completed operations per second
+	Latency    *LatencySummary            `json:"latency"`    // latency of all completed operations
+	Access     map[string]*LatencySummary `json:"access"`     // latency of the completed operations of each access type
+}
+
+// LatencySummary describes the distribution of latencies in milliseconds.
+type LatencySummary struct {
+	Count uint64  `json:"count"`
+	Mean  float64 `json:"mean"`
+	Min   float64 `json:"min"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P99   float64 `json:"p99"`
+	Max   float64 `json:"max"`
+}
+
+// Compute the throughput and the latency summaries of the benchmark.
+func (r *BenchmarkResults) summarize(elapsed time.Duration, latencies map[pb.AccessType][]time.Duration) {
+	r.Elapsed = elapsed.Seconds()
+	r.Operations = r.Succeeded + r.Rejected + r.Failed
+	if r.Elapsed > 0 {
+		r.Throughput = float64(r.Succeeded+r.Rejected) / r.Elapsed
+	}
+
+	all := make([]time.Duration, 0, r.Succeeded+r.Rejected)
+	r.Access = make(map[string]*LatencySummary, len(latencies))
+	for access, durations := range latencies {
+		all = append(all, durations...)
+		r.Access[strings.ToLower(access.String())] = summarizeLatency(durations)
+	}
+	r.Latency = summarizeLatency(all)
+}
+
+// Returns the summary of the latencies, sorting them in place.
+func summarizeLatency(latencies []time.Duration) *LatencySummary {
+	summary := &LatencySummary{Count: uint64(len(latencies))}
+	if len(latencies) == 0 {
+		return summary
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+	percentile := func(p float64) float64 {
+		return ms(latencies[int(math.Ceil(p*float64(len(latencies))))-1])
+	}
+
+	var total time.Duration
+	for _, latency := range latencies {
+		total += latency
+	}
+
+	summary.Mean = ms(total) / float64(len(latencies))
+	summary.Min = ms(latencies[0])
+	summary.P50 = percentile(0.5)
+	summary.P90 = percentile(0.9)
+	summary.P99 = percentile(0.99)
+	summary.Max = ms(latencies[len(latencies)-1])
+	return summary
+}
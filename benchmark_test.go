@@ -0,0 +1,175 @@
+package epaxos_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/bbengfort/epaxos"
+	"github.com/bbengfort/epaxos/pb"
+)
+
+var _ = Describe("Benchmark", func() {
+
+	// Count the operations generated for the workload by key and by access type.
+	generate := func(workload *Workload, n int) (map[string]int, map[pb.AccessType]int) {
+		workload.SetDefaults()
+		generator, err := NewGenerator(workload, 0)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		keys := make(map[string]int)
+		access := make(map[pb.AccessType]int)
+		for i := 0; i < n; i++ {
+			op := generator.Next()
+			keys[op.Key]++
+			access[op.Type]++
+			if op.Type == pb.AccessType_WRITE {
+				Ω(op.Value).Should(HaveLen(workload.ValueSize))
+			}
+		}
+		return keys, access
+	}
+
+	It("should validate the workload", func() {
+		Ω((&Workload{Clients: 1, Keys: 1, Distribution: UniformDistribution}).Validate()).Should(Equal(ErrBenchmarkMode))
+		Ω((&Workload{Clients: 1, Keys: 1, Distribution: UniformDistribution, Requests: 1, Duration: time.Second}).Validate()).Should(Equal(ErrBenchmarkMode))
+		Ω((&Workload{Clients: 1, Keys: 1, Distribution: UniformDistribution, Trace: "trace.jsonl"}).Validate()).Should(Succeed())
+
+		workload := &Workload{Requests: 10, ReadRatio: 1.5}
+		workload.SetDefaults()
+		Ω(workload.Validate()).Should(MatchError("read ratio must be between 0 and 1"))
+
+		workload = &Workload{Requests: 10, Distribution: "gaussian"}
+		workload.SetDefaults()
+		Ω(workload.Validate()).Should(MatchError(`unknown key distribution "gaussian"`))
+
+		workload = &Workload{Requests: 10, Distribution: ZipfianDistribution, Skew: 0.99}
+		Ω(workload.Validate()).ShouldNot(Succeed())
+	})
+
+	It("should generate reads and writes at the read ratio", func() {
+		keys, access := generate(&Workload{Requests: 1, ReadRatio: 0.7, Conflict: 1, Keys: 10, ValueSize: 16}, 10000)
+		Ω(keys).Should(HaveLen(10))
+		Ω(access[pb.AccessType_READ]).Should(BeNumerically("~", 7000, 300))
+		Ω(access[pb.AccessType_WRITE]).Should(BeNumerically("~", 3000, 300))
+	})
+
+	It("should generate keys from the zipfian and hotspot distributions", func() {
+		keys, _ := generate(&Workload{Requests: 1, Conflict: 1, Keys: 100, Distribution: ZipfianDistribution}, 10000)
+		for key, count := range keys {
+			if key != "key0" {
+				Ω(keys["key0"]).Should(BeNumerically(">", count))
+			}
+		}
+
+		keys, _ = generate(&Workload{Requests: 1, Conflict: 1, Keys: 100, Distribution: HotspotDistribution}, 10000)
+		hot := 0
+		for key, count := range keys {
+			idx, err := strconv.Atoi(strings.TrimPrefix(key, "key"))
+			Ω(err).ShouldNot(HaveOccurred())
+			if idx < 20 {
+				hot += count
+			}
+		}
+		Ω(hot).Should(BeNumerically("~", 8000, 300))
+	})
+
+	It("should generate conflicting operations on shared keys", func() {
+		workload := &Workload{Requests: 1, Conflict: 0.25, Keys: 10}
+		keys, _ := generate(workload, 10000)
+
+		shared := 0
+		for key, count := range keys {
+			if strings.HasPrefix(key, "key") {
+				shared += count
+			} else {
+				Ω(key).Should(HavePrefix("client0/"))
+			}
+		}
+		Ω(shared).Should(BeNumerically("~", 2500, 300))
+	})
+
+	It("should read a workload trace", func() {
+		trace := `{"op": "put", "key": "foo", "value": "bar"}
+
+{"op": "get", "key": "foo"}
+{"op": "put-if-absent", "key": "baz", "value": "1"}
+`
+		ops, err := ReadWorkloadTrace(strings.NewReader(trace))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(ops).Should(HaveLen(3))
+		Ω(ops[0].Type).Should(Equal(pb.AccessType_WRITE))
+		Ω(ops[0].Value).Should(Equal([]byte("bar")))
+		Ω(ops[1].Type).Should(Equal(pb.AccessType_READ))
+		Ω(ops[2].Type).Should(Equal(pb.AccessType_PUT_IF_ABSENT))
+
+		_, err = ReadWorkloadTrace(strings.NewReader(trace + `{"op": "frob", "key": "foo"}`))
+		Ω(err).Should(MatchError(`could not parse line 5 of trace: unknown operation "frob"`))
+	})
+
+	It("should run closed-loop and open-loop workloads", func() {
+		network := runNetwork(55264)
+		options := &Config{Timeout: "2s", LogLevel: int(LogSilent), Peers: network}
+
+		benchmark, err := NewBenchmark(&Workload{Clients: 3, Requests: 20, ReadRatio: 0.5, Conflict: 0.5, Keys: 10, ValueSize: 8}, "alpha", options)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		results, err := benchmark.Run(context.Background())
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(results.Operations).Should(BeEquivalentTo(60))
+		Ω(results.Succeeded).Should(BeEquivalentTo(60))
+		Ω(results.Latency.Count).Should(BeEquivalentTo(60))
+		Ω(results.Latency.P99).Should(BeNumerically(">=", results.Latency.P50))
+		Ω(results.Access["read"].Count + results.Access["write"].Count).Should(BeEquivalentTo(60))
+		Ω(results.Throughput).Should(BeNumerically(">", 0))
+
+		_, err = benchmark.Run(context.Background())
+		Ω(err).Should(Equal(ErrBenchmarkRun))
+
+		benchmark, err = NewBenchmark(&Workload{Clients: 2, Requests: 25, Rate: 200, Conflict: 1, Keys: 5}, "bravo", options)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		results, err = benchmark.Run(context.Background())
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(results.Succeeded).Should(BeEquivalentTo(50))
+
+		// Open-loop operations arrive at the rate regardless of how quickly they complete
+		Ω(results.Elapsed).Should(BeNumerically(">=", 0.245))
+	})
+
+	It("should replay a workload trace", func() {
+		network := runNetwork(56264)
+		options := &Config{Timeout: "2s", LogLevel: int(LogSilent), Peers: network}
+
+		dir, err := ioutil.TempDir("", "epaxos-trace")
+		Ω(err).ShouldNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "trace.jsonl")
+		trace := `{"op": "put", "key": "foo", "value": "bar"}
+{"op": "put-if-absent", "key": "foo", "value": "baz"}
+{"op": "incr", "key": "count", "value": "3"}
+`
+		Ω(ioutil.WriteFile(path, []byte(trace), 0644)).Should(Succeed())
+
+		benchmark, err := NewBenchmark(&Workload{Trace: path}, "charlie", options)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		results, err := benchmark.Run(context.Background())
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(results.Operations).Should(BeEquivalentTo(3))
+		Ω(results.Succeeded).Should(BeEquivalentTo(2))
+		Ω(results.Rejected).Should(BeEquivalentTo(1))
+
+		client, err := NewClient("alpha", options)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(client.Get("foo")).Should(Equal([]byte("bar")))
+	})
+})
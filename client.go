@@ -2,18 +2,24 @@ package epaxos
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"math/rand"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bbengfort/epaxos/pb"
 	"github.com/bbengfort/x/peers"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
-// DefaultRetries specifies the number of times to attempt a commit.
+// DefaultRetries specifies the number of times to attempt a commit by default.
 const DefaultRetries = 3
 
 // NewClient creates a new ePaxos client to connect to a quorum.
@@ -31,14 +37,15 @@ func NewClient(remote string, options *Config) (client *Client, err error) {
 	}
 
 	// Create the client
-	client = &Client{config: config}
+	client = &Client{config: config, window: make(chan struct{}, config.GetWindow()), policy: DefaultRetryPolicy(), health: NewHealth()}
+	if client.selector, err = config.GetSelector(); err != nil {
+		return nil, err
+	}
 
-	// Compute the identity
-	hostname, _ := config.GetName()
-	if hostname != "" {
-		client.identity = fmt.Sprintf("%s-%04X", hostname, rand.Intn(0x10000))
-	} else {
-		client.identity = fmt.Sprintf("%04X-%04X", rand.Intn(0x10000), rand.Intn(0x10000))
+	// Compute the identity, which must be unique since requests are deduplicated by
+	// the identity and sequence number of the client
+	if client.identity, err = newIdentity(config); err != nil {
+		return nil, err
 	}
 
 	// Connect when client is created to capture any errors as early as possible.
@@ -57,7 +64,14 @@ type Client struct {
 	config   *Config          // network details for connection
 	conn     *grpc.ClientConn // grpc connection to dial an ePaxos server
 	client   pb.EpaxosClient  // grpc RPC interface
+	admin    pb.AdminClient   // grpc admin RPC interface
 	identity string           // a unique identity for all clients
+	sequence uint64           // the sequence number of the last request for deduplication
+	window   chan struct{}    // bounds the number of outstanding requests
+	policy   *RetryPolicy     // determines how requests are retried
+	selector Selector         // selects the replica to connect to
+	health   *Health          // observed latency and failures of the replicas
+	remote   string           // name of the connected replica
 }
 
 //===========================================================================
@@ -66,7 +80,12 @@ type Client struct {
 
 // Get a value for a key (execute a read operation)
 func (c *Client) Get(key string) ([]byte, error) {
-	rep, err := c.Propose(pb.AccessType_READ, key, nil)
+	return c.GetContext(context.Background(), key)
+}
+
+// GetContext gets a value for a key, stopping all attempts if the context is done.
+func (c *Client) GetContext(ctx context.Context, key string) ([]byte, error) {
+	rep, err := c.ProposeContext(ctx, pb.AccessType_READ, key, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -75,6 +94,11 @@ func (c *Client) Get(key string) ([]byte, error) {
 
 // Put a value for a key (execute a write operation)
 func (c *Client) Put(key string, value []byte, execute bool) error {
+	return c.PutContext(context.Background(), key, value, execute)
+}
+
+// PutContext puts a value for a key, stopping all attempts if the context is done.
+func (c *Client) PutContext(ctx context.Context, key string, value []byte, execute bool) error {
 	var access pb.AccessType
 	if execute {
 		access = pb.AccessType_WRITEREAD
@@ -82,76 +106,392 @@ func (c *Client) Put(key string, value []byte, execute bool) error {
 		access = pb.AccessType_WRITE
 	}
 
-	_, err := c.Propose(access, key, value)
+	_, err := c.ProposeContext(ctx, access, key, value)
 	return err
 }
 
 // Del a value for a key (execute a delete operation)
 func (c *Client) Del(key string) error {
-	_, err := c.Propose(pb.AccessType_DELETE, key, nil)
+	return c.DelContext(context.Background(), key)
+}
+
+// DelContext deletes a key, stopping all attempts if the context is done.
+func (c *Client) DelContext(ctx context.Context, key string) error {
+	_, err := c.ProposeContext(ctx, pb.AccessType_DELETE, key, nil)
 	return err
 }
 
-// Propose an operation to be applied to the state store.
+// Propose an operation to be applied to the state store. Each request is assigned the
+// next sequence number of the client, which is reused when the request is retried so
+// that the replicas apply the operation exactly once.
 func (c *Client) Propose(access pb.AccessType, key string, value []byte) (rep *pb.ProposeReply, err error) {
-	// Create the request for the operation
+	return c.ProposeContext(context.Background(), access, key, value)
+}
+
+// ProposeContext proposes an operation to be applied to the state store, retrying the
+// request according to the client's retry policy until the context is done. Errors are
+// returned as a RequestError whose class can be checked with IsTimeout, IsRejected or
+// IsUnavailable, unless the context is canceled, in which case the context's error is
+// returned. If the replica rejects the operation, its reply is returned with the error.
+func (c *Client) ProposeContext(ctx context.Context, access pb.AccessType, key string, value []byte) (rep *pb.ProposeReply, err error) {
+	return c.propose(ctx, c.request(access, key, value))
+}
+
+// CompareAndSwap writes the value of the key if its current value is the expected value
+// and returns the new version of the key. If the expected value is empty, the value is
+// written if the key is at the specified version, where version zero means the key
+// does not exist. If the comparison fails, ErrRejected is returned.
+func (c *Client) CompareAndSwap(key string, expect []byte, version uint64, value []byte) (uint64, error) {
+	return c.CompareAndSwapContext(context.Background(), key, expect, version, value)
+}
+
+// CompareAndSwapContext is CompareAndSwap, stopping all attempts if the context is done.
+func (c *Client) CompareAndSwapContext(ctx context.Context, key string, expect []byte, version uint64, value []byte) (uint64, error) {
+	req := c.request(pb.AccessType_CAS, key, value)
+	req.Op.Expect, req.Op.Version = expect, version
+
+	rep, err := c.propose(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	return rep.Version, nil
+}
+
+// PutIfAbsent writes the value of the key only if the key does not exist, otherwise
+// ErrRejected is returned.
+func (c *Client) PutIfAbsent(key string, value []byte) error {
+	return c.PutIfAbsentContext(context.Background(), key, value)
+}
+
+// PutIfAbsentContext is PutIfAbsent, stopping all attempts if the context is done.
+func (c *Client) PutIfAbsentContext(ctx context.Context, key string, value []byte) error {
+	_, err := c.propose(ctx, c.request(pb.AccessType_PUT_IF_ABSENT, key, value))
+	return err
+}
+
+// Increment atomically adds the delta to the integer value of the key, which is zero if
+// the key does not exist, and returns the new value.
+func (c *Client) Increment(key string, delta int64) (int64, error) {
+	return c.IncrementContext(context.Background(), key, delta)
+}
+
+// IncrementContext is Increment, stopping all attempts if the context is done.
+func (c *Client) IncrementContext(ctx context.Context, key string, delta int64) (int64, error) {
+	rep, err := c.propose(ctx, c.request(pb.AccessType_INCREMENT, key, []byte(strconv.FormatInt(delta, 10))))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(rep.Value), 10, 64)
+}
+
+// AddPeer adds the replica to the quorum. The replica should be running with a
+// configuration that includes itself and the current replicas, since it receives the
+// state of the quorum once the change is executed.
+func (c *Client) AddPeer(peer peers.Peer) error {
+	return c.AddPeerContext(context.Background(), peer)
+}
+
+// AddPeerContext is AddPeer, stopping all attempts if the context is done.
+func (c *Client) AddPeerContext(ctx context.Context, peer peers.Peer) error {
+	return c.reconfigure(ctx, MembershipAdd, peer)
+}
+
+// RemovePeer removes the named replica from the quorum.
+func (c *Client) RemovePeer(name string) error {
+	return c.RemovePeerContext(context.Background(), name)
+}
+
+// RemovePeerContext is RemovePeer, stopping all attempts if the context is done.
+func (c *Client) RemovePeerContext(ctx context.Context, name string) error {
+	return c.reconfigure(ctx, MembershipRemove, peers.Peer{Name: name})
+}
+
+// Propose a membership change and wait for it to be executed.
+func (c *Client) reconfigure(ctx context.Context, action string, peer peers.Peer) error {
+	op, err := ReconfigureOperation(action, peer)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.propose(ctx, c.request(op.Type, op.Key, op.Value))
+	return err
+}
+
+// Transact proposes the operations as a transaction that is executed atomically.
+func (c *Client) Transact(ops ...*pb.Operation) ([]*pb.Result, error) {
+	return c.TransactContext(context.Background(), ops...)
+}
+
+// TransactContext proposes the operations as a transaction that is executed atomically:
+// either all of the operations are applied or, if a condition of the transaction is not
+// met, none of them are. The result of each operation is returned in order. If the
+// transaction is aborted, the results are returned with an ErrRejected error and
+// explain which of the conditions were not met.
+func (c *Client) TransactContext(ctx context.Context, ops ...*pb.Operation) ([]*pb.Result, error) {
+	if len(ops) == 0 {
+		return nil, errors.New("transaction does not contain any operations")
+	}
+
 	req := &pb.ProposeRequest{
+		Identity: c.identity,
+		Txn:      ops,
+	}
+
+	rep, err := c.propose(ctx, req)
+	if rep == nil {
+		return nil, err
+	}
+	return rep.Results, err
+}
+
+// SetSelector specifies the strategy used to select a replica when connecting.
+func (c *Client) SetSelector(selector Selector) {
+	c.Lock()
+	defer c.Unlock()
+	c.selector = selector
+}
+
+// Health returns the observed latency and failures of the replicas.
+func (c *Client) Health() *Health {
+	return c.health
+}
+
+// Remote returns the name of the replica the client is connected to, which is the
+// replica that served the last request unless the client is used concurrently.
+func (c *Client) Remote() string {
+	c.RLock()
+	defer c.RUnlock()
+	return c.remote
+}
+
+// SetRetryPolicy specifies how requests are retried by the client.
+func (c *Client) SetRetryPolicy(policy *RetryPolicy) {
+	c.Lock()
+	defer c.Unlock()
+	c.policy = policy
+}
+
+// Create the request for the operation. The sequence number of the request is assigned
+// when it acquires space in the window of outstanding requests.
+func (c *Client) request(access pb.AccessType, key string, value []byte) *pb.ProposeRequest {
+	return &pb.ProposeRequest{
 		Identity: c.identity,
 		Op: &pb.Operation{
 			Type: access, Key: key, Value: value,
 		},
 	}
+}
+
+// Wait for space in the window of outstanding requests, then assign the next sequence
+// number of the client to the request. The sequence number is assigned once there is
+// room in the window so that the outstanding requests are always within the results
+// cached by the session. The window must be released when the request completes.
+func (c *Client) acquire(ctx context.Context, req *pb.ProposeRequest) error {
+	select {
+	case c.window <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 
-	// Send the request
-	if rep, err = c.send(req, DefaultRetries); err != nil {
+	req.Sequence = atomic.AddUint64(&c.sequence, 1)
+	return nil
+}
+
+// Release the space in the window held by a completed request.
+func (c *Client) release() {
+	<-c.window
+}
+
+// Propose the request and wait for the reply, holding space in the window of
+// outstanding requests until the request completes.
+func (c *Client) propose(ctx context.Context, req *pb.ProposeRequest) (*pb.ProposeReply, error) {
+	if err := c.acquire(ctx, req); err != nil {
 		return nil, err
 	}
-	return rep, nil
+	defer c.release()
+	return c.send(ctx, req)
 }
 
-// Send the propose request, handling retries.
-func (c *Client) send(req *pb.ProposeRequest, retries int) (*pb.ProposeReply, error) {
-	// Don't attempt if there are no more retries.
-	if retries <= 0 {
-		return nil, ErrRetries
+// Send the propose request, handling retries according to the retry policy. Each
+// attempt is limited by the configured timeout and all attempts are stopped if the
+// context is done. If the replica rejects the request, its reply is returned with the
+// error. Send is safe to call concurrently; if an attempt fails to reach the
+// replica, the client reconnects to another replica once for all of the concurrent
+// requests that failed.
+func (c *Client) send(ctx context.Context, req *pb.ProposeRequest) (*pb.ProposeReply, error) {
+	c.RLock()
+	policy := c.policy
+	c.RUnlock()
+
+	attempts := policy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
 	}
 
-	// Connect if not connected
-	if !c.isConnected() {
-		if err := c.connect(""); err != nil {
+	var class, last error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		// Backoff before retrying the request
+		if attempt > 1 {
+			if err := policy.wait(ctx, attempt-1); err != nil {
+				return nil, contextError(err, attempt-1)
+			}
+		}
+
+		// Connect if not connected
+		client, remote, err := c.rpc()
+		if err != nil {
+			class, last = ErrUnavailable, err
+			continue
+		}
+
+		// Create the context for the attempt
+		actx, cancel, err := c.context(ctx)
+		if err != nil {
 			return nil, err
 		}
+
+		start := time.Now()
+		rep, err := client.Propose(actx, req)
+		cancel()
+
+		if err != nil {
+			// Do not retry if the caller is no longer waiting for the reply
+			if ctx.Err() != nil {
+				return nil, contextError(ctx.Err(), attempt)
+			}
+
+			// Do not retry requests that the policy does not allow
+			class, last = classify(err), err
+			if !policy.Retryable(class) {
+				return nil, &RequestError{Class: class, Attempts: attempt, Err: err}
+			}
+
+			// If the current host did not reply, avoid it and try another
+			c.health.Fail(remote)
+			if err = c.failover(client); err != nil {
+				last = err
+			}
+			continue
+		}
+
+		if !rep.Success {
+			// If there was an error, the replica rejected the request, otherwise retry.
+			if rep.Error != "" {
+				return rep, &RequestError{Class: ErrRejected, Attempts: attempt, Err: errors.New(rep.Error)}
+			}
+
+			class, last = ErrUnavailable, errors.New("replica did not execute the request")
+			if !policy.Retryable(class) {
+				break
+			}
+			continue
+		}
+
+		c.health.Observe(remote, time.Since(start))
+		return rep, nil
 	}
 
-	// Create the context
-	timeout, err := c.config.GetTimeout()
+	return nil, &RequestError{Class: class, Attempts: attempts, Err: last}
+}
+
+// Returns the error if the caller's context is done; deadlines are timeouts.
+func contextError(err error, attempts int) error {
+	if err == context.DeadlineExceeded {
+		return &RequestError{Class: ErrTimeout, Attempts: attempts, Err: err}
+	}
+	return err
+}
+
+//===========================================================================
+// Admin API
+//===========================================================================
+
+// Status returns the connected replica's view of the quorum and its 2D log.
+func (c *Client) Status() (*pb.StatusReply, error) {
+	admin, err := c.connectAdmin()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel, err := c.context(context.Background())
 	if err != nil {
 		return nil, err
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	rep, err := c.client.Propose(ctx, req)
+	return admin.Status(ctx, &pb.StatusRequest{})
+}
+
+// Fetch the instance in the specified replica log and slot from the connected replica.
+// If the instance has been truncated from the log, ErrTruncated is returned.
+func (c *Client) Fetch(replica uint32, slot uint64) (*pb.Instance, error) {
+	admin, err := c.connectAdmin()
 	if err != nil {
-		if retries > 1 {
-			// If there is an error connecting to the current host, try another
-			if err = c.connect(""); err != nil {
-				return nil, err
-			}
-			return c.send(req, retries-1)
-		}
 		return nil, err
 	}
 
-	if !rep.Success {
-		// If there was an error, return the error, otherwise retry.
-		if rep.Error != "" {
-			return nil, errors.New(rep.Error)
+	ctx, cancel, err := c.context(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	inst, err := admin.Fetch(ctx, &pb.FetchRequest{Replica: replica, Slot: slot})
+	if status.Code(err) == codes.OutOfRange {
+		return nil, ErrTruncated
+	}
+	return inst, err
+}
+
+// Graph returns the dependency graph reachable from the instance in the specified
+// replica log and slot as a Graphviz DOT document.
+func (c *Client) Graph(replica uint32, slot uint64) (string, error) {
+	admin, err := c.connectAdmin()
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel, err := c.context(context.Background())
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+
+	rep, err := admin.Graph(ctx, &pb.FetchRequest{Replica: replica, Slot: slot})
+	if err != nil {
+		return "", err
+	}
+	return rep.Dot, nil
+}
+
+// Returns the admin client of the current connection. Admin requests are made to a
+// specific replica so they are not retried on another replica if the connection fails;
+// only connect if the client is not connected.
+func (c *Client) connectAdmin() (pb.AdminClient, error) {
+	c.Lock()
+	defer c.Unlock()
+	if !c.isConnected() {
+		if err := c.connect(""); err != nil {
+			return nil, err
 		}
-		return c.send(req, retries-1)
 	}
+	return c.admin, nil
+}
 
-	return rep, nil
+// Create a context from the parent with the configured timeout for a single request. If
+// the client has a token, it is sent as a bearer token in the metadata of the request.
+func (c *Client) context(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	timeout, err := c.config.GetTimeout()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if c.config.Token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, metadataAuthorization, bearerScheme+c.config.Token)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, nil
 }
 
 //===========================================================================
@@ -159,9 +499,9 @@ func (c *Client) send(req *pb.ProposeRequest, retries int) (*pb.ProposeReply, er
 //===========================================================================
 
 // Connect to the remote client using the specified timeout. If a remote is not
-// specified (e.g. empty string) then a random replica is selected from the
-// configuration to connect to, prioritizing any replica on the same host as the
-// client.
+// specified (e.g. empty string) then a replica is selected from the configuration
+// by the client's selection strategy, by default prioritizing any replica on the
+// same host as the client.
 func (c *Client) connect(remote string) (err error) {
 	// Close the connection if one is already open.
 	c.close()
@@ -178,22 +518,39 @@ func (c *Client) connect(remote string) (err error) {
 		return err
 	}
 
+	// Get the transport credentials to verify the remote's identity
+	var creds grpc.DialOption
+	if creds, err = c.config.GetDialOption(host.Name); err != nil {
+		return err
+	}
+
 	// Connect to the remote's address
 	addr := host.Endpoint(false)
-	if c.conn, err = grpc.Dial(addr, grpc.WithInsecure(), grpc.WithTimeout(timeout)); err != nil {
+	if c.conn, err = grpc.Dial(addr, creds, grpc.WithTimeout(timeout)); err != nil {
 		return fmt.Errorf("could not connect to '%s': %s", addr, err)
 	}
 
-	// Create gRPC client and return
+	// Create gRPC clients and return
+	c.remote = host.Name
 	c.client = pb.NewEpaxosClient(c.conn)
+	c.admin = pb.NewAdminClient(c.conn)
 	return nil
 }
 
+// Close the connection to the replica; the client reconnects if it is used again.
+func (c *Client) Close() error {
+	c.Lock()
+	defer c.Unlock()
+	return c.close()
+}
+
 // Close the connection to the remote host and clean up.
 func (c *Client) close() (err error) {
 	defer func() {
 		c.conn = nil
 		c.client = nil
+		c.admin = nil
+		c.remote = ""
 	}()
 
 	if c.conn == nil {
@@ -203,28 +560,14 @@ func (c *Client) close() (err error) {
 	return c.conn.Close()
 }
 
-// Find the remote by name, or select the remote on the same host as the client,
-// otherwise return a random remote. Future versions should select a remote in the
-// same region as the client.
+// Find the remote by name, otherwise select a remote using the client's selection
+// strategy, avoiding any remotes that have recently failed.
 func (c *Client) selectRemote(remote string) (*peers.Peer, error) {
 	if remote == "" {
 		if len(c.config.Peers) == 0 {
 			return nil, ErrNoNetwork
 		}
-
-		// Search for host by client name/local hostname
-		hostname, _ := c.config.GetName()
-		if hostname != "" {
-			for _, peer := range c.config.Peers {
-				if peer.Name == hostname || peer.Hostname == hostname {
-					return &peer, nil
-				}
-			}
-		}
-
-		// Return a random peer
-		idx := rand.Intn(len(c.config.Peers))
-		return &c.config.Peers[idx], nil
+		return c.selector.Select(c.health.available(c.config.Peers), c.health), nil
 	}
 
 	for _, peer := range c.config.Peers {
@@ -236,7 +579,55 @@ func (c *Client) selectRemote(remote string) (*peers.Peer, error) {
 	return nil, fmt.Errorf("could not find remote '%s' in configuration", remote)
 }
 
+// Returns the gRPC client of the current connection and the name of the connected
+// remote, connecting if not connected.
+func (c *Client) rpc() (pb.EpaxosClient, string, error) {
+	c.RLock()
+	client, remote := c.client, c.remote
+	c.RUnlock()
+
+	if client != nil {
+		return client, remote, nil
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	if !c.isConnected() {
+		if err := c.connect(""); err != nil {
+			return nil, "", err
+		}
+	}
+	return c.client, c.remote, nil
+}
+
+// Connect to another replica after a request on the failed client did not reach the
+// replica. If another request has already reconnected, the connection is reused.
+func (c *Client) failover(failed pb.EpaxosClient) error {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.client != failed && c.isConnected() {
+		return nil
+	}
+	return c.connect("")
+}
+
 // Ensures a client and connection exist
 func (c *Client) isConnected() bool {
-	return c.client != nil && c.conn != nil
+	return c.client != nil && c.admin != nil && c.conn != nil
+}
+
+// Returns a unique identity for a client, prefixed by the configured name or hostname
+// to make it easier to identify in the logs. The identity has 128 random bits so that
+// clients do not share a session, even if many clients run on the same host.
+func newIdentity(config *Config) (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("could not generate client identity: %s", err)
+	}
+
+	if hostname, _ := config.GetName(); hostname != "" {
+		return hostname + "-" + hex.EncodeToString(nonce), nil
+	}
+	return hex.EncodeToString(nonce), nil
 }
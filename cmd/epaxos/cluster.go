@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/bbengfort/epaxos"
+	"github.com/urfave/cli"
+)
+
+// ShutdownTimeout is the time to wait for the replicas of a cluster to exit after they
+// have been interrupted before they are killed.
+const ShutdownTimeout = 5 * time.Second
+
+// Run a cluster of replicas on consecutive local ports as child processes of this
+// process, prefixing each line of their output with the name of the replica. The
+// replicas are interrupted when this process is interrupted or terminated.
+func cluster(c *cli.Context) (err error) {
+	n := c.Int("replicas")
+	if n < 1 {
+		return cli.NewExitError("specify at least one replica", 1)
+	}
+
+	// Write the configuration to a temporary directory unless a directory is specified
+	dir := c.String("dir")
+	if dir == "" {
+		if dir, err = ioutil.TempDir("", "epaxos-cluster"); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		defer os.RemoveAll(dir)
+	}
+
+	conf := &epaxos.Config{Timeout: c.String("timeout"), Thrifty: c.Bool("thrifty"), LogLevel: c.Int("level")}
+	if conf.Peers, err = makePeers(n, nil, c.Int("port")); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	if err = conf.ValidatePeers(); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	// Clients that use the configuration of the cluster are granted admin access
+	if err = addAdmin(conf); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	var data []byte
+	if data, err = encodeConfig(conf, "json"); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	path := filepath.Join(dir, "config.json")
+	if err = ioutil.WriteFile(path, data, 0644); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	var cmd string
+	if cmd, err = os.Executable(); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	// Interrupt the replicas when this process is interrupted
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	out := &prefixWriter{w: os.Stdout}
+	procs := make([]*exec.Cmd, 0, n)
+	done := make(chan struct{}, n)
+
+	for _, peer := range conf.Peers {
+		proc := exec.Command(cmd, "-c", path, "serve", "-n", peer.Name)
+		prefix := fmt.Sprintf("%-*s | ", len(replicaName(n-1)), peer.Name)
+
+		var stdout, stderr io.ReadCloser
+		if stdout, err = proc.StdoutPipe(); err != nil {
+			break
+		}
+		if stderr, err = proc.StderrPipe(); err != nil {
+			break
+		}
+		if err = proc.Start(); err != nil {
+			break
+		}
+		procs = append(procs, proc)
+
+		wg := new(sync.WaitGroup)
+		wg.Add(2)
+		go out.copy(prefix, stdout, wg)
+		go out.copy(prefix, stderr, wg)
+
+		go func(name string, proc *exec.Cmd, wg *sync.WaitGroup) {
+			// Wait for the output to be copied before the process is waited on
+			wg.Wait()
+			if err := proc.Wait(); err != nil {
+				out.println(fmt.Sprintf("%s exited: %s", name, err))
+			} else {
+				out.println(fmt.Sprintf("%s exited", name))
+			}
+			done <- struct{}{}
+		}(peer.Name, proc, wg)
+	}
+
+	if err != nil {
+		interrupt(procs)
+		return cli.NewExitError(fmt.Sprintf("could not start replicas: %s", err), 1)
+	}
+
+	out.println(fmt.Sprintf("started %d replicas with configuration %s", n, path))
+
+	// Wait until all of the replicas exit or until the cluster is interrupted
+	for running := len(procs); running > 0; {
+		select {
+		case <-done:
+			running--
+		case <-sigs:
+			out.println("shutting down the cluster")
+			interrupt(procs)
+			timeout := time.After(ShutdownTimeout)
+			for running > 0 {
+				select {
+				case <-done:
+					running--
+				case <-timeout:
+					for _, proc := range procs {
+						proc.Process.Kill()
+					}
+					timeout = nil
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Interrupt the processes so that they exit.
+func interrupt(procs []*exec.Cmd) {
+	for _, proc := range procs {
+		proc.Process.Signal(os.Interrupt)
+	}
+}
+
+// prefixWriter writes the lines of the output of multiple processes to the writer so
+// that lines of different processes are not interleaved.
+type prefixWriter struct {
+	sync.Mutex
+	w io.Writer
+}
+
+// Copy the lines read from the reader with the prefix until it is closed.
+func (p *prefixWriter) copy(prefix string, r io.Reader, wg *sync.WaitGroup) {
+	defer wg.Done()
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		p.println(prefix + scanner.Text())
+	}
+}
+
+// Write the line to the writer.
+func (p *prefixWriter) println(line string) {
+	p.Lock()
+	defer p.Unlock()
+	fmt.Fprintln(p.w, line)
+}
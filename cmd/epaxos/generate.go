@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/bbengfort/epaxos"
+	"github.com/bbengfort/x/peers"
+	"github.com/urfave/cli"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Names of generated replicas in PID order; replicas beyond the list are numbered.
+var replicaNames = []string{
+	"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel", "india",
+	"juliett", "kilo", "lima", "mike", "november", "oscar", "papa", "quebec", "romeo",
+	"sierra", "tango", "uniform", "victor", "whiskey", "xray", "yankee", "zulu",
+}
+
+// Generate a configuration for a cluster of replicas, either on consecutive local
+// ports or on the specified hosts, and optionally write an environment file for each
+// replica that sets its name and random seed.
+func generateConfig(c *cli.Context) (err error) {
+	var hosts []string
+	if c.String("hosts") != "" {
+		hosts = strings.Split(c.String("hosts"), ",")
+	}
+
+	n := c.Int("replicas")
+	if len(hosts) > 0 {
+		if c.IsSet("replicas") && n != len(hosts) {
+			return cli.NewExitError(fmt.Sprintf("%d replicas specified for %d hosts", n, len(hosts)), 1)
+		}
+		n = len(hosts)
+	}
+
+	if n < 1 {
+		return cli.NewExitError("specify at least one replica", 1)
+	}
+
+	conf := &epaxos.Config{Timeout: c.String("timeout"), Thrifty: c.Bool("thrifty")}
+	if conf.Peers, err = makePeers(n, hosts, c.Int("port")); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	if err = conf.ValidatePeers(); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	if c.Bool("admin") {
+		if err = addAdmin(conf); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	}
+
+	// Determine the format from the flag or the extension of the output path
+	outpath := c.String("outpath")
+	format := strings.ToLower(c.String("format"))
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(outpath), ".")
+	}
+
+	var data []byte
+	if data, err = encodeConfig(conf, format); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	if outpath == "" {
+		fmt.Print(string(data))
+	} else {
+		if err = ioutil.WriteFile(outpath, data, 0644); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		fmt.Fprintf(os.Stderr, "wrote configuration for %d replicas to %s\n", n, outpath)
+	}
+
+	if envdir := c.String("env"); envdir != "" {
+		for idx, peer := range conf.Peers {
+			path := filepath.Join(envdir, peer.Name+".sh")
+			env := fmt.Sprintf("export EPAXOS_NAME=%q\nexport EPAXOS_SEED=%d\n", peer.Name, c.Int64("seed")+int64(idx))
+			if err = ioutil.WriteFile(path, []byte(env), 0644); err != nil {
+				return cli.NewExitError(err, 1)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "wrote %d environment files to %s\n", n, envdir)
+	}
+
+	return nil
+}
+
+// Returns the definitions of n replicas on the hosts, or on consecutive local ports
+// starting at the port if no hosts are specified.
+func makePeers(n int, hosts []string, port int) ([]peers.Peer, error) {
+	replicas := make([]peers.Peer, 0, n)
+	for idx := 0; idx < n; idx++ {
+		peer := peers.Peer{PID: uint32(idx + 1), Name: replicaName(idx)}
+		if len(hosts) > 0 {
+			if err := setHost(&peer, strings.TrimSpace(hosts[idx]), port); err != nil {
+				return nil, err
+			}
+		} else {
+			peer.IPAddr = "127.0.0.1"
+			peer.Domain = "localhost"
+			peer.Port = uint16(port + idx)
+		}
+		replicas = append(replicas, peer)
+	}
+	return replicas, nil
+}
+
+// Add a client policy with a random token that grants admin access and configure
+// clients to send the token, so that clients using the configuration can use the admin
+// API. Note that once a policy is configured, every client must send a token.
+func addAdmin(conf *epaxos.Config) error {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return err
+	}
+
+	conf.Token = hex.EncodeToString(token)
+	conf.Clients = append(conf.Clients, epaxos.ClientPolicy{Identity: "admin", Token: conf.Token, Admin: true})
+	return nil
+}
+
+// Returns the name of the replica at the index.
+func replicaName(idx int) string {
+	if idx < len(replicaNames) {
+		return replicaNames[idx]
+	}
+	return fmt.Sprintf("replica%d", idx+1)
+}
+
+// Set the address of the peer from a host that is an IP address or a domain name,
+// optionally followed by a port; otherwise the port is used.
+func setHost(peer *peers.Peer, host string, port int) error {
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		if port, err = strconv.Atoi(p); err != nil {
+			return fmt.Errorf("could not parse port of host %q", host)
+		}
+		host = h
+	}
+
+	if host == "" {
+		return fmt.Errorf("no host specified for %s", peer.Name)
+	}
+
+	if net.ParseIP(host) != nil {
+		peer.IPAddr = host
+	} else {
+		peer.Domain = host
+	}
+
+	peer.Port = uint16(port)
+	return nil
+}
+
+// Encode the configuration in the format, using the keys that the loader of the format
+// expects and omitting zero values so that the defaults are used when it is loaded.
+func encodeConfig(conf *epaxos.Config, format string) ([]byte, error) {
+	switch format {
+	case "", "json":
+		data, err := json.MarshalIndent(configMap(reflect.ValueOf(conf), jsonKey), "", "  ")
+		return append(data, '\n'), err
+	case "toml":
+		buf := new(bytes.Buffer)
+		err := toml.NewEncoder(buf).Encode(configMap(reflect.ValueOf(conf), fieldKey))
+		return buf.Bytes(), err
+	case "yml", "yaml":
+		return yaml.Marshal(configMap(reflect.ValueOf(conf), lowerKey))
+	default:
+		return nil, fmt.Errorf("unknown configuration format %q", format)
+	}
+}
+
+// Returns the structs in the value as maps with the keys of its non-zero fields.
+func configMap(val reflect.Value, key func(reflect.StructField) string) interface{} {
+	switch val.Kind() {
+	case reflect.Ptr:
+		return configMap(val.Elem(), key)
+	case reflect.Struct:
+		fields := make(map[string]interface{})
+		for idx := 0; idx < val.NumField(); idx++ {
+			field := val.Type().Field(idx)
+			if field.PkgPath != "" || isZero(val.Field(idx)) {
+				continue
+			}
+			fields[key(field)] = configMap(val.Field(idx), key)
+		}
+		return fields
+	case reflect.Slice:
+		items := make([]interface{}, 0, val.Len())
+		for idx := 0; idx < val.Len(); idx++ {
+			items = append(items, configMap(val.Index(idx), key))
+		}
+		return items
+	default:
+		return val.Interface()
+	}
+}
+
+// Returns true if the value is the zero value of its type or an empty slice or map.
+func isZero(val reflect.Value) bool {
+	switch val.Kind() {
+	case reflect.Slice, reflect.Map:
+		return val.Len() == 0
+	default:
+		return reflect.DeepEqual(val.Interface(), reflect.Zero(val.Type()).Interface())
+	}
+}
+
+// Keys of JSON configurations are specified by the json tag of the field.
+func jsonKey(field reflect.StructField) string {
+	if name := strings.Split(field.Tag.Get("json"), ",")[0]; name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// Keys of TOML configurations match the name of the field.
+func fieldKey(field reflect.StructField) string {
+	return field.Name
+}
+
+// Keys of YAML configurations are the lower case name of the field.
+func lowerKey(field reflect.StructField) string {
+	return strings.ToLower(field.Name)
+}
@@ -1,10 +1,19 @@
 package main
 
 import (
+	"context"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/bbengfort/epaxos"
 	"github.com/bbengfort/epaxos/pb"
@@ -62,6 +71,132 @@ func main() {
 					Name:  "s, seed",
 					Usage: "specify the random seed",
 				},
+				cli.StringFlag{
+					Name:  "t, trace",
+					Usage: "record handled events to the specified trace file",
+				},
+			},
+		},
+		{
+			Name:      "replay",
+			Usage:     "replay a recorded event trace and dump the resulting log",
+			ArgsUsage: "trace",
+			Action:    replay,
+			Category:  "server",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "n, name",
+					Usage: "unique name of the replica that recorded the trace",
+				},
+				cli.BoolFlag{
+					Name:  "j, json",
+					Usage: "print the instances as JSON",
+				},
+			},
+		},
+		{
+			Name:     "cluster",
+			Usage:    "run a cluster of replicas on local ports",
+			Action:   cluster,
+			Category: "server",
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "n, replicas",
+					Usage: "number of replicas in the cluster",
+					Value: 3,
+				},
+				cli.IntFlag{
+					Name:  "p, port",
+					Usage: "port of the first replica, incremented for each replica",
+					Value: 3264,
+				},
+				cli.StringFlag{
+					Name:  "t, timeout",
+					Usage: "timeout to wait for responses",
+					Value: "500ms",
+				},
+				cli.BoolFlag{
+					Name:  "thrifty",
+					Usage: "send thrifty quorum messages",
+				},
+				cli.IntFlag{
+					Name:  "l, level",
+					Usage: "verbosity of logging, lower is more verbose",
+					Value: 3,
+				},
+				cli.StringFlag{
+					Name:  "d, dir",
+					Usage: "write the configuration to the directory instead of a temporary directory",
+				},
+			},
+		},
+		{
+			Name:     "config",
+			Usage:    "manage the configuration of replicas",
+			Category: "server",
+			Subcommands: []cli.Command{
+				{
+					Name:   "check",
+					Usage:  "validate the configuration and report every problem",
+					Action: checkConfig,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "n, name",
+							Usage: "unique name of the replica to check the configuration of",
+						},
+					},
+				},
+				{
+					Name:   "init",
+					Usage:  "generate the configuration of a cluster of replicas",
+					Action: generateConfig,
+					Flags: []cli.Flag{
+						cli.IntFlag{
+							Name:  "n, replicas",
+							Usage: "number of replicas in the cluster",
+							Value: 3,
+						},
+						cli.StringFlag{
+							Name:  "H, hosts",
+							Usage: "comma separated hosts of the replicas, optionally with ports",
+						},
+						cli.IntFlag{
+							Name:  "p, port",
+							Usage: "port of the replicas, incremented for each local replica",
+							Value: 3264,
+						},
+						cli.StringFlag{
+							Name:  "t, timeout",
+							Usage: "timeout to wait for responses",
+							Value: "500ms",
+						},
+						cli.BoolFlag{
+							Name:  "thrifty",
+							Usage: "send thrifty quorum messages",
+						},
+						cli.BoolFlag{
+							Name:  "a, admin",
+							Usage: "add a client policy with a random token that grants admin access",
+						},
+						cli.StringFlag{
+							Name:  "f, format",
+							Usage: "format of the configuration: json, toml or yaml",
+						},
+						cli.StringFlag{
+							Name:  "o, outpath",
+							Usage: "write the configuration to the specified path instead of stdout",
+						},
+						cli.StringFlag{
+							Name:  "e, env",
+							Usage: "write an environment file for each replica to the directory",
+						},
+						cli.Int64Flag{
+							Name:  "s, seed",
+							Usage: "random seed of the first replica, incremented for each replica",
+							Value: 42,
+						},
+					},
+				},
 			},
 		},
 		{
@@ -74,9 +209,10 @@ func main() {
 					Name:  "a, addr",
 					Usage: "name or address of replica to connect to",
 				},
-				cli.IntFlag{
-					Name:  "t, type",
-					Usage: "access type of the operation",
+				cli.StringFlag{
+					Name:  "op, t, type",
+					Usage: "operation: get, put, del, writeread, cas, put-if-absent, incr or pause",
+					Value: "get",
 				},
 				cli.StringFlag{
 					Name:  "k, key",
@@ -86,6 +222,39 @@ func main() {
 					Name:  "v, value",
 					Usage: "the value to commit with the associated key",
 				},
+				cli.StringFlag{
+					Name:  "f, file",
+					Usage: "read the value from the file, or from stdin if -",
+				},
+				cli.StringFlag{
+					Name:  "expect",
+					Usage: "the current value of the key that cas requires",
+				},
+				cli.Uint64Flag{
+					Name:  "version",
+					Usage: "the current version of the key that cas requires, 0 if it does not exist",
+				},
+				cli.BoolFlag{
+					Name:  "expect-absent",
+					Usage: "cas requires that the key does not exist",
+				},
+				cli.StringFlag{
+					Name:  "o, output",
+					Usage: "output format: raw, json or hex",
+					Value: "raw",
+				},
+			},
+		},
+		{
+			Name:     "shell",
+			Usage:    "run an interactive shell to access the key/value store",
+			Action:   runShell,
+			Category: "client",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "a, addr",
+					Usage: "name of the replica to connect to, selected by the configured strategy by default",
+				},
 			},
 		},
 		{
@@ -99,16 +268,67 @@ func main() {
 					Usage: "name or address of replica to connect to",
 					Value: "",
 				},
+				cli.IntFlag{
+					Name:  "c, clients",
+					Usage: "number of concurrent clients",
+					Value: 1,
+				},
 				cli.UintFlag{
 					Name:  "r, requests",
 					Usage: "number of requests issued per client",
-					Value: 1000,
+				},
+				cli.DurationFlag{
+					Name:  "D, duration",
+					Usage: "run the benchmark for a fixed time instead of a number of requests",
+				},
+				cli.Float64Flag{
+					Name:  "R, rate",
+					Usage: "issue requests open-loop at the target rate per second (closed-loop by default)",
+				},
+				cli.Float64Flag{
+					Name:  "reads",
+					Usage: "percentage of requests that are reads",
+					Value: 50,
+				},
+				cli.Float64Flag{
+					Name:  "conflict",
+					Usage: "percentage of requests on keys shared by the clients",
+					Value: 100,
+				},
+				cli.StringFlag{
+					Name:  "k, keys",
+					Usage: "key distribution: uniform, zipfian or hotspot",
+					Value: epaxos.UniformDistribution,
+				},
+				cli.IntFlag{
+					Name:  "n, keyspace",
+					Usage: "number of keys to choose from",
+					Value: epaxos.DefaultBenchmarkKeys,
+				},
+				cli.Float64Flag{
+					Name:  "skew",
+					Usage: "exponent of the zipfian distribution, greater than 1",
+					Value: epaxos.DefaultZipfianSkew,
+				},
+				cli.StringFlag{
+					Name:  "hotspot",
+					Usage: "percentage of hot keys and of requests on them",
+					Value: "20:80",
 				},
 				cli.IntFlag{
 					Name:  "s, size",
 					Usage: "number of bytes per value",
 					Value: 32,
 				},
+				cli.Int64Flag{
+					Name:  "seed",
+					Usage: "seed of the generated requests",
+					Value: 42,
+				},
+				cli.StringFlag{
+					Name:  "t, trace",
+					Usage: "replay the operations of a JSONL trace instead of generating them",
+				},
 				cli.DurationFlag{
 					Name:  "d, delay",
 					Usage: "wait specified time before starting benchmark",
@@ -117,9 +337,73 @@ func main() {
 					Name:  "i, indent",
 					Usage: "indent the results by specified number of spaces",
 				},
+				cli.StringFlag{
+					Name:  "o, outpath",
+					Usage: "append the results as a line of JSON to the specified path",
+				},
+			},
+		},
+		{
+			Name:     "status",
+			Usage:    "print the cluster view from every peer in the configuration",
+			Action:   status,
+			Category: "admin",
+			Flags: []cli.Flag{
 				cli.BoolFlag{
-					Name:  "b, blast",
-					Usage: "send all requests per client at once",
+					Name:  "j, json",
+					Usage: "print the status replies as JSON",
+				},
+			},
+		},
+		{
+			Name:     "log",
+			Usage:    "dump the instances in a range of a replica's log",
+			Action:   dumpLog,
+			Category: "admin",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "a, addr",
+					Usage: "name or address of replica to connect to",
+				},
+				cli.UintFlag{
+					Name:  "r, replica",
+					Usage: "PID of the replica log to dump (required)",
+				},
+				cli.Uint64Flag{
+					Name:  "s, start",
+					Usage: "first slot of the range to dump (default first slot that is not truncated)",
+				},
+				cli.Uint64Flag{
+					Name:  "e, end",
+					Usage: "dump up to but not including this slot (default next slot)",
+				},
+				cli.BoolFlag{
+					Name:  "j, json",
+					Usage: "print the instances as JSON",
+				},
+			},
+		},
+		{
+			Name:     "graph",
+			Usage:    "export the dependency graph of an instance as Graphviz DOT",
+			Action:   graph,
+			Category: "admin",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "a, addr",
+					Usage: "name or address of replica to connect to",
+				},
+				cli.UintFlag{
+					Name:  "r, replica",
+					Usage: "PID of the replica log of the instance (required)",
+				},
+				cli.Uint64Flag{
+					Name:  "s, slot",
+					Usage: "slot of the instance in the replica log",
+				},
+				cli.StringFlag{
+					Name:  "o, outpath",
+					Usage: "write the DOT document to the specified path",
 				},
 			},
 		},
@@ -136,19 +420,28 @@ func main() {
 func initConfig(c *cli.Context) (err error) {
 	config = new(epaxos.Config)
 	if cpath := c.String("config"); cpath != "" {
-		var data []byte
-		if data, err = ioutil.ReadFile(cpath); err != nil {
-			return cli.NewExitError(err, 1)
-		}
-
-		if err = json.Unmarshal(data, &config); err != nil {
-			return cli.NewExitError(err, 1)
+		// The file is loaded by its extension and replicas reload from the same file
+		config.Path = cpath
+		if err = config.Load(); err != nil {
+			return configError(err)
 		}
 	}
 
 	return nil
 }
 
+// Returns an exit error for the configuration error, printing each problem of the
+// configuration on its own line if there are several.
+func configError(err error) error {
+	if errs, ok := err.(epaxos.ConfigErrors); ok {
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "  - %s\n", err)
+		}
+		return cli.NewExitError(fmt.Sprintf("configuration has %d errors", len(errs)), 1)
+	}
+	return cli.NewExitError(err, 1)
+}
+
 //===========================================================================
 // Server Commands
 //===========================================================================
@@ -162,6 +455,10 @@ func serve(c *cli.Context) (err error) {
 		config.Seed = seed
 	}
 
+	if trace := c.String("trace"); trace != "" {
+		config.Trace = trace
+	}
+
 	if replica, err = epaxos.New(config); err != nil {
 		return cli.NewExitError(err, 1)
 	}
@@ -173,31 +470,457 @@ func serve(c *cli.Context) (err error) {
 	return nil
 }
 
+func replay(c *cli.Context) (err error) {
+	if c.NArg() != 1 {
+		return cli.NewExitError("specify the path to a single trace file", 1)
+	}
+
+	if name := c.String("name"); name != "" {
+		config.Name = name
+	}
+
+	// Replay the trace, printing the log even if an event could not be handled.
+	if replica, err = epaxos.Replay(c.Args().First(), config); replica == nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "replay stopped: %s\n", err)
+	}
+
+	logs := replica.Logs()
+	instances := make([]*pb.Instance, 0)
+	slots, offsets := logs.NextSlots(), logs.Offsets()
+	for _, peer := range config.Peers {
+		for slot := offsets[peer.PID]; slot < slots[peer.PID]; slot++ {
+			// Skip the instances that were truncated from the log by snapshots
+			if inst, err := logs.Get(peer.PID, slot); err == nil {
+				instances = append(instances, inst)
+			}
+		}
+	}
+
+	if c.Bool("json") {
+		return printJSON(instances)
+	}
+
+	fmt.Printf("sequence: %d\nslots: %s\nexecuted: %s\n\n", logs.Sequence(), fmtSlots(slots), fmtSlots(logs.Executed()))
+	return printInstances(instances)
+}
+
+func checkConfig(c *cli.Context) (err error) {
+	if name := c.String("name"); name != "" {
+		config.Name = name
+	}
+
+	// Load the configuration the same way as the replica does
+	conf := &epaxos.Config{Path: config.Path}
+	if err = conf.Load(); err == nil {
+		err = conf.Update(config)
+	}
+
+	if err != nil {
+		return configError(err)
+	}
+
+	fmt.Printf("configuration is valid: %d peers with a quorum of %d\n", len(conf.Peers), conf.GetQuorum())
+	if peer, err := conf.GetPeer(); err == nil {
+		fmt.Printf("local replica %s (PID %d) listens on port %d\n", peer.Name, peer.PID, peer.Port)
+	}
+	return nil
+}
+
 //===========================================================================
 // Client Commands
 //===========================================================================
 
 func propose(c *cli.Context) (err error) {
+	// Get the proposal args from cli
+	var access pb.AccessType
+	if access, err = epaxos.ParseAccess(c.String("op")); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	var value []byte
+	if value, err = readValue(c); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	conditions := 0
+	for _, flag := range []string{"expect", "version", "expect-absent"} {
+		if c.IsSet(flag) {
+			conditions++
+		}
+	}
+
+	if access != pb.AccessType_CAS && conditions > 0 {
+		return cli.NewExitError("--expect, --version and --expect-absent only apply to cas", 1)
+	}
+
+	if conditions > 1 {
+		return cli.NewExitError("specify only one of the expected value, the version or that the key is absent for cas", 1)
+	}
+
+	output := strings.ToLower(c.String("output"))
+	if output != "raw" && output != "json" && output != "hex" {
+		return cli.NewExitError(fmt.Sprintf("unknown output format %q", output), 1)
+	}
+
 	// Connect the client to the cluster
 	if client, err = epaxos.NewClient(c.String("addr"), config); err != nil {
 		return cli.NewExitError(err, 1)
 	}
 
-	// Get the proposal args from cli
-	access := pb.AccessType(int32(c.Int("type")))
-	key := c.String("key")
-	value := []byte(c.String("value"))
+	// Make the request; if the replica rejected the operation its reply is printed
+	var rep *pb.ProposeReply
+	if access == pb.AccessType_CAS {
+		// The swap is proposed as a transaction so that the result is returned when the
+		// comparison fails; an absent key is expected with version 0 and no value
+		op := &pb.Operation{Type: access, Key: c.String("key"), Value: value, Expect: []byte(c.String("expect")), Version: c.Uint64("version")}
 
-	// Make the request
-	rep, err := client.Propose(access, key, value)
-	if err != nil {
+		var results []*pb.Result
+		if results, err = client.Transact(op); len(results) != 1 {
+			if epaxos.IsRejected(err) {
+				return cli.NewExitError(err, 2)
+			}
+			return cli.NewExitError(err, 1)
+		}
+
+		res := results[0]
+		rep = &pb.ProposeReply{Success: res.Success, Error: res.Error, Key: res.Key, Value: res.Value, Version: res.Version, Results: results}
+	} else if rep, err = client.Propose(access, c.String("key"), value); rep == nil {
 		return cli.NewExitError(err, 1)
 	}
 
-	fmt.Println(rep)
+	switch output {
+	case "json":
+		if err := printJSON(rep); err != nil {
+			return err
+		}
+	case "hex":
+		fmt.Println(hex.EncodeToString(rep.Value))
+	default:
+		os.Stdout.Write(rep.Value)
+	}
+
+	if rep.Error != "" {
+		return cli.NewExitError(rep.Error, 2)
+	}
 	return nil
 }
 
+// Read the value of the operation from the value flag, a file or stdin.
+func readValue(c *cli.Context) ([]byte, error) {
+	path := c.String("file")
+	if path == "" {
+		return []byte(c.String("value")), nil
+	}
+
+	if c.IsSet("value") {
+		return nil, errors.New("specify either a value or a file to read the value from")
+	}
+
+	if path == "-" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+	return ioutil.ReadFile(path)
+}
+
 func bench(c *cli.Context) (err error) {
-	return cli.NewExitError("not implemented yet", 1)
+	workload := &epaxos.Workload{
+		Clients:      c.Int("clients"),
+		Requests:     uint64(c.Uint("requests")),
+		Duration:     c.Duration("duration"),
+		Rate:         c.Float64("rate"),
+		ReadRatio:    c.Float64("reads") / 100,
+		Conflict:     c.Float64("conflict") / 100,
+		Distribution: strings.ToLower(c.String("keys")),
+		Keys:         c.Int("keyspace"),
+		ValueSize:    c.Int("size"),
+		Seed:         c.Int64("seed"),
+		Trace:        c.String("trace"),
+	}
+
+	// Closed-loop benchmarks issue 1000 requests per client unless a duration is given
+	if workload.Requests == 0 && workload.Duration == 0 && workload.Trace == "" {
+		workload.Requests = 1000
+	}
+
+	switch workload.Distribution {
+	case epaxos.ZipfianDistribution:
+		workload.Skew = c.Float64("skew")
+	case epaxos.HotspotDistribution:
+		if workload.HotKeys, workload.HotOps, err = parseHotspot(c.String("hotspot")); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	}
+
+	var benchmark *epaxos.Benchmark
+	if benchmark, err = epaxos.NewBenchmark(workload, c.String("addr"), config); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	if delay := c.Duration("delay"); delay > 0 {
+		time.Sleep(delay)
+	}
+
+	// Stop the benchmark and report the results so far if it is interrupted
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	defer signal.Stop(sigs)
+	go func() {
+		select {
+		case <-sigs:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var results *epaxos.BenchmarkResults
+	if results, err = benchmark.Run(ctx); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	var data []byte
+	if indent := c.Int("indent"); indent > 0 {
+		data, err = json.MarshalIndent(results, "", strings.Repeat(" ", indent))
+	} else {
+		data, err = json.Marshal(results)
+	}
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	fmt.Println(string(data))
+
+	if outpath := c.String("outpath"); outpath != "" {
+		if err = appendLine(outpath, results); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	}
+	return nil
+}
+
+// Parse the percentage of hot keys and of the requests on them, e.g. 20:80.
+func parseHotspot(val string) (keys, ops float64, err error) {
+	parts := strings.Split(val, ":")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("could not parse hotspot %q, specify keys:requests", val)
+	}
+
+	if keys, err = strconv.ParseFloat(parts[0], 64); err != nil {
+		return 0, 0, fmt.Errorf("could not parse hotspot %q, specify keys:requests", val)
+	}
+
+	if ops, err = strconv.ParseFloat(parts[1], 64); err != nil {
+		return 0, 0, fmt.Errorf("could not parse hotspot %q, specify keys:requests", val)
+	}
+	return keys / 100, ops / 100, nil
+}
+
+// Append the value encoded as a line of JSON to the file at the path.
+func appendLine(path string, val interface{}) error {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+//===========================================================================
+// Admin Commands
+//===========================================================================
+
+func status(c *cli.Context) (err error) {
+	if len(config.Peers) == 0 {
+		return cli.NewExitError(epaxos.ErrNoNetwork, 1)
+	}
+
+	// Collect the status from every peer; peers that cannot be reached are offline.
+	replies := make(map[string]*pb.StatusReply, len(config.Peers))
+	for _, peer := range config.Peers {
+		if client, err = epaxos.NewClient(peer.Name, config); err != nil {
+			if client != nil {
+				client.Close()
+			}
+			continue
+		}
+
+		if rep, err := client.Status(); err == nil {
+			replies[peer.Name] = rep
+		}
+		client.Close()
+	}
+
+	if c.Bool("json") {
+		return printJSON(replies)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "PID\tNAME\tADDR\tSTATUS\tEPOCH\tSEQ\tSLOTS\tEXECUTED\tTRUNCATED")
+	for _, peer := range config.Peers {
+		rep, ok := replies[peer.Name]
+		if !ok {
+			fmt.Fprintf(w, "%d\t%s\t%s\toffline\t-\t-\t-\t-\t-\n", peer.PID, peer.Name, peer.Endpoint(false))
+			continue
+		}
+
+		fmt.Fprintf(
+			w, "%d\t%s\t%s\tonline\t%d\t%d\t%s\t%s\t%s\n", peer.PID, peer.Name, peer.Endpoint(false),
+			rep.Epoch, rep.Sequence, fmtSlots(rep.Slots), fmtSlots(rep.Executed), fmtSlots(rep.Offsets),
+		)
+	}
+	return w.Flush()
+}
+
+func dumpLog(c *cli.Context) (err error) {
+	if !c.IsSet("replica") {
+		return cli.NewExitError("specify the PID of the replica log to dump", 1)
+	}
+
+	// Connect the client to the cluster
+	if client, err = epaxos.NewClient(c.String("addr"), config); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	// Determine the range of the log to dump, by default from the first instance that
+	// has not been truncated to the end of the log.
+	pid := uint32(c.Uint("replica"))
+	start, end := c.Uint64("start"), c.Uint64("end")
+	if !c.IsSet("start") || !c.IsSet("end") {
+		var rep *pb.StatusReply
+		if rep, err = client.Status(); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+
+		next, ok := rep.Slots[pid]
+		if !ok {
+			return cli.NewExitError(fmt.Errorf("no log for replica with PID %d", pid), 1)
+		}
+
+		if !c.IsSet("start") {
+			start = rep.Offsets[pid]
+		}
+		if !c.IsSet("end") {
+			end = next
+		}
+	}
+
+	if start > end {
+		return cli.NewExitError(fmt.Sprintf("start slot %d is after end slot %d", start, end), 1)
+	}
+
+	instances := make([]*pb.Instance, 0, end-start)
+	for slot := start; slot < end; slot++ {
+		var inst *pb.Instance
+		if inst, err = client.Fetch(pid, slot); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		instances = append(instances, inst)
+	}
+
+	if c.Bool("json") {
+		return printJSON(instances)
+	}
+	return printInstances(instances)
+}
+
+func graph(c *cli.Context) (err error) {
+	if !c.IsSet("replica") {
+		return cli.NewExitError("specify the PID of the replica log of the instance", 1)
+	}
+
+	// Connect the client to the cluster
+	if client, err = epaxos.NewClient(c.String("addr"), config); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	var dot string
+	if dot, err = client.Graph(uint32(c.Uint("replica")), c.Uint64("slot")); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	if path := c.String("outpath"); path != "" {
+		if err = ioutil.WriteFile(path, []byte(dot), 0644); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		return nil
+	}
+
+	fmt.Print(dot)
+	return nil
+}
+
+//===========================================================================
+// Helpers
+//===========================================================================
+
+// Print the value as indented JSON to stdout.
+func printJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Println(string(data))
+	return nil
+}
+
+// Print the instances as a table to stdout.
+func printInstances(instances []*pb.Instance) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "REPLICA\tSLOT\tSTATUS\tSEQ\tDEPS\tOPS")
+	for _, inst := range instances {
+		if inst == nil {
+			continue
+		}
+
+		fmt.Fprintf(
+			w, "%d\t%d\t%s\t%d\t%s\t%s\n", inst.Replica, inst.Slot,
+			inst.Status, inst.Seq, fmtSlots(inst.Deps), fmtOps(inst.Ops),
+		)
+	}
+	return w.Flush()
+}
+
+// Format a map of replica PIDs to slots sorted by PID, e.g. "1:4 2:0 3:12".
+func fmtSlots(slots map[uint32]uint64) string {
+	if len(slots) == 0 {
+		return "-"
+	}
+
+	pids := make([]int, 0, len(slots))
+	for pid := range slots {
+		pids = append(pids, int(pid))
+	}
+	sort.Ints(pids)
+
+	parts := make([]string, 0, len(pids))
+	for _, pid := range pids {
+		parts = append(parts, fmt.Sprintf("%d:%d", pid, slots[uint32(pid)]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Format the operations of an instance, e.g. "WRITE(foo) READ(bar)".
+func fmtOps(ops []*pb.Operation) string {
+	if len(ops) == 0 {
+		return "-"
+	}
+
+	parts := make([]string, 0, len(ops))
+	for _, op := range ops {
+		parts = append(parts, fmt.Sprintf("%s(%s)", op.Type, op.Key))
+	}
+	return strings.Join(parts, " ")
 }
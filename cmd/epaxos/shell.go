@@ -0,0 +1,314 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bbengfort/epaxos"
+	"github.com/bbengfort/epaxos/pb"
+	"github.com/urfave/cli"
+)
+
+// Commands of the interactive shell in the order they are listed by help.
+var shellCommands = []struct {
+	name  string
+	args  string
+	usage string
+}{
+	{"get", "<key>", "read the value of the key"},
+	{"put", "<key> <value>", "write the value of the key"},
+	{"del", "<key>", "delete the key"},
+	{"cas", "<key> <expect|@version> <value>", "write the value if the key has the expected value or version"},
+	{"batch", "", "queue the following operations as a transaction"},
+	{"commit", "", "propose the queued operations atomically"},
+	{"abort", "", "discard the queued operations"},
+	{"help", "", "show the available commands"},
+	{"exit", "", "leave the shell"},
+}
+
+// shell is an interactive client of the key/value store.
+type shell struct {
+	client *epaxos.Client
+	batch  []*pb.Operation     // queued operations if a batch has been started
+	keys   map[string]struct{} // keys used in this session for tab completion
+	reader *lineReader
+}
+
+// Run an interactive shell that proposes operations to the replicas with the client,
+// reporting the replica that served each request and its latency.
+func runShell(c *cli.Context) (err error) {
+	sh := &shell{keys: make(map[string]struct{})}
+	if sh.client, err = epaxos.NewClient(c.String("addr"), config); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	sh.reader = newLineReader(sh.complete)
+	if sh.reader.terminal {
+		fmt.Println("connected to the epaxos cluster, type help for the available commands")
+	}
+
+	for {
+		line, err := sh.reader.ReadLine(sh.prompt())
+		if err == errInterrupted {
+			continue
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+
+		args, err := splitArgs(line)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+
+		if len(args) == 0 {
+			continue
+		}
+
+		if args[0] == "exit" || args[0] == "quit" {
+			return nil
+		}
+
+		if err = sh.exec(args); err != nil {
+			fmt.Println(err)
+		}
+	}
+}
+
+// Returns the prompt, which shows the number of queued operations of a batch.
+func (sh *shell) prompt() string {
+	if sh.batch != nil {
+		return fmt.Sprintf("epaxos(batch %d)> ", len(sh.batch))
+	}
+	return "epaxos> "
+}
+
+// Execute the command, queueing operations if a batch has been started.
+func (sh *shell) exec(args []string) error {
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "help":
+		sh.help()
+		return nil
+	case "batch":
+		if sh.batch != nil {
+			return errors.New("a batch has already been started")
+		}
+		sh.batch = make([]*pb.Operation, 0)
+		return nil
+	case "abort":
+		if sh.batch == nil {
+			return errors.New("no batch has been started")
+		}
+		sh.batch = nil
+		return nil
+	case "commit":
+		return sh.commit()
+	}
+
+	op, err := sh.operation(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	if sh.batch != nil {
+		sh.batch = append(sh.batch, op)
+		return nil
+	}
+
+	start := time.Now()
+	var rep *pb.ProposeReply
+	if op.Type == pb.AccessType_CAS {
+		var version uint64
+		if version, err = sh.client.CompareAndSwap(op.Key, op.Expect, op.Version, op.Value); err == nil {
+			rep = &pb.ProposeReply{Key: op.Key, Version: version}
+		}
+	} else {
+		rep, err = sh.client.Propose(op.Type, op.Key, op.Value)
+	}
+	latency := time.Since(start)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(formatResult(op.Type, rep.Value, rep.Version))
+	sh.served(latency)
+	return nil
+}
+
+// Propose the operations of the batch as a transaction and print the result of each.
+func (sh *shell) commit() error {
+	if sh.batch == nil {
+		return errors.New("no batch has been started")
+	}
+
+	ops := sh.batch
+	sh.batch = nil
+
+	start := time.Now()
+	results, err := sh.client.Transact(ops...)
+	latency := time.Since(start)
+
+	for idx, res := range results {
+		if idx >= len(ops) {
+			break
+		}
+
+		if res.Error != "" {
+			fmt.Printf("%d) %s %s: %s\n", idx+1, strings.ToLower(ops[idx].Type.String()), res.Key, res.Error)
+			continue
+		}
+		fmt.Printf("%d) %s %s: %s\n", idx+1, strings.ToLower(ops[idx].Type.String()), res.Key, formatResult(ops[idx].Type, res.Value, res.Version))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	sh.served(latency)
+	return nil
+}
+
+// Create the operation for the command.
+func (sh *shell) operation(cmd string, args []string) (*pb.Operation, error) {
+	nargs := map[string]int{"get": 1, "put": 2, "del": 1, "cas": 3}
+	n, ok := nargs[cmd]
+	if !ok {
+		return nil, fmt.Errorf("unknown command %q, type help for the available commands", cmd)
+	}
+
+	if len(args) != n {
+		for _, command := range shellCommands {
+			if command.name == cmd {
+				return nil, fmt.Errorf("usage: %s %s", cmd, command.args)
+			}
+		}
+	}
+
+	sh.keys[args[0]] = struct{}{}
+	switch cmd {
+	case "get":
+		return &pb.Operation{Type: pb.AccessType_READ, Key: args[0]}, nil
+	case "put":
+		return &pb.Operation{Type: pb.AccessType_WRITE, Key: args[0], Value: []byte(args[1])}, nil
+	case "del":
+		return &pb.Operation{Type: pb.AccessType_DELETE, Key: args[0]}, nil
+	default:
+		op := &pb.Operation{Type: pb.AccessType_CAS, Key: args[0], Value: []byte(args[2])}
+		if strings.HasPrefix(args[1], "@") {
+			version, err := strconv.ParseUint(args[1][1:], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse version %q", args[1])
+			}
+			op.Version = version
+		} else {
+			op.Expect = []byte(args[1])
+		}
+		return op, nil
+	}
+}
+
+// Print the replica that served the request and its latency.
+func (sh *shell) served(latency time.Duration) {
+	fmt.Printf("(%s in %s)\n", sh.client.Remote(), latency.Round(10*time.Microsecond))
+}
+
+// Print the usage of the commands.
+func (sh *shell) help() {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	for _, cmd := range shellCommands {
+		fmt.Fprintf(tw, "  %s %s\t%s\n", cmd.name, cmd.args, cmd.usage)
+	}
+	tw.Flush()
+}
+
+// Returns the commands or the keys used in this session that complete the last word.
+func (sh *shell) complete(words []string) []string {
+	word := words[len(words)-1]
+	candidates := make([]string, 0)
+	if len(words) == 1 {
+		for _, cmd := range shellCommands {
+			candidates = append(candidates, cmd.name)
+		}
+	} else if len(words) == 2 {
+		for key := range sh.keys {
+			candidates = append(candidates, key)
+		}
+	}
+
+	completions := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) {
+			completions = append(completions, candidate)
+		}
+	}
+	sort.Strings(completions)
+	return completions
+}
+
+// Returns the result of an operation for display.
+func formatResult(access pb.AccessType, value []byte, version uint64) string {
+	switch access {
+	case pb.AccessType_READ:
+		if version == 0 {
+			return "(nil)"
+		}
+		return fmt.Sprintf("%q (version %d)", value, version)
+	case pb.AccessType_DELETE:
+		return "OK"
+	default:
+		return fmt.Sprintf("OK (version %d)", version)
+	}
+}
+
+// Split the line into arguments separated by whitespace; arguments that contain
+// whitespace can be quoted with single or double quotes.
+func splitArgs(line string) ([]string, error) {
+	args := make([]string, 0)
+	var arg strings.Builder
+	var quote rune
+	inArg := false
+
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
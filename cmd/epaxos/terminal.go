@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Keys handled by the line reader.
+const (
+	keyCtrlA     = 1
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlH     = 8
+	keyTab       = 9
+	keyNewline   = 10
+	keyEnter     = 13
+	keyCtrlU     = 21
+	keyEscape    = 27
+	keyBackspace = 127
+)
+
+// errInterrupted is returned by the line reader if the line is canceled with Ctrl-C.
+var errInterrupted = errors.New("interrupted")
+
+// lineReader reads lines from stdin with line editing, history and tab completion if
+// stdin is a terminal, otherwise it reads lines without a prompt, e.g. from a script.
+type lineReader struct {
+	in       *bufio.Reader
+	out      io.Writer
+	terminal bool                          // if stdin is a terminal that can be put in raw mode
+	history  []string                      // lines previously read, oldest first
+	complete func(words []string) []string // returns the completions of the last word
+}
+
+// Create a line reader from stdin that writes the prompt and edits to stdout.
+func newLineReader(complete func(words []string) []string) *lineReader {
+	return &lineReader{
+		in:       bufio.NewReader(os.Stdin),
+		out:      os.Stdout,
+		terminal: isTerminal(os.Stdin),
+		complete: complete,
+	}
+}
+
+// ReadLine reads the next line, returning io.EOF if the input is closed, or if Ctrl-D
+// is entered on an empty line, and errInterrupted if the line is canceled.
+func (l *lineReader) ReadLine(prompt string) (string, error) {
+	if !l.terminal {
+		line, err := l.in.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	restore, err := makeRaw(os.Stdin)
+	if err != nil {
+		l.terminal = false
+		return l.ReadLine(prompt)
+	}
+	defer restore()
+
+	e := &lineEditor{prompt: prompt, out: l.out, history: len(l.history)}
+	e.render()
+
+	for {
+		r, _, err := l.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case keyEnter, keyNewline:
+			fmt.Fprint(l.out, "\r\n")
+			line := string(e.line)
+			if strings.TrimSpace(line) != "" {
+				l.history = append(l.history, line)
+			}
+			return line, nil
+		case keyCtrlC:
+			fmt.Fprint(l.out, "^C\r\n")
+			return "", errInterrupted
+		case keyCtrlD:
+			if len(e.line) == 0 {
+				fmt.Fprint(l.out, "\r\n")
+				return "", io.EOF
+			}
+			e.delete()
+		case keyBackspace, keyCtrlH:
+			e.backspace()
+		case keyCtrlA:
+			e.move(-e.pos)
+		case keyCtrlE:
+			e.move(len(e.line) - e.pos)
+		case keyCtrlU:
+			e.line, e.pos = e.line[e.pos:], 0
+		case keyTab:
+			l.completeLine(e)
+		case keyEscape:
+			l.escape(e)
+		default:
+			if unicode.IsPrint(r) {
+				e.insert(r)
+			}
+		}
+		e.render()
+	}
+}
+
+// Handle the escape sequences of the arrow, home, end and delete keys.
+func (l *lineReader) escape(e *lineEditor) {
+	if r, _, err := l.in.ReadRune(); err != nil || (r != '[' && r != 'O') {
+		return
+	}
+
+	r, _, err := l.in.ReadRune()
+	if err != nil {
+		return
+	}
+
+	switch r {
+	case 'A':
+		l.recall(e, -1)
+	case 'B':
+		l.recall(e, 1)
+	case 'C':
+		e.move(1)
+	case 'D':
+		e.move(-1)
+	case 'H':
+		e.move(-e.pos)
+	case 'F':
+		e.move(len(e.line) - e.pos)
+	case '3':
+		if r, _, err = l.in.ReadRune(); err == nil && r == '~' {
+			e.delete()
+		}
+	}
+}
+
+// Replace the line with the previous (-1) or next (1) line in the history.
+func (l *lineReader) recall(e *lineEditor, delta int) {
+	idx := e.history + delta
+	if idx < 0 || idx > len(l.history) {
+		return
+	}
+
+	e.history = idx
+	if idx == len(l.history) {
+		e.line = nil
+	} else {
+		e.line = []rune(l.history[idx])
+	}
+	e.pos = len(e.line)
+}
+
+// Complete the word before the cursor: a single completion is inserted, otherwise the
+// common prefix of the completions is inserted or the completions are listed.
+func (l *lineReader) completeLine(e *lineEditor) {
+	if l.complete == nil {
+		return
+	}
+
+	before := string(e.line[:e.pos])
+	words := strings.Fields(before)
+	if len(words) == 0 || unicode.IsSpace(e.line[e.pos-1]) {
+		words = append(words, "")
+	}
+
+	word := words[len(words)-1]
+	completions := l.complete(words)
+	if len(completions) == 0 {
+		return
+	}
+
+	if len(completions) == 1 {
+		for _, r := range strings.TrimPrefix(completions[0], word) + " " {
+			e.insert(r)
+		}
+		return
+	}
+
+	prefix := commonPrefix(completions)
+	if len(prefix) > len(word) {
+		for _, r := range strings.TrimPrefix(prefix, word) {
+			e.insert(r)
+		}
+		return
+	}
+
+	fmt.Fprintf(l.out, "\r\n%s\r\n", strings.Join(completions, "  "))
+}
+
+// Returns the longest prefix shared by all of the strings.
+func commonPrefix(vals []string) string {
+	prefix := vals[0]
+	for _, val := range vals[1:] {
+		for !strings.HasPrefix(val, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// lineEditor is the state of the line being edited in raw mode.
+type lineEditor struct {
+	prompt  string
+	out     io.Writer
+	line    []rune
+	pos     int // the position of the cursor in the line
+	history int // the index of the history entry being edited
+}
+
+// Insert the rune at the cursor.
+func (e *lineEditor) insert(r rune) {
+	e.line = append(e.line, 0)
+	copy(e.line[e.pos+1:], e.line[e.pos:])
+	e.line[e.pos] = r
+	e.pos++
+}
+
+// Delete the rune before the cursor.
+func (e *lineEditor) backspace() {
+	if e.pos > 0 {
+		e.line = append(e.line[:e.pos-1], e.line[e.pos:]...)
+		e.pos--
+	}
+}
+
+// Delete the rune at the cursor.
+func (e *lineEditor) delete() {
+	if e.pos < len(e.line) {
+		e.line = append(e.line[:e.pos], e.line[e.pos+1:]...)
+	}
+}
+
+// Move the cursor by the delta within the bounds of the line.
+func (e *lineEditor) move(delta int) {
+	e.pos += delta
+	if e.pos < 0 {
+		e.pos = 0
+	}
+	if e.pos > len(e.line) {
+		e.pos = len(e.line)
+	}
+}
+
+// Redraw the prompt and the line, then place the cursor.
+func (e *lineEditor) render() {
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K", e.prompt, string(e.line))
+	if back := len(e.line) - e.pos; back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
@@ -0,0 +1,9 @@
+package main
+
+import "golang.org/x/sys/unix"
+
+// Requests to get and set the attributes of a terminal.
+const (
+	ioctlGetTermios = unix.TCGETS
+	ioctlSetTermios = unix.TCSETS
+)
@@ -0,0 +1,19 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// Terminals are not supported, so lines are read without editing.
+func isTerminal(f *os.File) bool {
+	return false
+}
+
+// Raw mode is not supported on this platform.
+func makeRaw(f *os.File) (func() error, error) {
+	return nil, errors.New("raw terminal mode is not supported")
+}
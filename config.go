@@ -25,13 +25,29 @@ import (
 // environment using environment variables prefixed with $EPAXOS_ and the all
 // caps version of the configuration name.
 type Config struct {
-	Name      string       `required:"false" json:"name,omitempty"`             // unique name of the local replica, hostname by default
-	Seed      int64        `required:"false" json:"seed,omitempty"`             // random seed to initialize random generator
-	Timeout   string       `default:"500ms" validate:"duration" json:"timeout"` // timeout to wait for responses (parseable duration)
-	Aggregate bool         `default:"false" json:"aggregate"`                   // aggregate operations from multiple concurrent clients
-	Thrifty   bool         `default:"false" json:"thrifty"`                     // whether or not to send thrifty quorum messages
-	LogLevel  int          `default:"3" validate:"uint" json:"log_level"`       // verbosity of logging, lower is more verbose
-	Peers     []peers.Peer `json:"peers"`                                       // definition of all hosts on the network
+	Path           string         `required:"false" json:"-"`                                      // configuration file to load and reload, searched for if empty
+	Name           string         `required:"false" json:"name,omitempty"`                         // unique name of the local replica, hostname by default
+	Seed           int64          `required:"false" json:"seed,omitempty"`                         // random seed to initialize random generator
+	Timeout        string         `default:"500ms" validate:"duration" json:"timeout"`             // timeout to wait for responses (parseable duration)
+	Aggregate      bool           `default:"false" json:"aggregate"`                               // aggregate operations from multiple concurrent clients
+	Thrifty        bool           `default:"false" json:"thrifty"`                                 // whether or not to send thrifty quorum messages
+	LogLevel       int            `default:"3" validate:"uint" json:"log_level"`                   // verbosity of logging, lower is more verbose
+	LogFormat      string         `default:"text" json:"log_format"`                               // format of log output, either text or json
+	Trace          string         `required:"false" json:"trace,omitempty"`                        // path to record handled events to for replay
+	TLS            TLSConfig      `json:"tls"`                                                     // certificates for encrypted and authenticated connections
+	ClusterKey     string         `required:"false" json:"cluster_key,omitempty"`                  // shared secret to authenticate peers if TLS is not configured
+	Token          string         `required:"false" json:"token,omitempty"`                        // bearer token sent by clients to authenticate
+	Clients        []ClientPolicy `json:"clients,omitempty"`                                       // client tokens and the operations they may propose
+	ClientRate     float64        `required:"false" json:"client_rate,omitempty"`                  // proposals per second per client, unlimited if zero
+	ClientBurst    int            `required:"false" validate:"uint" json:"client_burst,omitempty"` // maximum burst of proposals per client
+	Window         int            `default:"64" validate:"uint" json:"window"`                     // maximum outstanding requests per client
+	SessionTimeout string         `default:"10m" validate:"duration" json:"session_timeout"`       // idle time before a client session is expired
+	Selection      string         `default:"local" json:"selection"`                               // strategy clients use to select a replica
+	Region         string         `required:"false" json:"region,omitempty"`                       // region of the client for replica affinity
+	Zone           string         `required:"false" json:"zone,omitempty"`                         // zone of the client for replica affinity
+	Snapshot       string         `default:"1m" validate:"duration" json:"snapshot"`               // interval between snapshots of the state machine, zero disables
+	Data           string         `required:"false" json:"data,omitempty"`                         // directory to write snapshots to, memory only if empty
+	Peers          []peers.Peer   `json:"peers"`                                                   // definition of all hosts on the network
 
 	// Experimental configuration
 	// TODO: remove after benchmarks
@@ -47,8 +63,13 @@ func (c *Config) Load() error {
 	// Read default values defined via tag fields "default"
 	loaders = append(loaders, &multiconfig.TagLoader{})
 
-	// Find the config path and hte appropriate file loader
-	if path, err := c.GetPath(); err == nil {
+	// Find the config path and hte appropriate file loader; an explicit path must exist
+	path, err := c.GetPath()
+	if err != nil && c.Path != "" {
+		return err
+	}
+
+	if err == nil {
 		if strings.HasSuffix(path, "toml") {
 			loaders = append(loaders, &multiconfig.TOMLLoader{Path: path})
 		}
@@ -82,7 +103,93 @@ func (c *Config) Validate() error {
 		&ComplexValidator{},
 	)
 
-	return validators.Validate(c)
+	if err := validators.Validate(c); err != nil {
+		return err
+	}
+
+	if err := c.TLS.Validate(); err != nil {
+		return err
+	}
+
+	if c.Window > SessionResults {
+		return fmt.Errorf("window must not be greater than %d outstanding requests", SessionResults)
+	}
+
+	if _, err := c.GetSelector(); err != nil {
+		return err
+	}
+
+	if c.ClientRate < 0 {
+		return errors.New("client rate must not be negative")
+	}
+
+	for idx := range c.Clients {
+		if err := c.Clients[idx].Validate(); err != nil {
+			return err
+		}
+	}
+
+	return c.ValidatePeers()
+}
+
+// ValidatePeers checks that every peer has a PID, a name and a port, that no two peers
+// share a PID, a name or an address, and that the local replica is one of the peers if
+// its name is configured. All of the problems are returned as ConfigErrors.
+func (c *Config) ValidatePeers() error {
+	errs := make(ConfigErrors, 0)
+	pids := make(map[uint32]string)
+	names := make(map[string]bool)
+	addrs := make(map[string]string)
+
+	for idx, peer := range c.Peers {
+		label := peer.Name
+		if label == "" {
+			label = fmt.Sprintf("at index %d", idx)
+			errs = append(errs, fmt.Errorf("peer %s has no name", label))
+		} else {
+			label = fmt.Sprintf("%q", label)
+			if names[peer.Name] {
+				errs = append(errs, fmt.Errorf("duplicate peer name %q", peer.Name))
+			}
+			names[peer.Name] = true
+		}
+
+		if peer.PID == 0 {
+			errs = append(errs, fmt.Errorf("peer %s has no PID", label))
+		} else {
+			if other, ok := pids[peer.PID]; ok {
+				errs = append(errs, fmt.Errorf("peer %s has the same PID %d as peer %s", label, peer.PID, other))
+			}
+			pids[peer.PID] = label
+		}
+
+		if peer.Port == 0 {
+			errs = append(errs, fmt.Errorf("peer %s has no port", label))
+			continue
+		}
+
+		// Peers are dialed by domain if it is set, otherwise by IP address
+		endpoints := uniqueStrings(peer.Endpoint(true), peer.Endpoint(false))
+		for _, addr := range endpoints {
+			if other, ok := addrs[addr]; ok {
+				errs = append(errs, fmt.Errorf("peer %s has the same address %s as peer %s", label, addr, other))
+				break
+			}
+		}
+
+		for _, addr := range endpoints {
+			addrs[addr] = label
+		}
+	}
+
+	if c.Name != "" && !names[c.Name] {
+		errs = append(errs, fmt.Errorf("local replica %q is not one of the peers", c.Name))
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
 }
 
 // Update the configuration from another configuration struct
@@ -198,16 +305,40 @@ func (c *Config) GetThrifty() []uint32 {
 	return thrifty
 }
 
+// GetLogger returns a logger that writes to stdout at the configured level using the
+// configured log format, either human readable text or JSON objects.
+func (c *Config) GetLogger() (Logger, error) {
+	var sink Sink
+	switch strings.ToLower(c.LogFormat) {
+	case "", "text":
+		sink = NewTextSink(os.Stdout, "[epaxos] ")
+	case "json":
+		sink = NewJSONSink(os.Stdout)
+	default:
+		return nil, fmt.Errorf("unknown log format '%s'", c.LogFormat)
+	}
+
+	return NewLogger(uint8(c.LogLevel), sink), nil
+}
+
 // GetQuorum returns the number of replicas required for a quourm based on the
 // peers defined in the configuration.
 func (c *Config) GetQuorum() uint32 {
 	return uint32((len(c.Peers) / 2) + 1)
 }
 
-// GetPath searches possible configuration paths returning the first path it
-// finds; this path is used when loading the configuration from disk. An
-// error is returned if no configuration file exists.
+// GetPath returns the configuration path if one was specified, otherwise it searches
+// possible configuration paths returning the first path it finds; this path is used
+// when loading the configuration from disk. An error is returned if no configuration
+// file exists.
 func (c *Config) GetPath() (string, error) {
+	if c.Path != "" {
+		if _, err := os.Stat(c.Path); err != nil {
+			return "", err
+		}
+		return c.Path, nil
+	}
+
 	// Prepare PATH list
 	paths := make([]string, 0, 3)
 
@@ -241,6 +372,32 @@ func (c *Config) GetTimeout() (time.Duration, error) {
 	return time.ParseDuration(c.Timeout)
 }
 
+// GetWindow returns the maximum number of outstanding requests per client,
+// using the default window if it is not configured.
+func (c *Config) GetWindow() int {
+	if c.Window <= 0 {
+		return DefaultWindow
+	}
+	return c.Window
+}
+
+// GetSessionTimeout parses the session timeout duration and returns it. If the
+// session timeout is not set, sessions are never expired.
+func (c *Config) GetSessionTimeout() (time.Duration, error) {
+	if c.SessionTimeout == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(c.SessionTimeout)
+}
+
+// GetSnapshotInterval parses the interval between snapshots, zero if disabled.
+func (c *Config) GetSnapshotInterval() (time.Duration, error) {
+	if c.Snapshot == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(c.Snapshot)
+}
+
 // GetUptime parses the uptime duration and returns it.
 func (c *Config) GetUptime() (time.Duration, error) {
 	return time.ParseDuration(c.Uptime)
@@ -250,6 +407,42 @@ func (c *Config) GetUptime() (time.Duration, error) {
 // Validators
 //===========================================================================
 
+// ConfigErrors aggregates the problems found when validating the configuration so
+// that all of them can be reported at once.
+type ConfigErrors []error
+
+// Error implements the error interface.
+func (e ConfigErrors) Error() string {
+	if len(e) == 1 {
+		return fmt.Sprintf("invalid configuration: %s", e[0])
+	}
+
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("invalid configuration (%d errors): %s", len(e), strings.Join(msgs, "; "))
+}
+
+// Returns the strings in order without duplicates.
+func uniqueStrings(vals ...string) []string {
+	unique := make([]string, 0, len(vals))
+	for _, val := range vals {
+		dup := false
+		for _, seen := range unique {
+			if seen == val {
+				dup = true
+				break
+			}
+		}
+
+		if !dup {
+			unique = append(unique, val)
+		}
+	}
+	return unique
+}
+
 // ComplexValidator validates complex types that multiconfig doesn't understand
 type ComplexValidator struct {
 	TagName string
@@ -322,7 +515,9 @@ func (v *ComplexValidator) processURLField(fieldName string, field *structs.Fiel
 }
 
 func (v *ComplexValidator) processPathField(fieldName string, field *structs.Field) error {
-	// No path validation quite yet
+	if _, err := os.Stat(field.Value().(string)); err != nil {
+		return fmt.Errorf("could not validate %s: %s", fieldName, err.Error())
+	}
 	return nil
 }
 
@@ -9,6 +9,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	. "github.com/bbengfort/epaxos"
+	"github.com/bbengfort/x/peers"
 )
 
 var _ = Describe("Config", func() {
@@ -23,6 +24,7 @@ var _ = Describe("Config", func() {
 			LogLevel:  2,
 			Uptime:    "15m",
 			Metrics:   "metrics.json",
+			Peers:     []peers.Peer{{PID: 1, Name: "foo", IPAddr: "127.0.0.1", Port: 3264}},
 		}
 		Ω(conf.Validate()).Should(Succeed())
 	})
@@ -41,6 +43,7 @@ var _ = Describe("Config", func() {
 		Ω(conf.Aggregate).Should(BeFalse())
 		Ω(conf.Thrifty).Should(BeFalse())
 		Ω(conf.LogLevel).Should(Equal(3))
+		Ω(conf.LogFormat).Should(Equal("text"))
 
 		// Validate non configurations
 		Ω(conf.Name).Should(BeZero())
@@ -76,6 +79,31 @@ var _ = Describe("Config", func() {
 			Ω(config.Validate()).Should(Succeed())
 		})
 
+		It("should report every problem with the peers", func() {
+			Ω(config.ValidatePeers()).Should(Succeed())
+
+			config.Name = "zulu"
+			config.Peers[1].PID = 1
+			config.Peers[2].Name = "alpha"
+			config.Peers[3].Port = 0
+			config.Peers[4].Port = config.Peers[0].Port
+
+			err := config.Validate()
+			Ω(err).Should(BeAssignableToTypeOf(ConfigErrors{}))
+			Ω(err.(ConfigErrors)).Should(HaveLen(5))
+			Ω(err).Should(MatchError(ContainSubstring("invalid configuration (5 errors): ")))
+			Ω(err).Should(MatchError(ContainSubstring(`peer "bravo" has the same PID 1 as peer "alpha"`)))
+			Ω(err).Should(MatchError(ContainSubstring(`duplicate peer name "alpha"`)))
+			Ω(err).Should(MatchError(ContainSubstring(`peer "delta" has no port`)))
+			Ω(err).Should(MatchError(ContainSubstring(`peer "echo" has the same address localhost:3264 as peer "alpha"`)))
+			Ω(err).Should(MatchError(ContainSubstring(`local replica "zulu" is not one of the peers`)))
+		})
+
+		It("should report a missing local peer without any peers", func() {
+			config.Peers = []peers.Peer{}
+			Ω(config.Validate()).Should(MatchError(`invalid configuration: local replica "bravo" is not one of the peers`))
+		})
+
 		It("should be able to get it's local peer config", func() {
 			Ω(config.GetName()).Should(Equal("bravo"))
 
@@ -1,10 +1,15 @@
 package epaxos
 
 import (
+	"encoding/json"
 	"fmt"
-	"log"
+	"io"
+	"sort"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/bbengfort/epaxos/pb"
 )
 
 // Levels for implementing the debug and trace message functionality.
@@ -21,121 +26,279 @@ const (
 // CautionThreshold for issuing caution logs after accumulating cautions.
 const CautionThreshold = 80
 
-// These variables are initialized in init()
-var (
-	logLevel        = LogCaution
-	logger          *log.Logger
-	cautionCounter  *counter
-	logLevelStrings = [...]string{
-		"trace", "debug", "info", "caution", "status", "warn", "silent",
-	}
+// CautionLimit is the maximum number of distinct caution messages that are counted
+// at a time, bounding the memory used by the caution rate limiter.
+const CautionLimit = 1024
+
+// Standard field keys used to add structured context to log entries.
+const (
+	FieldReplica  = "replica"
+	FieldPeer     = "peer"
+	FieldInstance = "instance"
+	FieldEvent    = "event"
 )
 
-type counter struct {
-	sync.Mutex
-	counts map[string]uint
+// Names of the log levels
+var logLevelStrings = [...]string{
+	"trace", "debug", "info", "caution", "status", "warn", "silent",
 }
 
-func (c *counter) init() {
-	c.counts = make(map[string]uint)
+// LevelString returns a string representation of the specified level
+func LevelString(level uint8) string {
+	if int(level) < len(logLevelStrings) {
+		return logLevelStrings[level]
+	}
+	return logLevelStrings[LogSilent]
 }
 
 //===========================================================================
-// Interact with debug output
+// Logger Interface
 //===========================================================================
 
-// LogLevel returns a string representation of the current level
-func LogLevel() string {
-	return logLevelStrings[logLevel]
+// Fields are structured key/value pairs that add context to log entries, such as the
+// replica, the remote peer, the instance id, or the event type.
+type Fields map[string]interface{}
+
+// Logger writes leveled messages with structured fields to one or more sinks. Message
+// arguments are handled in the manner of fmt.Printf. Loggers are thread safe and
+// loggers created using With share the level, sinks, and caution counts of their parent.
+type Logger interface {
+	With(fields Fields) Logger            // Create a child logger that adds fields to every entry
+	Level() uint8                         // The current minimum level of messages that are written
+	SetLevel(level uint8)                 // Modify the log level at runtime
+	Trace(msg string, a ...interface{})   // Log a message at the trace level
+	Debug(msg string, a ...interface{})   // Log a message at the debug level
+	Info(msg string, a ...interface{})    // Log a message at the info level
+	Caution(msg string, a ...interface{}) // Log a message at the caution level once it is repeated
+	Status(msg string, a ...interface{})  // Log a message at the status level
+	Warn(msg string, a ...interface{})    // Log a message at the warn level
+	Warne(err error)                      // Log an error at the warn level
 }
 
-// SetLogLevel modifies the log level for messages at runtime. Ensures that
-// the highest level that can be set is the trace level.
-func SetLogLevel(level uint8) {
+// NewLogger creates a logger that writes entries at or above the specified level to
+// each of the sinks. If no sinks are specified, no entries are written.
+func NewLogger(level uint8, sinks ...Sink) Logger {
 	if level > LogSilent {
 		level = LogSilent
 	}
 
-	logLevel = level
+	return &logger{
+		core: &logCore{
+			level:    level,
+			sinks:    sinks,
+			cautions: make(map[string]uint),
+		},
+	}
+}
+
+// logCore is shared by a logger and all of its children.
+type logCore struct {
+	sync.Mutex
+	level    uint8           // minimum level of messages to write
+	sinks    []Sink          // the outputs to write entries to
+	cautions map[string]uint // count of repeated caution messages
 }
 
-// SetLogger sets the logger for writing output to. Can set to a noplog to
-// remove all log messages (or set the log level to silent).
-func SetLogger(l *log.Logger) {
-	logger = l
+// logger implements the Logger interface with the fields for a specific context.
+type logger struct {
+	core   *logCore
+	fields Fields
 }
 
-//===========================================================================
-// Debugging output functions
-//===========================================================================
+// With returns a child logger that adds the fields to all entries it logs.
+func (l *logger) With(fields Fields) Logger {
+	merged := make(Fields, len(l.fields)+len(fields))
+	for key, val := range l.fields {
+		merged[key] = val
+	}
+	for key, val := range fields {
+		merged[key] = val
+	}
+	return &logger{core: l.core, fields: merged}
+}
 
-// Print to the standard logger at the specified level. Arguments are handled
-// in the manner of log.Printf, but a newline is appended.
-func print(level uint8, msg string, a ...interface{}) {
-	if logLevel <= level {
-		if !strings.HasSuffix(msg, "\n") {
-			msg += "\n"
+// Level returns the current log level.
+func (l *logger) Level() uint8 {
+	l.core.Lock()
+	defer l.core.Unlock()
+	return l.core.level
+}
+
+// SetLevel modifies the log level for messages at runtime. Ensures that the highest
+// level that can be set is the silent level.
+func (l *logger) SetLevel(level uint8) {
+	if level > LogSilent {
+		level = LogSilent
+	}
+
+	l.core.Lock()
+	defer l.core.Unlock()
+	l.core.level = level
+}
+
+// Trace logs the message if the level is trace or greater.
+func (l *logger) Trace(msg string, a ...interface{}) {
+	l.print(LogTrace, msg, a...)
+}
+
+// Debug logs the message if the level is debug or greater.
+func (l *logger) Debug(msg string, a ...interface{}) {
+	l.print(LogDebug, msg, a...)
+}
+
+// Info logs the message if the level is info or greater.
+func (l *logger) Info(msg string, a ...interface{}) {
+	l.print(LogInfo, msg, a...)
+}
+
+// Caution messages only log if the number of the same caution messages is greater
+// than the CautionThreshold, reducing the number of log messages in the system but
+// still reporting valuable information. Messages are counted by their unformatted
+// message and the peer field, so the arguments do not create distinct counts. At most
+// CautionLimit messages are counted at a time; if the limit is reached, an arbitrary
+// count is evicted to make room for the new message.
+func (l *logger) Caution(msg string, a ...interface{}) {
+	l.core.Lock()
+	if l.core.level > LogCaution {
+		// Don't waste memory if the log level is set above caution.
+		l.core.Unlock()
+		return
+	}
+
+	key := msg
+	if peer, ok := l.fields[FieldPeer]; ok {
+		key = fmt.Sprintf("%v|%s", peer, msg)
+	}
+
+	if _, ok := l.core.cautions[key]; !ok && len(l.core.cautions) >= CautionLimit {
+		for evict := range l.core.cautions {
+			delete(l.core.cautions, evict)
+			break
 		}
+	}
+
+	l.core.cautions[key]++
+	count := l.core.cautions[key]
+	if count >= CautionThreshold {
+		delete(l.core.cautions, key)
+	}
+	l.core.Unlock()
 
-		logger.Printf(msg, a...)
+	if count >= CautionThreshold {
+		l.With(Fields{"repeated": count}).(*logger).print(LogCaution, msg, a...)
 	}
 }
 
-// Prints to the standard logger if level is warn or greater; arguments are
-// handled in the manner of log.Printf, but a newline is appended.
-func warn(msg string, a ...interface{}) {
-	print(LogWarn, msg, a...)
+// Status logs the message if the level is status or greater.
+func (l *logger) Status(msg string, a ...interface{}) {
+	l.print(LogStatus, msg, a...)
 }
 
-// Helper function to simply warn about an error received.
-func warne(err error) {
-	warn(err.Error())
+// Warn logs the message if the level is warn or greater.
+func (l *logger) Warn(msg string, a ...interface{}) {
+	l.print(LogWarn, msg, a...)
 }
 
-// Prints to the standard logger if level is status or greater; arguments are
-// handled in the manner of log.Printf, but a newline is appended.
-func status(msg string, a ...interface{}) {
-	print(LogStatus, msg, a...)
+// Warne is a helper function to simply warn about an error received.
+func (l *logger) Warne(err error) {
+	l.print(LogWarn, "%s", err)
 }
 
-// Caution messages only log if the number of the same caution messages is
-// greater than the CautionThreshold, reducing the number of log messages
-// in the system but still reporting valuable information.
-//
-// NOTE: take care with string formatting individual messages, this could
-// lead to a very full caution counter that is taking up memory.
-func caution(msg string, a ...interface{}) {
-	if logLevel > LogCaution {
-		// Don't waste memory if the log level is set below caution.
+// Create the entry and write it to all sinks if the level is high enough.
+func (l *logger) print(level uint8, msg string, a ...interface{}) {
+	l.core.Lock()
+	defer l.core.Unlock()
+
+	if l.core.level > level {
 		return
 	}
 
-	cautionCounter.Lock()
-	defer cautionCounter.Unlock()
+	entry := &Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Message: strings.TrimSuffix(fmt.Sprintf(msg, a...), "\n"),
+		Fields:  l.fields,
+	}
+
+	for _, sink := range l.core.sinks {
+		// Cannot log errors writing to the log, so ignore them.
+		sink.Write(entry)
+	}
+}
+
+// Returns the fields that identify the instance in log entries.
+func instanceFields(inst *pb.Instance) Fields {
+	return Fields{FieldInstance: instanceID{inst.Replica, inst.Slot}.String()}
+}
+
+//===========================================================================
+// Log Entries and Sinks
+//===========================================================================
+
+// Entry is a single log message with its level and structured fields.
+type Entry struct {
+	Time    time.Time // the time the message was logged
+	Level   uint8     // the level the message was logged at
+	Message string    // the formatted message
+	Fields  Fields    // structured context associated with the message
+}
 
-	msg = fmt.Sprintf(msg, a...)
-	cautionCounter.counts[msg]++
+// Sink writes log entries to an output. Sinks are not required to be thread safe, the
+// logger ensures that only one entry is written to its sinks at a time.
+type Sink interface {
+	Write(e *Entry) error
+}
+
+// NewTextSink returns a sink that writes human readable lines to the writer in the
+// format "[prefix] 15:04:05.000000 message key=value", sorting fields by key.
+func NewTextSink(w io.Writer, prefix string) Sink {
+	return &textSink{w: w, prefix: prefix}
+}
 
-	if cautionCounter.counts[msg] >= CautionThreshold {
-		print(LogCaution, msg)
-		delete(cautionCounter.counts, msg)
+type textSink struct {
+	w      io.Writer
+	prefix string
+}
+
+func (s *textSink) Write(e *Entry) error {
+	line := new(strings.Builder)
+	line.WriteString(s.prefix)
+	line.WriteString(e.Time.Format("15:04:05.000000 "))
+	line.WriteString(e.Message)
+
+	keys := make([]string, 0, len(e.Fields))
+	for key := range e.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Fprintf(line, " %s=%v", key, e.Fields[key])
 	}
+
+	line.WriteString("\n")
+	_, err := io.WriteString(s.w, line.String())
+	return err
 }
 
-// Prints to the standard logger if level is info or greater; arguments are
-// handled in the manner of log.Printf, but a newline is appended.
-func info(msg string, a ...interface{}) {
-	print(LogInfo, msg, a...)
+// NewJSONSink returns a sink that writes each entry as a JSON object on its own line
+// with the time, level, and message along with the fields of the entry.
+func NewJSONSink(w io.Writer) Sink {
+	return &jsonSink{enc: json.NewEncoder(w)}
 }
 
-// Prints to the standard logger if level is debug or greater; arguments are
-// handled in the manner of log.Printf, but a newline is appended.
-func debug(msg string, a ...interface{}) {
-	print(LogDebug, msg, a...)
+type jsonSink struct {
+	enc *json.Encoder
 }
 
-// Prints to the standard logger if level is trace or greater; arguments are
-// handled in the manner of log.Printf, but a newline is appended.
-func trace(msg string, a ...interface{}) {
-	print(LogTrace, msg, a...)
+func (s *jsonSink) Write(e *Entry) error {
+	obj := make(map[string]interface{}, len(e.Fields)+3)
+	for key, val := range e.Fields {
+		obj[key] = val
+	}
+
+	obj["time"] = e.Time.Format(time.RFC3339Nano)
+	obj["level"] = LevelString(e.Level)
+	obj["msg"] = e.Message
+	return s.enc.Encode(obj)
 }
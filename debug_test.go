@@ -0,0 +1,97 @@
+package epaxos_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/bbengfort/epaxos"
+)
+
+var _ = Describe("Logger", func() {
+
+	var buf *bytes.Buffer
+
+	BeforeEach(func() {
+		buf = new(bytes.Buffer)
+	})
+
+	It("should only write messages at or above the log level", func() {
+		logger := NewLogger(LogInfo, NewTextSink(buf, "[test] "))
+		logger.Debug("not written")
+		logger.Info("written")
+		logger.Warn("also written")
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		Ω(lines).Should(HaveLen(2))
+		Ω(lines[0]).Should(HavePrefix("[test] "))
+		Ω(lines[0]).Should(HaveSuffix(" written"))
+
+		logger.SetLevel(LogTrace)
+		Ω(logger.Level()).Should(Equal(LogTrace))
+		logger.Trace("now written")
+		Ω(buf.String()).Should(ContainSubstring("now written"))
+	})
+
+	It("should write sorted fields in text output", func() {
+		logger := NewLogger(LogTrace, NewTextSink(buf, ""))
+		logger.With(Fields{FieldReplica: "alpha"}).With(Fields{FieldInstance: "1.4"}).Info("instance %s", "committed")
+		Ω(buf.String()).Should(HaveSuffix("instance committed instance=1.4 replica=alpha\n"))
+	})
+
+	It("should write entries as JSON objects", func() {
+		logger := NewLogger(LogTrace, NewJSONSink(buf))
+		logger.With(Fields{FieldPeer: "bravo", FieldEvent: "propose"}).Status("hello %d", 42)
+
+		entry := make(map[string]interface{})
+		Ω(json.Unmarshal(buf.Bytes(), &entry)).Should(Succeed())
+		Ω(entry).Should(HaveKeyWithValue("msg", "hello 42"))
+		Ω(entry).Should(HaveKeyWithValue("level", "status"))
+		Ω(entry).Should(HaveKeyWithValue("peer", "bravo"))
+		Ω(entry).Should(HaveKeyWithValue("event", "propose"))
+		Ω(entry).Should(HaveKey("time"))
+	})
+
+	It("should not share fields between child loggers", func() {
+		logger := NewLogger(LogTrace, NewTextSink(buf, ""))
+		logger.With(Fields{FieldPeer: "alpha"})
+		logger.Info("no fields")
+		Ω(buf.String()).Should(HaveSuffix("no fields\n"))
+	})
+
+	It("should only write repeated cautions at the threshold", func() {
+		logger := NewLogger(LogCaution, NewTextSink(buf, ""))
+		for i := 0; i < CautionThreshold-1; i++ {
+			logger.Caution("dropped message %d", i)
+		}
+		Ω(buf.Len()).Should(BeZero())
+
+		logger.Caution("dropped message %d", CautionThreshold)
+		Ω(buf.String()).Should(ContainSubstring("dropped message 80 repeated=80"))
+	})
+
+	It("should count cautions separately for each peer", func() {
+		logger := NewLogger(LogCaution, NewTextSink(buf, ""))
+		for i := 0; i < CautionThreshold-1; i++ {
+			logger.With(Fields{FieldPeer: "alpha"}).Caution("dropped message")
+		}
+
+		logger.With(Fields{FieldPeer: "bravo"}).Caution("dropped message")
+		Ω(buf.Len()).Should(BeZero())
+
+		logger.With(Fields{FieldPeer: "alpha"}).Caution("dropped message")
+		Ω(buf.String()).Should(ContainSubstring("peer=alpha"))
+	})
+
+	It("should bound the number of cautions counted", func() {
+		logger := NewLogger(LogCaution, NewTextSink(buf, ""))
+		for i := 0; i < CautionLimit*2; i++ {
+			logger.With(Fields{FieldPeer: i}).Caution("unique caution")
+		}
+		Ω(buf.Len()).Should(BeZero())
+	})
+
+})
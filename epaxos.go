@@ -5,9 +5,7 @@ package epaxos
 
 import (
 	"fmt"
-	"log"
 	"math/rand"
-	"os"
 	"strings"
 	"time"
 
@@ -29,11 +27,6 @@ func init() {
 	// Set the random seed to something different each time.
 	rand.Seed(time.Now().UnixNano())
 
-	// Initialize our debug logging with our prefix
-	SetLogger(log.New(os.Stdout, "[epaxos] ", log.Lmicroseconds))
-	cautionCounter = new(counter)
-	cautionCounter.init()
-
 	// Stop the grpc verbose logging
 	grpclog.SetLogger(noplog.New())
 }
@@ -42,11 +35,22 @@ func init() {
 // New ePaxos Instance
 //===========================================================================
 
-// New ePaxos replica with the specified config.
+// New ePaxos replica with the specified config. If a data directory is configured and
+// contains a snapshot of the replica, the replica is restored from the snapshot.
 func New(options *Config) (replica *Replica, err error) {
+	return newReplica(options, true)
+}
+
+// Create a replica with the specified config, restoring it from its snapshot in the
+// data directory if restore is true.
+func newReplica(options *Config, restore bool) (replica *Replica, err error) {
 	// Create a new configuration from defaults, configuration file, and
 	// the environment; then verify it, returning any errors.
 	config := new(Config)
+	if options != nil {
+		config.Path = options.Path
+	}
+
 	if err = config.Load(); err != nil {
 		return nil, err
 	}
@@ -56,20 +60,43 @@ func New(options *Config) (replica *Replica, err error) {
 		return nil, err
 	}
 
-	// Set the logging level and the random seed
-	SetLogLevel(uint8(config.LogLevel))
+	// Create the logger and set the random seed
+	var logger Logger
+	if logger, err = config.GetLogger(); err != nil {
+		return nil, err
+	}
+
 	if config.Seed != 0 {
-		debug("setting random seed to %d", config.Seed)
+		logger.Debug("setting random seed to %d", config.Seed)
 		rand.Seed(config.Seed)
 	}
 
 	// Create and initialize the replica
 	replica = new(Replica)
 	replica.config = config
+	replica.options = options
 	replica.quorum = config.GetQuorum()
+	replica.quorums = map[uint64]uint32{0: replica.quorum}
+	replica.rosters = map[uint64][]peers.Peer{0: config.Peers}
+	replica.votes = make(map[uint64]map[string]bool)
 	replica.thrifty = config.GetThrifty()
+	replica.done = make(chan struct{})
 	replica.clients = make(map[uint64]chan *pb.ProposeReply)
+	replica.pauses = make(map[string]*pause)
+	replica.frontiers = make(map[uint32]map[uint32]uint64)
+	replica.transfers = make(map[uint32]time.Time)
+	replica.installs = make(map[string][]byte)
+	replica.watchers = make(map[chan *pb.Instance]*pb.WatchRequest)
 	replica.logs = NewLog(config)
+	replica.quotas = newQuotas(config)
+	replica.store = newStore()
+
+	// Create the client sessions to deduplicate requests
+	var timeout time.Duration
+	if timeout, err = config.GetSessionTimeout(); err != nil {
+		return nil, err
+	}
+	replica.sessions = newSessions(timeout)
 	// replica.Metrics = NewMetrics()
 
 	// Create the local replica definition
@@ -77,6 +104,23 @@ func New(options *Config) (replica *Replica, err error) {
 	if err != nil {
 		return nil, err
 	}
+	replica.log = logger.With(Fields{FieldReplica: replica.Name})
+
+	// Create the client authenticator and authorizer from the client policies
+	var policies *Policies
+	if policies, err = NewPolicies(config); err != nil {
+		return nil, err
+	}
+	replica.authn = policies
+	replica.authz = policies
+
+	// Restore the state that the replica had written to disk before it was restarted;
+	// the membership of the snapshot determines the remote peers
+	if restore {
+		if err = replica.loadSnapshot(); err != nil {
+			return nil, err
+		}
+	}
 
 	// Fetch all remote peers (e.g. all peers but self)
 	// NOTE: we expect peers to be sorted by PID
@@ -94,14 +138,14 @@ func New(options *Config) (replica *Replica, err error) {
 	}
 
 	// Set state to initialized
-	info("epaxos replica with %d remote peers created", len(replica.remotes))
+	replica.log.Info("epaxos replica with %d remote peers created", len(replica.remotes))
 	if replica.thrifty != nil {
 		pids := make([]string, 0, len(replica.thrifty))
 		for _, p := range replica.thrifty {
 			pids = append(pids, fmt.Sprintf("%d", p))
 		}
 
-		info("thrifty communications to replica PIDs %s", strings.Join(pids, ", "))
+		replica.log.Info("thrifty communications to replica PIDs %s", strings.Join(pids, ", "))
 	}
 	return replica, nil
 }
@@ -1,13 +1,107 @@
 package epaxos_test
 
 import (
+	"encoding/json"
+	"io/ioutil"
+	"sync"
 	"testing"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+
+	. "github.com/bbengfort/epaxos"
+	"github.com/bbengfort/x/peers"
 )
 
 func TestEpaxos(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Epaxos Suite")
 }
+
+// Token of the test client whose policy grants admin access.
+const adminToken = "4dm1n"
+
+// Option that configures a policy granting admin access to clients with the admin
+// token, which is required to use the admin API of the replicas.
+func withAdmin(conf *Config) {
+	conf.Clients = append(conf.Clients, ClientPolicy{Identity: "admin", Token: adminToken, Admin: true})
+}
+
+// Functions that stop the replicas run by the current test.
+var running []func()
+
+// Stop every replica run by the test so that its listener, background routines and
+// connections to peers do not leak into the following tests.
+var _ = AfterEach(func() {
+	for _, stop := range running {
+		stop()
+	}
+	running = nil
+})
+
+// Runs a network of three replicas listening on consecutive ports starting at the
+// specified port and returns the peers of the network. The options modify the
+// configuration of each replica. The replicas are stopped after each test.
+func runNetwork(port int, options ...func(*Config)) []peers.Peer {
+	network := makeNetwork(port, 3)
+	for _, peer := range network {
+		runReplica(peer.Name, network, options...)
+	}
+	return network
+}
+
+// Returns the peers of a network of replicas of the specified size listening on
+// consecutive ports starting at the specified port without running the replicas.
+func makeNetwork(port, size int) []peers.Peer {
+	data, err := ioutil.ReadFile("testdata/config.json")
+	Ω(err).ShouldNot(HaveOccurred())
+
+	var config *Config
+	Ω(json.Unmarshal(data, &config)).Should(Succeed())
+
+	network := config.Peers[:size]
+	for idx := range network {
+		network[idx].Port = uint16(port + idx)
+	}
+	return network
+}
+
+// Runs the named replica of the network with the configuration modified by the options
+// and returns a function that closes the replica and waits for it to stop listening.
+func runReplica(name string, network []peers.Peer, options ...func(*Config)) func() {
+	conf := &Config{
+		Name:     name,
+		Timeout:  "500ms",
+		LogLevel: int(LogSilent),
+		Peers:    network,
+	}
+
+	for _, option := range options {
+		option(conf)
+	}
+
+	replica, err := New(conf)
+	Ω(err).ShouldNot(HaveOccurred())
+	return serve(replica)
+}
+
+// Runs the replica in its own routine and returns a function that closes the replica
+// and waits for it to stop listening. The replica is also stopped after each test.
+func serve(replica *Replica) func() {
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		replica.Listen()
+	}()
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			replica.Close()
+			<-stopped
+		})
+	}
+
+	running = append(running, stop)
+	return stop
+}
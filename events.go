@@ -18,6 +18,16 @@ const (
 	CommitReplyEvent
 	BeaconRequestEvent
 	BeaconReplyEvent
+	StatusRequestEvent
+	FetchRequestEvent
+	WatchRequestEvent
+	UnwatchRequestEvent
+	GraphRequestEvent
+	ResumeEvent
+	SnapshotEvent
+	SnapshotRequestEvent
+	SnapshotReplyEvent
+	ReloadEvent
 )
 
 // Names of event types
@@ -25,6 +35,9 @@ var eventTypeStrings = [...]string{
 	"unknown", "error", "messageReceived", "propose",
 	"preacceptRequested", "preacceptReplied", "acceptRequested", "acceptReplied",
 	"commitRequested", "commitReplied", "beaconRequested", "beaconReplied",
+	"statusRequested", "fetchRequested", "watchRequested", "unwatchRequested",
+	"graphRequested", "phaseResumed", "snapshotTaken",
+	"snapshotRequested", "snapshotReplied", "configReloaded",
 }
 
 //===========================================================================
@@ -62,6 +75,7 @@ type event struct {
 	etype  EventType
 	source interface{}
 	value  interface{}
+	peer   string // name of the peer or client identity that sent the event
 }
 
 // Type returns the event type.
@@ -93,6 +107,8 @@ func requestEvent(req *pb.PeerRequest) *event {
 		return &event{etype: CommitRequestEvent, value: req.GetCommit()}
 	case pb.Type_BEACON:
 		return &event{etype: BeaconRequestEvent, value: req.GetBeacon()}
+	case pb.Type_SNAPSHOT:
+		return &event{etype: SnapshotRequestEvent, value: req.GetSnapshot()}
 	case pb.Type_UNKNOWN:
 		return &event{etype: UnknownEvent, value: req}
 	default:
@@ -110,6 +126,8 @@ func replyEvent(rep *pb.PeerReply) *event {
 		return &event{etype: CommitReplyEvent, value: rep.GetCommit()}
 	case pb.Type_BEACON:
 		return &event{etype: BeaconReplyEvent, value: rep.GetBeacon()}
+	case pb.Type_SNAPSHOT:
+		return &event{etype: SnapshotReplyEvent, value: rep.GetSnapshot()}
 	case pb.Type_UNKNOWN:
 		return &event{etype: UnknownEvent, value: rep}
 	default:
@@ -0,0 +1,180 @@
+package epaxos
+
+import (
+	"sort"
+
+	"github.com/bbengfort/epaxos/pb"
+)
+
+//===========================================================================
+// Instance Execution
+//===========================================================================
+
+// Execute all committed instances whose dependencies have been committed. Instances
+// are executed by finding the strongly connected components of the dependency graph
+// and executing the components in reverse topological order; instances in the same
+// component are executed in sequence order. Because all replicas commit the same
+// dependencies and sequence numbers for an instance, conflicting instances are
+// executed in the same order on every replica. Nothing is executed while the execute
+// phase is paused.
+func (r *Replica) execute() {
+	// Instances are executed after they are committed, so stop authenticating the
+	// replicas of epochs that no longer have instances waiting to be committed
+	r.updatePending()
+
+	if _, ok := r.pauses[PhaseExecute]; ok {
+		return
+	}
+
+	for _, pid := range r.logs.replicas() {
+		rlog := r.logs.logs[pid]
+		for slot := rlog.executed(); slot < rlog.nextSlot(); slot++ {
+			if inst := rlog.get(slot); inst.Status == pb.Status_COMMITTED {
+				r.executeGraph(inst)
+			}
+		}
+	}
+
+	// Propose to expire any idle client sessions
+	for _, op := range r.sessions.idle() {
+		r.propose(op)
+	}
+
+	// Transfer the state to the replicas added by this replica so that they can join
+	for _, pid := range r.joining {
+		r.transfer(pid)
+	}
+	r.joining = nil
+}
+
+// Execute the instance along with all of its unexecuted dependencies. If any of the
+// dependencies have not been committed, nothing is executed and false is returned.
+func (r *Replica) executeGraph(root *pb.Instance) bool {
+	graph, ok := r.logs.executionGraph(root)
+	if !ok {
+		return false
+	}
+
+	for _, scc := range graph.components() {
+		// Execute the instances of the component in sequence order, breaking ties
+		// by replica PID and slot so that the order is the same on every replica.
+		sort.Slice(scc, func(i, j int) bool {
+			a, b := graph.nodes[scc[i]], graph.nodes[scc[j]]
+			if a.Seq != b.Seq {
+				return a.Seq < b.Seq
+			}
+			if scc[i].replica != scc[j].replica {
+				return scc[i].replica < scc[j].replica
+			}
+			return scc[i].slot < scc[j].slot
+		})
+
+		for _, id := range scc {
+			r.apply(graph.nodes[id])
+		}
+	}
+
+	return true
+}
+
+// Apply the operations of the instance to the store through the client sessions and
+// mark the instance as executed. The operations of each client request are applied
+// atomically as a transaction, and membership changes are applied to the replica. If
+// this replica led the instance, the clients that proposed the operations are replied
+// to with the result.
+func (r *Replica) apply(inst *pb.Instance) {
+	for _, txn := range transactions(inst.Ops) {
+		res := r.sessions.execute(txn[0], func() *result {
+			if txn[0].Type == pb.AccessType_RECONFIGURE {
+				return r.reconfigure(txn[0], inst.Replica)
+			}
+			return r.store.transact(txn)
+		})
+
+		if inst.Replica != r.PID {
+			continue
+		}
+
+		if source, ok := r.clients[txn[0].Request]; ok {
+			source <- res.reply(inst.Slot)
+			delete(r.clients, txn[0].Request)
+		}
+	}
+
+	inst.Status = pb.Status_EXECUTED
+	r.notify(inst)
+	r.log.With(instanceFields(inst)).Debug("instance executed")
+}
+
+// Groups the consecutive operations of the same client request into transactions;
+// operations that are not associated with a request are applied on their own.
+func transactions(ops []*pb.Operation) [][]*pb.Operation {
+	txns := make([][]*pb.Operation, 0, len(ops))
+	for idx, op := range ops {
+		if idx > 0 && op.Request != 0 && op.Request == ops[idx-1].Request {
+			txns[len(txns)-1] = append(txns[len(txns)-1], op)
+			continue
+		}
+		txns = append(txns, []*pb.Operation{op})
+	}
+	return txns
+}
+
+// Collect the graph of unexecuted instances reachable from the root instance. An
+// instance depends on every instance in its dependencies as well as the previous
+// instance in its replica's log, since dependencies only record the latest conflicting
+// slot in each replica's log. Returns false if any instance in the graph has not been
+// committed or is missing from the log, in which case the root cannot be executed yet.
+func (l *Logs) executionGraph(root *pb.Instance) (graph *depGraph, ok bool) {
+	graph = &depGraph{
+		nodes:   make(map[instanceID]*pb.Instance),
+		order:   make([]instanceID, 0),
+		missing: make(map[instanceID]bool),
+		ordered: true,
+	}
+
+	start := instanceID{root.Replica, root.Slot}
+	graph.nodes[start] = root
+	graph.order = append(graph.order, start)
+
+	for queue := []instanceID{start}; len(queue) > 0; queue = queue[1:] {
+		for _, dep := range graph.edges(queue[0]) {
+			if _, seen := graph.nodes[dep]; seen {
+				continue
+			}
+
+			// Truncated instances have been executed by every replica
+			if l.truncated(dep.replica, dep.slot) {
+				continue
+			}
+
+			inst, err := l.Get(dep.replica, dep.slot)
+			if err != nil {
+				return nil, false
+			}
+
+			switch inst.Status {
+			case pb.Status_EXECUTED:
+				continue
+			case pb.Status_COMMITTED:
+				graph.nodes[dep] = inst
+				graph.order = append(graph.order, dep)
+				queue = append(queue, dep)
+			default:
+				return nil, false
+			}
+		}
+	}
+
+	return graph, true
+}
+
+// Returns the PIDs of the replicas in the log in sorted order.
+func (l *Logs) replicas() []uint32 {
+	pids := make([]uint32, 0, len(l.logs))
+	for pid := range l.logs {
+		pids = append(pids, pid)
+	}
+	sort.Slice(pids, func(i, j int) bool { return pids[i] < pids[j] })
+	return pids
+}
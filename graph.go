@@ -0,0 +1,242 @@
+package epaxos
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/bbengfort/epaxos/pb"
+)
+
+// Fill colors of instance nodes by status in the DOT output.
+var statusColors = map[pb.Status]string{
+	pb.Status_INITIAL:     "white",
+	pb.Status_PREACCEPTED: "lightyellow",
+	pb.Status_ACCEPTED:    "khaki",
+	pb.Status_COMMITTED:   "lightblue",
+	pb.Status_EXECUTED:    "palegreen",
+}
+
+// instanceID identifies an instance in the 2D log by leader replica and slot.
+type instanceID struct {
+	replica uint32
+	slot    uint64
+}
+
+// String returns the node name of the instance in the dependency graph.
+func (id instanceID) String() string {
+	return fmt.Sprintf("%d.%d", id.replica, id.slot)
+}
+
+// depGraph is the subgraph of instances reachable from a root instance by following
+// the instance dependencies. Dependencies that are not in the log are missing.
+type depGraph struct {
+	nodes   map[instanceID]*pb.Instance // instances in the subgraph
+	order   []instanceID                // the order the nodes were discovered
+	missing map[instanceID]bool         // dependencies that are not in the log
+	ordered bool                        // instances also depend on the previous slot in their log
+}
+
+//===========================================================================
+// Dependency Graph
+//===========================================================================
+
+// WriteDOT writes the subgraph of instances reachable from the instance at the
+// specified replica and slot as a Graphviz DOT document. Nodes are labeled with the
+// status and sequence number of the instance and edges point from an instance to its
+// dependencies. Strongly connected components that must be executed together are
+// highlighted as clusters and dependencies missing from the log are drawn dashed.
+func (l *Logs) WriteDOT(w io.Writer, replica uint32, slot uint64) (err error) {
+	var graph *depGraph
+	if graph, err = l.subgraph(replica, slot); err != nil {
+		return err
+	}
+
+	buf := bufio.NewWriter(w)
+	fmt.Fprintf(buf, "digraph \"%d.%d\" {\n", replica, slot)
+	fmt.Fprintln(buf, "  rankdir=LR;")
+	fmt.Fprintln(buf, "  node [shape=box, style=filled, fontname=\"Helvetica\"];")
+
+	// Highlight the strongly connected components with more than one member
+	for idx, scc := range graph.components() {
+		if len(scc) < 2 {
+			continue
+		}
+
+		fmt.Fprintf(buf, "  subgraph cluster_scc%d {\n", idx)
+		fmt.Fprintf(buf, "    label=\"SCC %d\";\n    style=filled;\n    color=lightgrey;\n", idx)
+		for _, id := range scc {
+			fmt.Fprintf(buf, "    \"%s\";\n", id)
+		}
+		fmt.Fprintln(buf, "  }")
+	}
+
+	// Write the nodes then the edges in the order they were discovered
+	for _, id := range graph.order {
+		inst := graph.nodes[id]
+		fmt.Fprintf(
+			buf, "  \"%s\" [label=\"%s\\n%s\\nseq=%d\", fillcolor=%s];\n",
+			id, id, inst.Status, inst.Seq, statusColors[inst.Status],
+		)
+	}
+
+	for _, id := range graph.sortedMissing() {
+		fmt.Fprintf(buf, "  \"%s\" [label=\"%s\\nMISSING\", style=dashed];\n", id, id)
+	}
+
+	for _, id := range graph.order {
+		for _, dep := range dependencies(graph.nodes[id]) {
+			if graph.missing[dep] {
+				fmt.Fprintf(buf, "  \"%s\" -> \"%s\" [style=dashed];\n", id, dep)
+			} else {
+				fmt.Fprintf(buf, "  \"%s\" -> \"%s\";\n", id, dep)
+			}
+		}
+	}
+
+	fmt.Fprintln(buf, "}")
+	return buf.Flush()
+}
+
+// Collect the subgraph of instances reachable from the specified instance using a
+// breadth first search of the dependencies of each instance.
+func (l *Logs) subgraph(replica uint32, slot uint64) (graph *depGraph, err error) {
+	var root *pb.Instance
+	if root, err = l.Get(replica, slot); err != nil {
+		return nil, err
+	}
+
+	graph = &depGraph{
+		nodes:   make(map[instanceID]*pb.Instance),
+		order:   make([]instanceID, 0),
+		missing: make(map[instanceID]bool),
+	}
+
+	start := instanceID{replica, slot}
+	graph.nodes[start] = root
+	graph.order = append(graph.order, start)
+
+	for queue := []instanceID{start}; len(queue) > 0; queue = queue[1:] {
+		for _, dep := range dependencies(graph.nodes[queue[0]]) {
+			if _, seen := graph.nodes[dep]; seen || graph.missing[dep] {
+				continue
+			}
+
+			inst, err := l.Get(dep.replica, dep.slot)
+			if err != nil {
+				graph.missing[dep] = true
+				continue
+			}
+
+			graph.nodes[dep] = inst
+			graph.order = append(graph.order, dep)
+			queue = append(queue, dep)
+		}
+	}
+
+	return graph, nil
+}
+
+// Compute the strongly connected components of the graph using Tarjan's algorithm.
+// Components are returned in reverse topological order, e.g. every component is
+// returned after all of the components it depends on, which is the order that the
+// components must be executed in. Dependencies that are not in the graph, e.g. missing
+// or already executed instances, are not part of any component.
+func (g *depGraph) components() [][]instanceID {
+	var (
+		index   uint64
+		stack   []instanceID
+		sccs    [][]instanceID
+		indices = make(map[instanceID]uint64)
+		lowlink = make(map[instanceID]uint64)
+		onstack = make(map[instanceID]bool)
+	)
+
+	var connect func(id instanceID)
+	connect = func(id instanceID) {
+		indices[id] = index
+		lowlink[id] = index
+		index++
+		stack = append(stack, id)
+		onstack[id] = true
+
+		for _, dep := range g.edges(id) {
+			if _, ok := g.nodes[dep]; !ok {
+				continue
+			}
+
+			if _, visited := indices[dep]; !visited {
+				connect(dep)
+				if lowlink[dep] < lowlink[id] {
+					lowlink[id] = lowlink[dep]
+				}
+			} else if onstack[dep] && indices[dep] < lowlink[id] {
+				lowlink[id] = indices[dep]
+			}
+		}
+
+		// If this is a root node, pop the stack to generate the component
+		if lowlink[id] == indices[id] {
+			scc := make([]instanceID, 0)
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onstack[top] = false
+				scc = append(scc, top)
+				if top == id {
+					break
+				}
+			}
+			sccs = append(sccs, scc)
+		}
+	}
+
+	for _, id := range g.order {
+		if _, visited := indices[id]; !visited {
+			connect(id)
+		}
+	}
+
+	return sccs
+}
+
+// Returns the instances that the instance depends on in the graph. Edges to instances
+// that are not in the graph, e.g. executed instances, are ignored by the caller.
+func (g *depGraph) edges(id instanceID) []instanceID {
+	deps := dependencies(g.nodes[id])
+	if g.ordered && id.slot > 0 {
+		deps = append(deps, instanceID{id.replica, id.slot - 1})
+	}
+	return deps
+}
+
+// Returns the missing dependencies sorted by replica and slot for stable output.
+func (g *depGraph) sortedMissing() []instanceID {
+	missing := make([]instanceID, 0, len(g.missing))
+	for id := range g.missing {
+		missing = append(missing, id)
+	}
+	sortInstanceIDs(missing)
+	return missing
+}
+
+// Returns the dependencies of the instance sorted by replica PID.
+func dependencies(inst *pb.Instance) []instanceID {
+	deps := make([]instanceID, 0, len(inst.Deps))
+	for pid, slot := range inst.Deps {
+		deps = append(deps, instanceID{pid, slot})
+	}
+	sortInstanceIDs(deps)
+	return deps
+}
+
+// Sorts instance identifiers by replica PID then by slot.
+func sortInstanceIDs(ids []instanceID) {
+	sort.Slice(ids, func(i, j int) bool {
+		if ids[i].replica == ids[j].replica {
+			return ids[i].slot < ids[j].slot
+		}
+		return ids[i].replica < ids[j].replica
+	})
+}
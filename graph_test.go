@@ -0,0 +1,73 @@
+package epaxos_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/bbengfort/epaxos"
+	"github.com/bbengfort/epaxos/pb"
+)
+
+var _ = Describe("Graph", func() {
+
+	var logs *Logs
+
+	BeforeEach(func() {
+		var config *Config
+		data, err := ioutil.ReadFile("testdata/config.json")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(json.Unmarshal(data, &config)).Should(Succeed())
+
+		// Create a dependency cycle between 1.0 and 2.0 and a dependency on the
+		// cycle and on a missing instance from 1.1
+		logs = NewLog(config)
+		instances := []*pb.Instance{
+			{Replica: 1, Slot: 0, Seq: 1, Deps: map[uint32]uint64{2: 0}, Status: pb.Status_COMMITTED},
+			{Replica: 2, Slot: 0, Seq: 1, Deps: map[uint32]uint64{1: 0}, Status: pb.Status_COMMITTED},
+			{Replica: 1, Slot: 1, Seq: 2, Deps: map[uint32]uint64{1: 0, 3: 0}, Status: pb.Status_PREACCEPTED},
+		}
+
+		for _, inst := range instances {
+			Ω(logs.Insert(inst)).Should(Succeed())
+		}
+	})
+
+	It("should export the reachable subgraph as DOT", func() {
+		buf := new(bytes.Buffer)
+		Ω(logs.WriteDOT(buf, 1, 1)).Should(Succeed())
+
+		dot := buf.String()
+		Ω(dot).Should(HavePrefix("digraph \"1.1\" {"))
+		Ω(dot).Should(ContainSubstring(`"1.1" [label="1.1\nPREACCEPTED\nseq=2"`))
+		Ω(dot).Should(ContainSubstring(`"1.1" -> "1.0";`))
+		Ω(dot).Should(ContainSubstring(`"1.0" -> "2.0";`))
+		Ω(dot).Should(ContainSubstring(`"2.0" -> "1.0";`))
+		Ω(dot).Should(ContainSubstring(`"3.0" [label="3.0\nMISSING", style=dashed];`))
+		Ω(dot).Should(ContainSubstring(`"1.1" -> "3.0" [style=dashed];`))
+	})
+
+	It("should highlight strongly connected components", func() {
+		buf := new(bytes.Buffer)
+		Ω(logs.WriteDOT(buf, 1, 1)).Should(Succeed())
+
+		dot := buf.String()
+		Ω(dot).Should(ContainSubstring("subgraph cluster_scc0 {"))
+		Ω(dot).ShouldNot(ContainSubstring("subgraph cluster_scc1 {"))
+	})
+
+	It("should only include instances reachable from the root", func() {
+		buf := new(bytes.Buffer)
+		Ω(logs.WriteDOT(buf, 2, 0)).Should(Succeed())
+		Ω(buf.String()).ShouldNot(ContainSubstring(`"1.1"`))
+	})
+
+	It("should not export a graph for a missing instance", func() {
+		buf := new(bytes.Buffer)
+		Ω(logs.WriteDOT(buf, 1, 10)).Should(MatchError("no instance found for replica PID 1 in slot 10"))
+	})
+
+})
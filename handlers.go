@@ -1,52 +1,96 @@
 package epaxos
 
 import (
-	"fmt"
-
 	"github.com/bbengfort/epaxos/pb"
+	"github.com/golang/protobuf/proto"
 )
 
 func (r *Replica) onProposeRequest(e Event) (err error) {
 	// Unpack the request from the event
 	req := e.Value().(*pb.ProposeRequest)
 
-	// Associate the operation with the source to reply to the client on execute
+	// PAUSE operations are applied to this replica rather than proposed
+	if req.Op != nil && req.Op.Type == pb.AccessType_PAUSE && len(req.Txn) == 0 {
+		rep := &pb.ProposeReply{Success: true, Key: req.Op.Key, Value: req.Op.Value}
+		if err = r.pause(req.Op); err != nil {
+			rep.Success, rep.Error = false, err.Error()
+		}
+		e.Source().(chan *pb.ProposeReply) <- rep
+		return nil
+	}
+
+	// Replicas that have been removed from the quorum cannot commit instances
+	if !r.member() {
+		e.Source().(chan *pb.ProposeReply) <- &pb.ProposeReply{Success: false, Error: ErrRemoved.Error()}
+		return nil
+	}
+
+	// The operations of a transaction are proposed together in a single instance
+	ops := req.Txn
+	if len(ops) == 0 {
+		ops = []*pb.Operation{req.Op}
+	}
+
+	// Associate the operations with the source to reply to the client on execute
 	r.nops++
-	req.Op.Request = r.nops
 	source := e.Source().(chan *pb.ProposeReply)
 	r.clients[r.nops] = source
 
-	// Create an Instance with the operation
+	// Associate the operations with the client session for deduplication
+	for _, op := range ops {
+		op.Request = r.nops
+		op.Client = req.Identity
+		op.Sequence = req.Sequence
+	}
+
+	return r.propose(ops...)
+}
+
+// Create an instance with the operations in the replica's log and broadcast the
+// preaccept request for the instance to the quorum of the current membership epoch.
+func (r *Replica) propose(ops ...*pb.Operation) (err error) {
+	// Create an Instance with the operations
 	// QUESTION: What happens to the memory associated with the request? Is it released?
 	var inst *pb.Instance
-	if inst, err = r.logs.Create(r.PID, []*pb.Operation{req.Op}); err != nil {
+	if inst, err = r.logs.Create(r.PID, ops); err != nil {
 		return err
 	}
+	inst.Epoch = r.epoch
+	r.notify(inst)
 
-	// Broadcast PreAccept Request for instance
-	r.Broadcast(pb.WrapPreacceptRequest(r.Name, &pb.PreacceptRequest{Inst: inst}), false)
+	// Broadcast PreAccept Request for a copy of the instance, which is modified while
+	// the request is being sent as replies are received from the quorum.
+	r.Broadcast(pb.WrapPreacceptRequest(r.Name, &pb.PreacceptRequest{Inst: proto.Clone(inst).(*pb.Instance)}), false)
 	return nil
 }
 
 func (r *Replica) onPreacceptRequest(e Event) (err error) {
 	// Unpack the request from the event and get the replica log
 	req := e.Value().(*pb.PreacceptRequest)
+	if r.paused(PhasePreaccept, func() error { return r.onPreacceptRequest(e) }) {
+		return nil
+	}
 	rlog := r.logs.logs[req.Inst.Replica]
+	source := e.Source().(chan *pb.PeerReply)
 
-	// Ensure we are appending the next instance (no out of order instances)
+	// Ensure we are appending the next instance (no out of order instances). Do not
+	// stop the replica if there is a gap in the log, e.g. while the replica is behind
+	// and waiting for a snapshot transfer, but do not vote for the instance either.
 	// TODO: move this to the log to return an error if needed.
 	if req.Inst.Slot != rlog.nextSlot() {
-		return fmt.Errorf("cannot append instance with slot %d into log at slot %d", req.Inst.Slot, rlog.nextSlot())
+		r.log.Caution("cannot append instance with slot %d into log at slot %d", req.Inst.Slot, rlog.nextSlot())
+		source <- &pb.PeerReply{Type: pb.Type_PREACCEPT, Sender: r.Name, Success: false}
+		return nil
 	}
 
 	// Append the instance into the log for that replica
 	rlog.instances = append(rlog.instances, req.Inst)
 	changed := r.logs.updateDependencies(req.Inst)
 	r.logs.updateConflicts(req.Inst)
+	r.notify(req.Inst)
 
 	// Prepare the reply
 	// TODO: make the channel directional
-	source := e.Source().(chan *pb.PeerReply)
 	source <- pb.WrapPreacceptReply(r.Name, &pb.PreacceptReply{
 		Slot:    req.Inst.Slot,
 		Seq:     req.Inst.Seq,
@@ -60,7 +104,10 @@ func (r *Replica) onPreacceptReply(e Event) (err error) {
 	// Unpack the reply from the event and fetch the instance
 	// TODO make the instance fetch from the logs much nicer
 	rep := e.Value().(*pb.PreacceptReply)
-	inst := r.logs.logs[r.PID].instances[rep.Slot]
+	inst := r.logs.logs[r.PID].get(rep.Slot)
+	if inst == nil {
+		return nil
+	}
 
 	// Ensure the sequence is monotonically increasing
 	// TODO: move this helper to the log
@@ -89,18 +136,20 @@ func (r *Replica) onPreacceptReply(e Event) (err error) {
 		}
 	}
 
-	// Determine if we've reached a quorum
-	// TODO: add the quorum size to the logs
-	Q := uint32((len(r.logs.logs) / 2) + 1)
-
-	if inst.Status == pb.Status_INITIAL && inst.Acks >= Q {
+	// Determine if we've reached a quorum of every epoch since the instance was proposed
+	if inst.Status == pb.Status_INITIAL && r.vote(inst, e.(*event).peer) {
 		inst.Status = pb.Status_PREACCEPTED
 		inst.Acks = 0
+		r.resetVotes(inst)
+		r.notify(inst)
 
 		if inst.Changed {
 			// Slow Path
+			r.log.With(instanceFields(inst)).Debug("instance preaccepted with conflicts, taking the slow path")
+			inst.Status = pb.Status_ACCEPTED
 			inst.Acks = 1
-			r.Broadcast(pb.WrapAcceptRequest(r.Name, &pb.AcceptRequest{Inst: inst}), false)
+			r.notify(inst)
+			r.Broadcast(pb.WrapAcceptRequest(r.Name, &pb.AcceptRequest{Inst: proto.Clone(inst).(*pb.Instance)}), false)
 		} else {
 			// Fast Path
 			r.Commit(inst)
@@ -111,16 +160,102 @@ func (r *Replica) onPreacceptReply(e Event) (err error) {
 	return nil
 }
 
+func (r *Replica) onAcceptRequest(e Event) (err error) {
+	// Unpack the request from the event and accept the instance
+	req := e.Value().(*pb.AcceptRequest)
+	if r.paused(PhaseAccept, func() error { return r.onAcceptRequest(e) }) {
+		return nil
+	}
+
+	source := e.Source().(chan *pb.PeerReply)
+
+	var inst *pb.Instance
+	if inst, err = r.logs.Update(req.Inst, pb.Status_ACCEPTED); err != nil {
+		// Do not stop the replica if the instance cannot be accepted, e.g. if there
+		// is a gap in the log, but do not vote for the instance either.
+		r.log.Caution("could not accept instance: %s", err)
+		source <- &pb.PeerReply{Type: pb.Type_ACCEPT, Sender: r.Name, Success: false}
+		return nil
+	}
+	r.notify(inst)
+
+	source <- pb.WrapAcceptReply(r.Name, &pb.AcceptReply{Slot: inst.Slot})
+	return nil
+}
+
+func (r *Replica) onAcceptReply(e Event) (err error) {
+	// Unpack the reply from the event and fetch the instance
+	rep := e.Value().(*pb.AcceptReply)
+
+	// The instance may have been truncated or replaced by an installed snapshot, which
+	// must not stop the replica
+	var inst *pb.Instance
+	if inst, err = r.logs.Get(r.PID, rep.Slot); err != nil {
+		r.log.Caution("could not count accept vote: %s", err)
+		return nil
+	}
+
+	// Only count votes if we're still trying to accept
+	if inst.Status != pb.Status_ACCEPTED {
+		return nil
+	}
+
+	inst.Acks++
+	if r.vote(inst, e.(*event).peer) {
+		inst.Acks = 0
+		r.resetVotes(inst)
+		r.Commit(inst)
+	}
+
+	return nil
+}
+
+func (r *Replica) onCommitRequest(e Event) (err error) {
+	// Unpack the request from the event and commit the instance
+	req := e.Value().(*pb.CommitRequest)
+	if r.paused(PhaseCommit, func() error { return r.onCommitRequest(e) }) {
+		return nil
+	}
+
+	source := e.Source().(chan *pb.PeerReply)
+
+	var inst *pb.Instance
+	if inst, err = r.logs.Update(req.Inst, pb.Status_COMMITTED); err != nil {
+		// Do not stop the replica if the instance cannot be committed, e.g. if there
+		// is a gap in the log; the instance cannot be executed until it is recovered.
+		r.log.Caution("could not commit instance: %s", err)
+		source <- &pb.PeerReply{Type: pb.Type_COMMIT, Sender: r.Name, Success: false}
+		return nil
+	}
+	r.notify(inst)
+
+	source <- pb.WrapCommitReply(r.Name, &pb.CommitReply{Slot: inst.Slot})
+
+	// Execute the instance and any instances that were waiting for it to commit
+	r.execute()
+	return nil
+}
+
+func (r *Replica) onCommitReply(e Event) (err error) {
+	return nil
+}
+
 func (r *Replica) onBeaconRequest(e Event) (err error) {
+	req := e.Value().(*pb.BeaconRequest)
+	r.observeFrontier(req.Replica, req.Executed)
+
 	source := e.Source().(chan *pb.PeerReply)
 	source <- pb.WrapBeaconReply(r.Name, &pb.BeaconReply{
 		QuorumMember: true,
 		Replica:      r.PID,
+		Executed:     r.logs.Executed(),
 	})
 
 	return nil
 }
 
 func (r *Replica) onBeaconReply(e Event) (err error) {
+	rep := e.Value().(*pb.BeaconReply)
+	r.observeFrontier(rep.Replica, rep.Executed)
 	return nil
 }
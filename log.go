@@ -39,7 +39,9 @@ type Logs struct {
 // An internal type for the slice of Instances assigned to each replica.
 type replicaLog struct {
 	conflicts map[string]uint64 // cache of key to latest instance to optimize conflict detection
-	instances []*pb.Instance    // the instances created by the replica
+	instances []*pb.Instance    // the instances created by the replica that have not been truncated
+	offset    uint64            // the slot of the first instance that has not been truncated
+	frontier  uint64            // the first slot that has not been executed
 }
 
 //===========================================================================
@@ -101,7 +103,7 @@ func (l *replicaLog) insert(inst *pb.Instance) (err error) {
 	}
 
 	if inst.Slot < next {
-		if l.instances[inst.Slot] != nil {
+		if l.get(inst.Slot) != nil {
 			return fmt.Errorf("there is already an instance in slot %d", inst.Slot)
 		}
 		return fmt.Errorf("cannot append to slot %d before next slot %d", inst.Slot, next)
@@ -111,6 +113,48 @@ func (l *replicaLog) insert(inst *pb.Instance) (err error) {
 	return nil
 }
 
+// Update the instance in the log with the sequence number, dependencies, and
+// operations decided by the leader of the instance, e.g. when the instance is accepted
+// or committed. If the instance is the next instance in the leader's log, e.g. because
+// the preaccept was not received, it is appended to the log. The status of the
+// instance is never regressed, so a committed instance is not marked accepted again.
+func (l *Logs) Update(inst *pb.Instance, status pb.Status) (local *pb.Instance, err error) {
+	var rlog *replicaLog
+	if rlog, err = l.replicaLog(inst.Replica); err != nil {
+		return nil, err
+	}
+
+	// Empty dependencies are unmarshaled as a nil map from remote peers.
+	if inst.Deps == nil {
+		inst.Deps = make(map[uint32]uint64)
+	}
+
+	if inst.Slot == rlog.nextSlot() {
+		inst.Status = status
+		inst.Acks = 0
+		rlog.instances = append(rlog.instances, inst)
+		l.updateConflicts(inst)
+		l.observe(inst)
+		return inst, nil
+	}
+
+	if local, err = l.Get(inst.Replica, inst.Slot); err != nil {
+		return nil, err
+	}
+
+	if local.Status >= status {
+		return local, nil
+	}
+
+	local.Seq = inst.Seq
+	local.Deps = inst.Deps
+	local.Ops = inst.Ops
+	local.Status = status
+	l.updateConflicts(local)
+	l.observe(local)
+	return local, nil
+}
+
 // Get an instance in the specified replica's log at the specified index.
 func (l *Logs) Get(replica uint32, slot uint64) (inst *pb.Instance, err error) {
 	var rlog *replicaLog
@@ -122,7 +166,158 @@ func (l *Logs) Get(replica uint32, slot uint64) (inst *pb.Instance, err error) {
 		return nil, fmt.Errorf("no instance found for replica PID %d in slot %d", replica, slot)
 	}
 
-	return rlog.instances[slot], nil
+	if slot < rlog.offset {
+		return nil, ErrTruncated
+	}
+
+	return rlog.get(slot), nil
+}
+
+// Truncate the executed instances below the specified slot of each replica's log,
+// keyed by replica PID, releasing them from memory. Instances that have not been
+// executed are never truncated. Conflicts on truncated instances are also removed
+// since new instances do not need to be ordered after instances executed by every
+// replica. Returns the number of instances truncated.
+func (l *Logs) Truncate(slots map[uint32]uint64) (truncated int) {
+	for pid, slot := range slots {
+		rlog, ok := l.logs[pid]
+		if !ok {
+			continue
+		}
+
+		if executed := rlog.executed(); slot > executed {
+			slot = executed
+		}
+
+		if slot <= rlog.offset {
+			continue
+		}
+
+		// Copy the remaining instances so the truncated instances can be released
+		remaining := rlog.instances[slot-rlog.offset:]
+		rlog.instances = append(make([]*pb.Instance, 0, len(remaining)), remaining...)
+		truncated += int(slot - rlog.offset)
+		rlog.offset = slot
+
+		for key, conflict := range rlog.conflicts {
+			if conflict < slot {
+				delete(rlog.conflicts, key)
+			}
+		}
+	}
+	return truncated
+}
+
+// Install replaces the instances of every replica's log with the instances of the tail
+// of a snapshot transfer. The tail must contain the consecutive instances of each
+// replica's log starting at the executed frontier of the snapshot, which becomes the
+// offset of the log; replicas in the frontier that do not have a log, e.g. replicas
+// added to the membership, are given one. Executed instances in the tail are marked
+// committed so that they are executed on this replica after the snapshot. Local
+// instances beyond the end of the tail, e.g. instances proposed by this replica that
+// the sender has not received, are kept so that their slots are not reused. The logs
+// are not modified if the tail is not consecutive.
+func (l *Logs) Install(executed map[uint32]uint64, sequence uint64, tail []*pb.Instance) error {
+	logs := make(map[uint32]*replicaLog, len(l.logs))
+	install := func(pid uint32) {
+		logs[pid] = &replicaLog{
+			conflicts: make(map[string]uint64),
+			instances: make([]*pb.Instance, 0),
+			offset:    executed[pid],
+			frontier:  executed[pid],
+		}
+	}
+
+	for pid := range l.logs {
+		install(pid)
+	}
+
+	for pid := range executed {
+		install(pid)
+	}
+
+	for _, inst := range tail {
+		rlog, ok := logs[inst.Replica]
+		if !ok {
+			return fmt.Errorf("no log for replica with PID %d", inst.Replica)
+		}
+
+		if inst.Slot != rlog.nextSlot() {
+			return fmt.Errorf("cannot install instance in slot %d when next slot is %d", inst.Slot, rlog.nextSlot())
+		}
+
+		// Empty dependencies are unmarshaled as a nil map from remote peers.
+		if inst.Deps == nil {
+			inst.Deps = make(map[uint32]uint64)
+		}
+
+		if inst.Status == pb.Status_EXECUTED {
+			inst.Status = pb.Status_COMMITTED
+		}
+		inst.Acks = 0
+		rlog.instances = append(rlog.instances, inst)
+	}
+
+	// Keep the local instances that are beyond the end of the tail of each log
+	kept := make([]*pb.Instance, 0)
+	for pid, local := range l.logs {
+		rlog := logs[pid]
+		if rlog.nextSlot() < local.offset {
+			continue
+		}
+
+		for slot := rlog.nextSlot(); slot < local.nextSlot(); slot++ {
+			inst := local.get(slot)
+			rlog.instances = append(rlog.instances, inst)
+			kept = append(kept, inst)
+		}
+	}
+
+	l.logs = logs
+	if sequence > l.sequence {
+		l.sequence = sequence
+	}
+
+	for _, instances := range [][]*pb.Instance{tail, kept} {
+		for _, inst := range instances {
+			l.updateConflicts(inst)
+			l.observe(inst)
+		}
+	}
+	return nil
+}
+
+// Add an empty log for the replica if it does not have a log, e.g. when the replica
+// is added to the membership.
+func (l *Logs) addLog(replica uint32) {
+	if _, ok := l.logs[replica]; !ok {
+		l.logs[replica] = &replicaLog{
+			conflicts: make(map[string]uint64),
+			instances: make([]*pb.Instance, 0),
+		}
+	}
+}
+
+// Returns the instances at or after the specified slot of each replica's log, ordered
+// by replica PID and slot.
+func (l *Logs) tail(slots map[uint32]uint64) []*pb.Instance {
+	instances := make([]*pb.Instance, 0)
+	for _, pid := range l.replicas() {
+		rlog := l.logs[pid]
+		for slot := slots[pid]; slot < rlog.nextSlot(); slot++ {
+			if inst := rlog.get(slot); inst != nil {
+				instances = append(instances, inst)
+			}
+		}
+	}
+	return instances
+}
+
+// Returns true if the instance in the specified replica log and slot was executed and
+// truncated from the log.
+func (l *Logs) truncated(replica uint32, slot uint64) bool {
+	rlog, ok := l.logs[replica]
+	return ok && slot < rlog.offset
 }
 
 //===========================================================================
@@ -143,58 +338,140 @@ func (l *Logs) LastApplied(replica uint32) (idx uint64, err error) {
 	return next - 1, nil
 }
 
+// Sequence returns the maximum sequence number seen by the log.
+func (l *Logs) Sequence() uint64 {
+	return l.sequence
+}
+
+// NextSlots returns the next slot in each replica's log, keyed by replica PID.
+func (l *Logs) NextSlots() map[uint32]uint64 {
+	slots := make(map[uint32]uint64, len(l.logs))
+	for pid, rlog := range l.logs {
+		slots[pid] = rlog.nextSlot()
+	}
+	return slots
+}
+
+// Offsets returns the first slot of each replica's log that has not been truncated,
+// keyed by replica PID. The instances below the offset can no longer be fetched.
+func (l *Logs) Offsets() map[uint32]uint64 {
+	offsets := make(map[uint32]uint64, len(l.logs))
+	for pid, rlog := range l.logs {
+		offsets[pid] = rlog.offset
+	}
+	return offsets
+}
+
+// Executed returns the executed frontier for each replica's log, keyed by replica
+// PID. The frontier is the first slot in the log whose instance has not been executed,
+// so a frontier of zero means that no instances have been executed for that replica.
+func (l *Logs) Executed() map[uint32]uint64 {
+	frontier := make(map[uint32]uint64, len(l.logs))
+	for pid, rlog := range l.logs {
+		frontier[pid] = rlog.executed()
+	}
+	return frontier
+}
+
 // returns the next slot in the replica log.
 func (l *replicaLog) nextSlot() uint64 {
-	return uint64(len(l.instances))
+	return l.offset + uint64(len(l.instances))
+}
+
+// returns the instance in the slot or nil if the slot has been truncated or is empty.
+func (l *replicaLog) get(slot uint64) *pb.Instance {
+	if slot < l.offset || slot >= l.nextSlot() {
+		return nil
+	}
+	return l.instances[slot-l.offset]
+}
+
+// returns the first slot in the replica log that has not been executed, advancing the
+// cached frontier past any instances that have been executed since the last call.
+func (l *replicaLog) executed() uint64 {
+	for l.frontier < l.nextSlot() && l.get(l.frontier).Status == pb.Status_EXECUTED {
+		l.frontier++
+	}
+	return l.frontier
 }
 
 // use the conflicts map to locate the latest dependency by slot across each replica's
-// log and update the internal dependencies of the instance. Returns true if the
-// dependencies on the instance have changed.
+// log and update the internal dependencies of the instance. Every instance depends on
+// the latest membership change, and membership changes depend on the latest instance
+// of every replica's log, so that they are ordered with respect to every instance.
+// Returns true if the dependencies on the instance have changed.
 //
 // TODO: should this simply happen on insert/append to the log?
 func (l *Logs) updateDependencies(inst *pb.Instance) (changed bool) {
+	// Empty dependencies are unmarshaled as a nil map from remote peers.
+	if inst.Deps == nil {
+		inst.Deps = make(map[uint32]uint64)
+	}
+
 	// Ensure we have the latest dependency for all operations in the instance.
 	for _, op := range inst.Ops {
-
-		// Go through all replica logs to create the dependency map
-		for pid, rlog := range l.logs {
-
-			fmt.Println(rlog.conflicts)
-
-			// If the replica has a conflict with this key add the conflict slot to the deps
-			if slot, present := rlog.conflicts[op.Key]; present {
-
-				fmt.Println(inst.Deps)
-
-				// Check to see if the dependency has not changed
-				if curdep, hasdep := inst.Deps[pid]; hasdep && slot <= curdep {
-					// In this case the dependency is already stored or larger than the
-					// cached conflict so no change in the deps needs to occur.
+		if op.Type == pb.AccessType_RECONFIGURE {
+			for pid, rlog := range l.logs {
+				// The previous instance in the replica's own log is an implicit dependency
+				if pid == inst.Replica || rlog.nextSlot() == 0 {
 					continue
 				}
 
-				// Store the new depedency with the instance and mark changed
-				// info("DEPENDENCY CHANGED! key %s pid %d slot %d", op.Key, pid, slot)
-				changed = true
-				inst.Deps[pid] = slot
+				if slot := rlog.nextSlot() - 1; l.addDependency(inst, pid, slot) {
+					changed = true
+				}
+			}
+		}
 
-				// Check to ensure that the instances sequence is bigger than all
-				// conflicting instance sequences to ensure correct execution order.
-				if rlog.instances[slot].Seq >= inst.Seq {
-					inst.Seq = 1 + rlog.instances[slot].Seq
+		for _, key := range append(conflictKeys(op), membershipKey) {
+
+			// Go through all replica logs to create the dependency map
+			for pid, rlog := range l.logs {
+				// If the replica has a conflict with this key add the conflict slot to the deps
+				if slot, present := rlog.conflicts[key]; present && l.addDependency(inst, pid, slot) {
+					changed = true
 				}
 			}
 		}
 	}
 
-	// Ensure that our global sequence is monotonically increasing
+	// Ensure that our global sequence is monotonically increasing; this does not
+	// change the instance so it is not reported as a change in the dependencies.
+	l.observe(inst)
+	return changed
+}
+
+// Add the instance in the slot of the replica's log to the dependencies of the instance,
+// returning true if the dependencies have changed.
+func (l *Logs) addDependency(inst *pb.Instance, pid uint32, slot uint64) bool {
+	// Check to see if the dependency has not changed
+	if curdep, hasdep := inst.Deps[pid]; hasdep && slot <= curdep {
+		// In this case the dependency is already stored or larger than the
+		// cached conflict so no change in the deps needs to occur.
+		return false
+	}
+
+	// An instance does not depend on itself
+	if pid == inst.Replica && slot == inst.Slot {
+		return false
+	}
+
+	// Store the new depedency with the instance
+	inst.Deps[pid] = slot
+
+	// Check to ensure that the instances sequence is bigger than all
+	// conflicting instance sequences to ensure correct execution order.
+	if conflict := l.logs[pid].get(slot); conflict != nil && conflict.Seq >= inst.Seq {
+		inst.Seq = 1 + conflict.Seq
+	}
+	return true
+}
+
+// Ensure that the global sequence is at least the sequence of the instance.
+func (l *Logs) observe(inst *pb.Instance) {
 	if inst.Seq > l.sequence {
 		l.sequence = inst.Seq
-		changed = true
 	}
-
-	return changed
 }
 
 // Update the local conflict cache for the leader's replica log by associating all the
@@ -207,14 +484,16 @@ func (l *Logs) updateConflicts(inst *pb.Instance) {
 
 	// Update the set of keys across all operations in the instance
 	for _, op := range inst.Ops {
-		// If the key is in the conflicts map, but there is already a conflict slot
-		// greater than the slot of the instance, do not modify the conflicts log.
-		if slot, present := rlog.conflicts[op.Key]; present && slot >= inst.Slot {
-			continue
-		}
+		for _, key := range conflictKeys(op) {
+			// If the key is in the conflicts map, but there is already a conflict slot
+			// greater than the slot of the instance, do not modify the conflicts log.
+			if slot, present := rlog.conflicts[key]; present && slot >= inst.Slot {
+				continue
+			}
 
-		// Update the conflicts map if there was no conflict before or if a new conflict was added
-		rlog.conflicts[op.Key] = inst.Slot
+			// Update the conflicts map if there was no conflict before or if a new conflict was added
+			rlog.conflicts[key] = inst.Slot
+		}
 	}
 }
 
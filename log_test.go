@@ -110,6 +110,92 @@ var _ = Describe("Log", func() {
 			Ω(logs.Insert(inst)).Should(MatchError("there is already an instance in slot 0"))
 		})
 
+		It("should report the next slot and executed frontier of each replica", func() {
+			for i := 0; i < 3; i++ {
+				_, err := logs.Create(2, []*pb.Operation{{Type: pb.AccessType_WRITE, Key: "foo", Value: []byte("bar")}})
+				Ω(err).ShouldNot(HaveOccurred())
+			}
+
+			inst, err := logs.Get(2, 0)
+			Ω(err).ShouldNot(HaveOccurred())
+			inst.Status = pb.Status_EXECUTED
+
+			Ω(logs.Sequence()).Should(BeNumerically(">=", 3))
+			Ω(logs.NextSlots()).Should(HaveLen(5))
+			Ω(logs.NextSlots()).Should(HaveKeyWithValue(uint32(2), uint64(3)))
+			Ω(logs.NextSlots()).Should(HaveKeyWithValue(uint32(1), uint64(0)))
+			Ω(logs.Executed()).Should(HaveKeyWithValue(uint32(2), uint64(1)))
+			Ω(logs.Executed()).Should(HaveKeyWithValue(uint32(1), uint64(0)))
+		})
+
+		It("should update instances decided by the leader", func() {
+			op := &pb.Operation{Type: pb.AccessType_WRITE, Key: "foo", Value: []byte("bar")}
+
+			// A commit for the next slot appends the instance
+			inst, err := logs.Update(&pb.Instance{Replica: 3, Slot: 0, Seq: 4, Ops: []*pb.Operation{op}}, pb.Status_COMMITTED)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(inst.Status).Should(Equal(pb.Status_COMMITTED))
+			Ω(inst.Deps).ShouldNot(BeNil())
+			Ω(logs.Sequence()).Should(Equal(uint64(4)))
+
+			// An accept for a committed instance does not regress the status
+			inst, err = logs.Update(&pb.Instance{Replica: 3, Slot: 0, Seq: 2}, pb.Status_ACCEPTED)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(inst.Status).Should(Equal(pb.Status_COMMITTED))
+			Ω(inst.Seq).Should(Equal(uint64(4)))
+
+			// An instance created after the commit depends on it
+			inst, err = logs.Create(1, []*pb.Operation{op})
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(inst.Deps).Should(HaveKeyWithValue(uint32(3), uint64(0)))
+			Ω(inst.Seq).Should(Equal(uint64(5)))
+
+			// Cannot update an instance that would leave a gap in the log
+			_, err = logs.Update(&pb.Instance{Replica: 3, Slot: 4, Seq: 6}, pb.Status_COMMITTED)
+			Ω(err).Should(HaveOccurred())
+		})
+
+		It("should install the tail of a snapshot transfer", func() {
+			op := &pb.Operation{Type: pb.AccessType_WRITE, Key: "foo", Value: []byte("bar")}
+			_, err := logs.Create(1, []*pb.Operation{op})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			// The tail must start at the executed frontier of the snapshot
+			executed := map[uint32]uint64{2: 8}
+			err = logs.Install(executed, 12, []*pb.Instance{{Replica: 2, Slot: 9}})
+			Ω(err).Should(MatchError("cannot install instance in slot 9 when next slot is 8"))
+			Ω(logs.NextSlots()).Should(HaveKeyWithValue(uint32(1), uint64(1)))
+
+			tail := []*pb.Instance{
+				{Replica: 2, Slot: 8, Seq: 13, Status: pb.Status_EXECUTED, Ops: []*pb.Operation{op}},
+				{Replica: 2, Slot: 9, Seq: 14, Status: pb.Status_ACCEPTED},
+			}
+			Ω(logs.Install(executed, 12, tail)).Should(Succeed())
+			Ω(logs.NextSlots()).Should(HaveKeyWithValue(uint32(2), uint64(10)))
+			Ω(logs.Executed()).Should(HaveKeyWithValue(uint32(2), uint64(8)))
+			Ω(logs.Sequence()).Should(Equal(uint64(14)))
+
+			// Executed instances are committed to be executed after the snapshot
+			inst, err := logs.Get(2, 8)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(inst.Status).Should(Equal(pb.Status_COMMITTED))
+
+			_, err = logs.Get(2, 7)
+			Ω(err).Should(Equal(ErrTruncated))
+
+			// Local instances beyond the tail are kept so their slots are not reused
+			Ω(logs.NextSlots()).Should(HaveKeyWithValue(uint32(1), uint64(1)))
+			inst, err = logs.Get(1, 0)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(inst.Ops).Should(ConsistOf(op))
+
+			// New instances depend on the installed instances
+			inst, err = logs.Create(1, []*pb.Operation{op})
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(inst.Slot).Should(Equal(uint64(1)))
+			Ω(inst.Deps).Should(HaveKeyWithValue(uint32(2), uint64(8)))
+		})
+
 	})
 
 })
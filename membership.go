@@ -0,0 +1,363 @@
+package epaxos
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/bbengfort/epaxos/pb"
+	"github.com/bbengfort/x/peers"
+)
+
+// Actions of a RECONFIGURE operation that are specified by the key of the operation.
+const (
+	MembershipAdd    = "add"
+	MembershipRemove = "remove"
+)
+
+// Pseudo-key that RECONFIGURE operations conflict on; every instance depends on the
+// latest instance with a RECONFIGURE operation so that membership changes are executed
+// at the same point in the execution order on every replica.
+const membershipKey = "\x00membership"
+
+// ReconfigureOperation creates a RECONFIGURE operation that adds the peer to or
+// removes the peer from the replicas, depending on the action. Peers are removed by
+// name, so only the name of the peer is required to remove it.
+func ReconfigureOperation(action string, peer peers.Peer) (*pb.Operation, error) {
+	if action != MembershipAdd && action != MembershipRemove {
+		return nil, fmt.Errorf("unknown membership action %q", action)
+	}
+
+	value, err := json.Marshal(peer)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Operation{Type: pb.AccessType_RECONFIGURE, Key: action, Value: value}, nil
+}
+
+//===========================================================================
+// Membership Changes
+//===========================================================================
+
+// Execute the RECONFIGURE operation, changing the replicas that participate in
+// consensus. The operation is applied in execution order, so every replica changes its
+// membership after executing the same instances. Instances proposed after the change
+// are tagged with the next epoch and require a quorum of the new replicas; instances
+// that were proposed before the change require a quorum of the epoch they were proposed
+// in and of every epoch since, see vote. Adding a replica that is already a member with
+// the same definition is not an error, so that a new replica can execute the change
+// after it receives the state of the cluster. The replica that led the change transfers
+// its state to an added replica once the change has been executed.
+func (r *Replica) reconfigure(op *pb.Operation, leader uint32) *result {
+	res := &result{key: op.Key}
+	if err := r.changeMembership(op, leader); err != nil {
+		res.err = err.Error()
+	}
+	return &result{err: res.err, results: []*result{res}}
+}
+
+// Change the membership as specified by the RECONFIGURE operation.
+func (r *Replica) changeMembership(op *pb.Operation, leader uint32) error {
+	peer := peers.Peer{}
+	if err := json.Unmarshal(op.Value, &peer); err != nil {
+		return fmt.Errorf("could not parse replica: %s", err)
+	}
+
+	members := make([]peers.Peer, 0, len(r.config.Peers)+1)
+	switch op.Key {
+	case MembershipAdd:
+		if peer.PID == 0 || peer.Name == "" {
+			return errors.New("replicas require a PID and a name")
+		}
+
+		for _, member := range r.config.Peers {
+			if samePeer(member, peer) {
+				return nil
+			}
+
+			if member.PID == peer.PID || member.Name == peer.Name {
+				return fmt.Errorf("replica %q (PID %d) conflicts with member %q (PID %d)", peer.Name, peer.PID, member.Name, member.PID)
+			}
+		}
+
+		// Logs are not removed with their replica, so PIDs cannot be reused
+		if _, ok := r.logs.logs[peer.PID]; ok {
+			return fmt.Errorf("PID %d has been used by a removed replica", peer.PID)
+		}
+
+		members = append(append(members, r.config.Peers...), peer)
+	case MembershipRemove:
+		for _, member := range r.config.Peers {
+			if member.Name != peer.Name {
+				members = append(members, member)
+			}
+		}
+
+		if len(members) == len(r.config.Peers) {
+			return fmt.Errorf("replica %q is not a member", peer.Name)
+		}
+
+		if len(members) == 0 {
+			return errors.New("cannot remove the last replica")
+		}
+	default:
+		return fmt.Errorf("unknown membership action %q", op.Key)
+	}
+
+	r.setMembership(r.epoch+1, members)
+	if op.Key == MembershipAdd && r.PID == leader {
+		r.joining = append(r.joining, peer.PID)
+	}
+	return nil
+}
+
+// Set the replicas of the epoch, updating the logs, the remotes, the thrifty peers,
+// and the quorum size. Remotes of removed replicas are closed, and if this replica has
+// been removed, it no longer accepts proposals and disconnects from every replica once
+// the instances that it leads have been committed.
+func (r *Replica) setMembership(epoch uint64, members []peers.Peer) {
+	r.members.Lock()
+	r.config.Peers = members
+	r.rosters[epoch] = members
+	r.members.Unlock()
+
+	r.epoch = epoch
+	r.quorum = r.config.GetQuorum()
+	r.quorums[epoch] = r.quorum
+
+	_, member := r.config.lookupPeer(r.Name)
+	for _, peer := range members {
+		r.logs.addLog(peer.PID)
+	}
+
+	// Networking is stubbed out when a trace is replayed
+	if r.events == nil {
+		r.log.Status("membership changed to %d replicas in epoch %d", len(members), epoch)
+		return
+	}
+
+	// The other replicas cannot execute instances that depend on the instances led by
+	// this replica until they are committed, so a removed replica stays connected to
+	// the replicas of the previous epoch until its pending instances are committed.
+	if !member && r.leading() {
+		r.thrifty = nil
+		r.log.Status("replica has been removed from the quorum in epoch %d, committing its pending instances", epoch)
+		return
+	}
+
+	remotes := make(Remotes, len(members))
+	for _, peer := range members {
+		if !member || peer.PID == r.PID {
+			continue
+		}
+
+		if remote, ok := r.remotes[peer.PID]; ok && samePeer(remote.Peer, peer) {
+			remotes[peer.PID] = remote
+			delete(r.remotes, peer.PID)
+			continue
+		}
+
+		remote, err := NewRemote(peer, r)
+		if err != nil {
+			r.log.Warn("could not create remote for %s: %s", peer.Name, err)
+			continue
+		}
+
+		if err = remote.Connect(); err != nil {
+			r.log.Warn("could not connect to %s: %s", peer.Name, err)
+			continue
+		}
+		remotes[peer.PID] = remote
+	}
+
+	// Close the remotes of removed replicas without blocking the event loop
+	for _, remote := range r.remotes {
+		go remote.Close()
+	}
+
+	r.remotes = remotes
+	r.thrifty = r.config.GetThrifty()
+
+	if !member {
+		r.log.Status("replica has been removed from the quorum in epoch %d", epoch)
+		return
+	}
+	r.log.Status("membership changed to %d replicas in epoch %d", len(members), epoch)
+}
+
+// Disconnects a removed replica from every replica once the instances that it leads
+// have been committed.
+func (r *Replica) disconnect() {
+	if len(r.remotes) == 0 || r.member() || r.leading() {
+		return
+	}
+
+	for _, remote := range r.remotes {
+		go remote.Close()
+	}
+	r.remotes = make(Remotes)
+	r.log.Status("committed every instance led by this replica, disconnected from the quorum")
+}
+
+// Returns true if this replica leads instances that have not been committed.
+func (r *Replica) leading() bool {
+	rlog, ok := r.logs.logs[r.PID]
+	if !ok {
+		return false
+	}
+
+	for slot := rlog.executed(); slot < rlog.nextSlot(); slot++ {
+		if inst := rlog.get(slot); inst != nil && inst.Status < pb.Status_COMMITTED {
+			return true
+		}
+	}
+	return false
+}
+
+// Record the vote of the sender for the current phase of an instance led by this
+// replica, returning true once the replicas that voted are a quorum of the epoch the
+// instance was proposed in and of every epoch since. Replicas execute membership
+// changes at different times, so instances of consecutive epochs are voted on
+// concurrently; requiring a joint quorum ensures that the quorums of any two instances
+// intersect. A vote only counts towards the quorums of the epochs that the sender was
+// a member of. The votes must be reset with resetVotes when the phase changes.
+func (r *Replica) vote(inst *pb.Instance, sender string) bool {
+	votes, ok := r.votes[inst.Slot]
+	if !ok {
+		votes = map[string]bool{r.Name: true}
+		r.votes[inst.Slot] = votes
+	}
+	votes[sender] = true
+
+	for epoch := inst.Epoch; epoch <= r.epoch; epoch++ {
+		roster, ok := r.rosters[epoch]
+		if !ok {
+			// Epochs before an installed snapshot are not known
+			continue
+		}
+
+		var count uint32
+		for _, peer := range roster {
+			if votes[peer.Name] {
+				count++
+			}
+		}
+
+		if count < r.quorumOf(epoch) {
+			return false
+		}
+	}
+	return true
+}
+
+// Reset the votes for an instance led by this replica when its phase changes.
+func (r *Replica) resetVotes(inst *pb.Instance) {
+	delete(r.votes, inst.Slot)
+}
+
+// Returns the number of replicas required for a quorum of the epoch.
+func (r *Replica) quorumOf(epoch uint64) uint32 {
+	if quorum, ok := r.quorums[epoch]; ok {
+		return quorum
+	}
+	return r.quorum
+}
+
+// Returns the replica with the name if it was a member of the epoch. The replicas of
+// epochs before an installed snapshot are not known, so the current members are used
+// instead. Must be called with the members lock held.
+func (r *Replica) memberOf(epoch uint64, name string) (peers.Peer, bool) {
+	roster, ok := r.rosters[epoch]
+	if !ok {
+		roster = r.config.Peers
+	}
+
+	for _, peer := range roster {
+		if peer.Name == name {
+			return peer, true
+		}
+	}
+	return peers.Peer{}, false
+}
+
+// Returns the replica with the name if it was a member of any known epoch. Must be
+// called with the members lock held.
+func (r *Replica) formerMember(name string) (peers.Peer, bool) {
+	for _, roster := range r.rosters {
+		for _, peer := range roster {
+			if peer.Name == name {
+				return peer, true
+			}
+		}
+	}
+	return peers.Peer{}, false
+}
+
+// Returns the replica with the name if it was a member of the current epoch or of any
+// epoch since the oldest epoch with instances that have not been committed. Removed
+// replicas must be able to open consensus streams until the instances that they led
+// as members are committed. Must be called with the members lock held.
+func (r *Replica) pendingMember(name string) (peers.Peer, bool) {
+	if peer, ok := r.config.lookupPeer(name); ok {
+		return peer, true
+	}
+
+	for epoch, roster := range r.rosters {
+		if epoch < r.pending {
+			continue
+		}
+
+		for _, peer := range roster {
+			if peer.Name == name {
+				return peer, true
+			}
+		}
+	}
+	return peers.Peer{}, false
+}
+
+// Advance the pending epoch to the oldest epoch of the instances in any log that have
+// not been committed, or to the current epoch if every instance has been committed.
+func (r *Replica) updatePending() {
+	if r.pending == r.epoch {
+		return
+	}
+
+	oldest := r.epoch
+	for _, rlog := range r.logs.logs {
+		for slot := rlog.executed(); slot < rlog.nextSlot(); slot++ {
+			if inst := rlog.get(slot); inst != nil && inst.Status < pb.Status_COMMITTED && inst.Epoch < oldest {
+				oldest = inst.Epoch
+			}
+		}
+	}
+
+	if oldest > r.pending {
+		r.members.Lock()
+		r.pending = oldest
+		r.members.Unlock()
+	}
+}
+
+// Returns true if the peers have the same identity and address.
+func samePeer(a, b peers.Peer) bool {
+	return a.PID == b.PID && a.Name == b.Name && a.Hostname == b.Hostname &&
+		a.IPAddr == b.IPAddr && a.Domain == b.Domain && a.Port == b.Port
+}
+
+// Returns the PIDs of the replicas of the current epoch in sorted order.
+func (r *Replica) memberPIDs() []uint32 {
+	pids := make([]uint32, 0, len(r.config.Peers))
+	for _, peer := range r.config.Peers {
+		pids = append(pids, peer.PID)
+	}
+	sort.Slice(pids, func(i, j int) bool { return pids[i] < pids[j] })
+	return pids
+}
+
+// Returns true if the replica is a member of the current epoch.
+func (r *Replica) member() bool {
+	_, ok := r.config.lookupPeer(r.Name)
+	return ok
+}
@@ -0,0 +1,129 @@
+package epaxos_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/bbengfort/epaxos"
+	"github.com/bbengfort/epaxos/pb"
+	"github.com/bbengfort/x/peers"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var _ = Describe("Membership", func() {
+
+	It("should add and remove replicas through consensus", func() {
+		members := makeNetwork(53264, 4)
+		network, delta := members[:3], members[3]
+		for _, peer := range network {
+			runReplica(peer.Name, network, withAdmin)
+		}
+
+		// Delta is started with the new membership before it is added
+		runReplica(delta.Name, members, withAdmin)
+
+		client, err := NewClient("alpha", &Config{Timeout: "2s", Token: adminToken, Peers: network[:1]})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(client.Put("foo", []byte("bar"), false)).Should(Succeed())
+		Ω(client.AddPeer(delta)).Should(Succeed())
+
+		// Adding a replica twice is a no-op, but PIDs and names must be unique
+		Ω(client.AddPeer(delta)).Should(Succeed())
+		err = client.AddPeer(peers.Peer{PID: 4, Name: "echo"})
+		Ω(IsRejected(err)).Should(BeTrue())
+
+		// Delta installs a snapshot that includes the change and executes the rest itself
+		dclient, err := NewClient("delta", &Config{Timeout: "2s", Token: adminToken, Peers: []peers.Peer{delta}})
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Eventually(func() pb.Status {
+			inst, err := dclient.Fetch(1, 3)
+			if err != nil {
+				return pb.Status_INITIAL
+			}
+			return inst.Status
+		}, "2s").Should(Equal(pb.Status_EXECUTED))
+
+		Ω(dclient.Get("foo")).Should(Equal([]byte("bar")))
+
+		status, err := dclient.Status()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(status.Epoch).Should(Equal(uint64(1)))
+		Ω(status.Quorum).Should(Equal(uint32(3)))
+
+		// Removed replicas no longer accept proposals
+		Ω(client.RemovePeer("charlie")).Should(Succeed())
+
+		cclient, err := NewClient("charlie", &Config{Timeout: "2s", Token: adminToken, Peers: network[2:]})
+		Ω(err).ShouldNot(HaveOccurred())
+		cclient.SetRetryPolicy(&RetryPolicy{MaxAttempts: 1})
+
+		Eventually(func() error {
+			return cclient.Put("foo", []byte("charlie"), false)
+		}, "2s").Should(MatchError(ContainSubstring(ErrRemoved.Error())))
+
+		Ω(client.Put("foo", []byte("baz"), false)).Should(Succeed())
+		Ω(dclient.Get("foo")).Should(Equal([]byte("baz")))
+	})
+
+	It("should accept the instances that removed replicas led in earlier epochs", func() {
+		network := makeNetwork(60264, 3)
+		for _, peer := range network {
+			runReplica(peer.Name, network, withAdmin)
+		}
+
+		client, err := NewClient("alpha", &Config{Timeout: "2s", Token: adminToken, Peers: network[:1]})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(client.RemovePeer("charlie")).Should(Succeed())
+
+		bclient, err := NewClient("bravo", &Config{Timeout: "2s", Token: adminToken, Peers: network[1:2]})
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Eventually(func() uint64 {
+			status, err := bclient.Status()
+			if err != nil {
+				return 0
+			}
+			return status.Epoch
+		}, "2s").Should(Equal(uint64(1)))
+
+		// Send messages to bravo as charlie, which was removed in epoch 1
+		conn, err := grpc.Dial(fmt.Sprintf("127.0.0.1:%d", network[1].Port), grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(2*time.Second))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer conn.Close()
+
+		send := func(req *pb.PeerRequest) (*pb.PeerReply, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			stream, err := pb.NewEpaxosClient(conn).Consensus(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			if err = stream.Send(req); err != nil {
+				return nil, err
+			}
+			return stream.Recv()
+		}
+
+		_, err = send(pb.WrapBeaconRequest("charlie", &pb.BeaconRequest{}))
+		Ω(err).ShouldNot(HaveOccurred())
+
+		inst := &pb.Instance{Replica: network[2].PID, Slot: 0, Epoch: 0, Seq: 1, Deps: map[uint32]uint64{}}
+		rep, err := send(pb.WrapPreacceptRequest("charlie", &pb.PreacceptRequest{Inst: inst}))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(rep.Success).Should(BeTrue())
+
+		inst = &pb.Instance{Replica: network[2].PID, Slot: 1, Epoch: 1, Seq: 2, Deps: map[uint32]uint64{}}
+		_, err = send(pb.WrapPreacceptRequest("charlie", &pb.PreacceptRequest{Inst: inst}))
+		Ω(status.Code(err)).Should(Equal(codes.PermissionDenied))
+		Ω(err.Error()).Should(ContainSubstring("not in the configuration of epoch 1"))
+	})
+})
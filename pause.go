@@ -0,0 +1,101 @@
+package epaxos
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bbengfort/epaxos/pb"
+)
+
+// Protocol phases that can be delayed by a PAUSE operation.
+const (
+	PhasePreaccept = "preaccept"
+	PhaseAccept    = "accept"
+	PhaseCommit    = "commit"
+	PhaseExecute   = "execute"
+)
+
+// MaxPauseDuration is the longest that a single PAUSE operation can delay a phase.
+const MaxPauseDuration = time.Minute
+
+// pause delays a protocol phase of the replica until the pause is resumed.
+type pause struct {
+	generation uint64         // the generation of the PAUSE operation that delayed the phase
+	deferred   []func() error // the delayed steps of the phase in the order they occurred
+}
+
+// Pause the phase of the protocol named by the key of the PAUSE operation for the
+// duration in its value, e.g. "accept" for "2s", up to MaxPauseDuration. The operation
+// is not replicated: only the replica it is proposed to is paused, so that slow paths
+// and delayed execution can be triggered on a single replica. Steps of the phase that
+// occur during the pause are delayed until the pause ends and then run in order; if the
+// phase is paused again before the pause ends, the pause is extended.
+func (r *Replica) pause(op *pb.Operation) error {
+	phase := strings.ToLower(op.Key)
+	switch phase {
+	case PhasePreaccept, PhaseAccept, PhaseCommit, PhaseExecute:
+	default:
+		return fmt.Errorf("cannot pause unknown phase %q", op.Key)
+	}
+
+	duration, err := time.ParseDuration(string(op.Value))
+	if err != nil {
+		return fmt.Errorf("could not parse pause duration: %s", err)
+	}
+
+	if duration <= 0 || duration > MaxPauseDuration {
+		return fmt.Errorf("pause duration must be positive and at most %s", MaxPauseDuration)
+	}
+
+	// Number every pause so that a pause that is issued again with the same operation
+	// does not resume when the timer of the pause it replaced ends.
+	r.npauses++
+	if p, ok := r.pauses[phase]; ok {
+		p.generation = r.npauses
+	} else {
+		r.pauses[phase] = &pause{generation: r.npauses}
+	}
+
+	// Resume the phase through the event loop so the delayed steps run in order
+	resume := &pb.Resume{Phase: phase, Generation: r.npauses}
+	time.AfterFunc(duration, func() {
+		r.Dispatch(&event{etype: ResumeEvent, value: resume})
+	})
+
+	r.log.Status("pausing %s phase for %s", phase, duration)
+	return nil
+}
+
+// Delay the step of the phase if the phase is paused, returning true if it was delayed.
+func (r *Replica) paused(phase string, step func() error) bool {
+	if p, ok := r.pauses[phase]; ok {
+		p.deferred = append(p.deferred, step)
+		return true
+	}
+	return false
+}
+
+// Resume the phase paused by the generation of the event and run its delayed steps,
+// unless the phase was paused again since that generation.
+func (r *Replica) onResume(e Event) (err error) {
+	resume := e.Value().(*pb.Resume)
+	phase := resume.Phase
+	p, ok := r.pauses[phase]
+	if !ok || p.generation != resume.Generation {
+		return nil
+	}
+
+	delete(r.pauses, phase)
+	r.log.Status("resuming %s phase with %d delayed steps", phase, len(p.deferred))
+
+	for _, step := range p.deferred {
+		if err = step(); err != nil {
+			return err
+		}
+	}
+
+	// Execute any instances that were committed while the phase was paused
+	r.execute()
+	return nil
+}
@@ -0,0 +1,85 @@
+package epaxos_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/bbengfort/epaxos"
+	"github.com/bbengfort/epaxos/pb"
+	"github.com/bbengfort/x/peers"
+)
+
+var _ = Describe("Pause", func() {
+
+	var (
+		port    = 50264
+		network []peers.Peer
+		client  *Client
+	)
+
+	BeforeEach(func() {
+		network = runNetwork(port, withAdmin, func(conf *Config) {
+			conf.Clients = append(conf.Clients, ClientPolicy{Identity: "tenant", Token: "t3nant"})
+		})
+		port += len(network)
+
+		var err error
+		client, err = NewClient("alpha", &Config{Timeout: "2s", Token: adminToken, Peers: network})
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("should delay the named phase for the duration", func() {
+		for _, phase := range []string{PhaseCommit, PhaseExecute} {
+			_, err := client.Propose(pb.AccessType_PAUSE, phase, []byte("300ms"))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			start := time.Now()
+			Ω(client.Put("foo", []byte(phase), false)).Should(Succeed())
+			Ω(time.Since(start)).Should(BeNumerically(">=", 250*time.Millisecond))
+		}
+
+		// Once the pause ends the phase is no longer delayed
+		start := time.Now()
+		val, err := client.Get("foo")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(val).Should(Equal([]byte(PhaseExecute)))
+		Ω(time.Since(start)).Should(BeNumerically("<", 250*time.Millisecond))
+	})
+
+	It("should not resume early when the same pause is issued again", func() {
+		_, err := client.Propose(pb.AccessType_PAUSE, PhaseCommit, []byte("300ms"))
+		Ω(err).ShouldNot(HaveOccurred())
+		time.Sleep(200 * time.Millisecond)
+
+		// The timer of the first pause ends before the second pause does
+		_, err = client.Propose(pb.AccessType_PAUSE, PhaseCommit, []byte("300ms"))
+		Ω(err).ShouldNot(HaveOccurred())
+
+		start := time.Now()
+		Ω(client.Put("foo", []byte("bar"), false)).Should(Succeed())
+		Ω(time.Since(start)).Should(BeNumerically(">=", 250*time.Millisecond))
+	})
+
+	It("should reject unknown phases and durations", func() {
+		_, err := client.Propose(pb.AccessType_PAUSE, "propose", []byte("1s"))
+		Ω(IsRejected(err)).Should(BeTrue())
+
+		for _, duration := range []string{"forever", "0s", "-1s", "2m"} {
+			_, err = client.Propose(pb.AccessType_PAUSE, PhaseAccept, []byte(duration))
+			Ω(IsRejected(err)).Should(BeTrue())
+		}
+	})
+
+	It("should only pause replicas for clients whose policy grants admin access", func() {
+		// The tenant policy does not restrict the access types or keys of the client
+		tenant, err := NewClient("alpha", &Config{Timeout: "2s", Token: "t3nant", Peers: network})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(tenant.Put("foo", []byte("bar"), false)).Should(Succeed())
+
+		_, err = tenant.Propose(pb.AccessType_PAUSE, PhaseExecute, []byte("1s"))
+		Ω(IsRejected(err)).Should(BeTrue())
+		Ω(err).Should(MatchError(ContainSubstring(`client "tenant" is not allowed to administer the cluster`)))
+	})
+})
@@ -0,0 +1,335 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: admin.proto
+
+package pb
+
+import (
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+// Request the current status of the replica and its view of the 2D log.
+type StatusRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StatusRequest) Reset()         { *m = StatusRequest{} }
+func (m *StatusRequest) String() string { return proto.CompactTextString(m) }
+func (*StatusRequest) ProtoMessage()    {}
+func (*StatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{0}
+}
+
+func (m *StatusRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StatusRequest.Unmarshal(m, b)
+}
+func (m *StatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StatusRequest.Marshal(b, m, deterministic)
+}
+func (m *StatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StatusRequest.Merge(m, src)
+}
+func (m *StatusRequest) XXX_Size() int {
+	return xxx_messageInfo_StatusRequest.Size(m)
+}
+func (m *StatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StatusRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StatusRequest proto.InternalMessageInfo
+
+// The replica's current view of the quorum and of each replica's log. Slots are
+// reported as the next slot (e.g. the number of instances) for each replica, and the
+// executed frontier is the first slot in each log that has not yet been executed.
+type StatusReply struct {
+	Pid                  uint32            `protobuf:"varint,1,opt,name=pid,proto3" json:"pid,omitempty"`
+	Name                 string            `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Quorum               uint32            `protobuf:"varint,3,opt,name=quorum,proto3" json:"quorum,omitempty"`
+	Thrifty              []uint32          `protobuf:"varint,4,rep,packed,name=thrifty,proto3" json:"thrifty,omitempty"`
+	Sequence             uint64            `protobuf:"varint,5,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Slots                map[uint32]uint64 `protobuf:"bytes,6,rep,name=slots,proto3" json:"slots,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	Executed             map[uint32]uint64 `protobuf:"bytes,7,rep,name=executed,proto3" json:"executed,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	Epoch                uint64            `protobuf:"varint,8,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	Members              []uint32          `protobuf:"varint,9,rep,packed,name=members,proto3" json:"members,omitempty"`
+	Offsets              map[uint32]uint64 `protobuf:"bytes,10,rep,name=offsets,proto3" json:"offsets,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *StatusReply) Reset()         { *m = StatusReply{} }
+func (m *StatusReply) String() string { return proto.CompactTextString(m) }
+func (*StatusReply) ProtoMessage()    {}
+func (*StatusReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{1}
+}
+
+func (m *StatusReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StatusReply.Unmarshal(m, b)
+}
+func (m *StatusReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StatusReply.Marshal(b, m, deterministic)
+}
+func (m *StatusReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StatusReply.Merge(m, src)
+}
+func (m *StatusReply) XXX_Size() int {
+	return xxx_messageInfo_StatusReply.Size(m)
+}
+func (m *StatusReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_StatusReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StatusReply proto.InternalMessageInfo
+
+func (m *StatusReply) GetPid() uint32 {
+	if m != nil {
+		return m.Pid
+	}
+	return 0
+}
+
+func (m *StatusReply) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *StatusReply) GetQuorum() uint32 {
+	if m != nil {
+		return m.Quorum
+	}
+	return 0
+}
+
+func (m *StatusReply) GetThrifty() []uint32 {
+	if m != nil {
+		return m.Thrifty
+	}
+	return nil
+}
+
+func (m *StatusReply) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *StatusReply) GetSlots() map[uint32]uint64 {
+	if m != nil {
+		return m.Slots
+	}
+	return nil
+}
+
+func (m *StatusReply) GetExecuted() map[uint32]uint64 {
+	if m != nil {
+		return m.Executed
+	}
+	return nil
+}
+
+func (m *StatusReply) GetEpoch() uint64 {
+	if m != nil {
+		return m.Epoch
+	}
+	return 0
+}
+
+func (m *StatusReply) GetMembers() []uint32 {
+	if m != nil {
+		return m.Members
+	}
+	return nil
+}
+
+func (m *StatusReply) GetOffsets() map[uint32]uint64 {
+	if m != nil {
+		return m.Offsets
+	}
+	return nil
+}
+
+// Request a single instance from the log by replica and slot.
+type FetchRequest struct {
+	Replica              uint32   `protobuf:"varint,1,opt,name=replica,proto3" json:"replica,omitempty"`
+	Slot                 uint64   `protobuf:"varint,2,opt,name=slot,proto3" json:"slot,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FetchRequest) Reset()         { *m = FetchRequest{} }
+func (m *FetchRequest) String() string { return proto.CompactTextString(m) }
+func (*FetchRequest) ProtoMessage()    {}
+func (*FetchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{2}
+}
+
+func (m *FetchRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FetchRequest.Unmarshal(m, b)
+}
+func (m *FetchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FetchRequest.Marshal(b, m, deterministic)
+}
+func (m *FetchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FetchRequest.Merge(m, src)
+}
+func (m *FetchRequest) XXX_Size() int {
+	return xxx_messageInfo_FetchRequest.Size(m)
+}
+func (m *FetchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FetchRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FetchRequest proto.InternalMessageInfo
+
+func (m *FetchRequest) GetReplica() uint32 {
+	if m != nil {
+		return m.Replica
+	}
+	return 0
+}
+
+func (m *FetchRequest) GetSlot() uint64 {
+	if m != nil {
+		return m.Slot
+	}
+	return 0
+}
+
+// Request a stream of instances whenever they change state on the replica.
+type WatchRequest struct {
+	Replicas             []uint32 `protobuf:"varint,1,rep,packed,name=replicas,proto3" json:"replicas,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchRequest) Reset()         { *m = WatchRequest{} }
+func (m *WatchRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchRequest) ProtoMessage()    {}
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{3}
+}
+
+func (m *WatchRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchRequest.Unmarshal(m, b)
+}
+func (m *WatchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchRequest.Marshal(b, m, deterministic)
+}
+func (m *WatchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchRequest.Merge(m, src)
+}
+func (m *WatchRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchRequest.Size(m)
+}
+func (m *WatchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchRequest proto.InternalMessageInfo
+
+func (m *WatchRequest) GetReplicas() []uint32 {
+	if m != nil {
+		return m.Replicas
+	}
+	return nil
+}
+
+// The dependency graph reachable from an instance as a Graphviz DOT document.
+type GraphReply struct {
+	Dot                  string   `protobuf:"bytes,1,opt,name=dot,proto3" json:"dot,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GraphReply) Reset()         { *m = GraphReply{} }
+func (m *GraphReply) String() string { return proto.CompactTextString(m) }
+func (*GraphReply) ProtoMessage()    {}
+func (*GraphReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_73a7fc70dcc2027c, []int{4}
+}
+
+func (m *GraphReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GraphReply.Unmarshal(m, b)
+}
+func (m *GraphReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GraphReply.Marshal(b, m, deterministic)
+}
+func (m *GraphReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GraphReply.Merge(m, src)
+}
+func (m *GraphReply) XXX_Size() int {
+	return xxx_messageInfo_GraphReply.Size(m)
+}
+func (m *GraphReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_GraphReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GraphReply proto.InternalMessageInfo
+
+func (m *GraphReply) GetDot() string {
+	if m != nil {
+		return m.Dot
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*StatusRequest)(nil), "pb.StatusRequest")
+	proto.RegisterType((*StatusReply)(nil), "pb.StatusReply")
+	proto.RegisterMapType((map[uint32]uint64)(nil), "pb.StatusReply.ExecutedEntry")
+	proto.RegisterMapType((map[uint32]uint64)(nil), "pb.StatusReply.OffsetsEntry")
+	proto.RegisterMapType((map[uint32]uint64)(nil), "pb.StatusReply.SlotsEntry")
+	proto.RegisterType((*FetchRequest)(nil), "pb.FetchRequest")
+	proto.RegisterType((*WatchRequest)(nil), "pb.WatchRequest")
+	proto.RegisterType((*GraphReply)(nil), "pb.GraphReply")
+}
+
+func init() { proto.RegisterFile("admin.proto", fileDescriptor_73a7fc70dcc2027c) }
+
+var fileDescriptor_73a7fc70dcc2027c = []byte{
+	// 367 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x52, 0x4d, 0x6b, 0xe3, 0x30,
+	0x14, 0xc4, 0xb1, 0x93, 0x38, 0x2f, 0x31, 0xbb, 0x88, 0x65, 0x11, 0x66, 0xb7, 0x18, 0x9f, 0x4c,
+	0x0f, 0xa6, 0xb4, 0x50, 0xd2, 0xb4, 0xd7, 0xb4, 0xc7, 0x82, 0x72, 0xe8, 0xd9, 0x1f, 0x0a, 0x36,
+	0xf1, 0x87, 0x62, 0xc9, 0xa5, 0xfe, 0x09, 0xfd, 0xd7, 0x45, 0x92, 0xed, 0xa6, 0xb9, 0xe5, 0xf6,
+	0xc6, 0x6f, 0xc6, 0x33, 0x8c, 0x1e, 0x2c, 0xa3, 0xb4, 0xcc, 0xab, 0x90, 0x35, 0xb5, 0xa8, 0xd1,
+	0x84, 0xc5, 0xfe, 0x2f, 0x70, 0x76, 0x22, 0x12, 0x2d, 0x27, 0xf4, 0xd8, 0x52, 0x2e, 0xfc, 0x4f,
+	0x0b, 0x96, 0xc3, 0x17, 0x56, 0x74, 0xe8, 0x37, 0x98, 0x2c, 0x4f, 0xb1, 0xe1, 0x19, 0x81, 0x43,
+	0xe4, 0x88, 0x10, 0x58, 0x55, 0x54, 0x52, 0x3c, 0xf1, 0x8c, 0x60, 0x41, 0xd4, 0x8c, 0xfe, 0xc2,
+	0xec, 0xd8, 0xd6, 0x4d, 0x5b, 0x62, 0x53, 0x11, 0x7b, 0x84, 0x30, 0xcc, 0x45, 0xd6, 0xe4, 0x7b,
+	0xd1, 0x61, 0xcb, 0x33, 0x03, 0x87, 0x0c, 0x10, 0xb9, 0x60, 0x73, 0x69, 0x59, 0x25, 0x14, 0x4f,
+	0x3d, 0x23, 0xb0, 0xc8, 0x88, 0xd1, 0x0d, 0x4c, 0x79, 0x51, 0x0b, 0x8e, 0x67, 0x9e, 0x19, 0x2c,
+	0x6f, 0xdd, 0x90, 0xc5, 0xe1, 0x49, 0xa6, 0x70, 0x27, 0x97, 0xdb, 0x4a, 0x34, 0x1d, 0xd1, 0x44,
+	0xf4, 0x00, 0x36, 0xfd, 0xa0, 0x49, 0x2b, 0x68, 0x8a, 0xe7, 0x4a, 0xf4, 0xff, 0x5c, 0xb4, 0xed,
+	0xf7, 0x5a, 0x37, 0xd2, 0xd1, 0x1f, 0x98, 0x52, 0x56, 0x27, 0x19, 0xb6, 0x55, 0x0a, 0x0d, 0x64,
+	0xf0, 0x92, 0x96, 0x31, 0x6d, 0x38, 0x5e, 0xe8, 0xe0, 0x3d, 0x44, 0xf7, 0x30, 0xaf, 0xf7, 0x7b,
+	0x4e, 0x05, 0xc7, 0xa0, 0x9c, 0xfe, 0x9d, 0x3b, 0xbd, 0xea, 0xb5, 0x36, 0x1a, 0xc8, 0xee, 0x1a,
+	0xe0, 0x3b, 0xb7, 0xac, 0xf5, 0x40, 0xbb, 0xa1, 0xd6, 0x03, 0xed, 0x64, 0x8e, 0xf7, 0xa8, 0x68,
+	0x75, 0xaf, 0x16, 0xd1, 0x60, 0x33, 0x59, 0x1b, 0xee, 0x23, 0x38, 0x3f, 0xc2, 0x5f, 0x24, 0xde,
+	0xc0, 0xea, 0x34, 0xcf, 0x25, 0x5a, 0xff, 0x09, 0x56, 0xcf, 0x54, 0x24, 0x59, 0x7f, 0x1b, 0xb2,
+	0x94, 0x86, 0xb2, 0x22, 0x4f, 0xa2, 0x5e, 0x3f, 0x40, 0x79, 0x13, 0xf2, 0x21, 0xfa, 0x5f, 0xa8,
+	0xd9, 0xbf, 0x86, 0xd5, 0x5b, 0x74, 0xa2, 0x76, 0xc1, 0xee, 0xe9, 0x1c, 0x1b, 0xaa, 0xd3, 0x11,
+	0xfb, 0x57, 0x00, 0x2f, 0x4d, 0xc4, 0xb2, 0xf1, 0xe6, 0xd2, 0x5a, 0x28, 0x8f, 0x05, 0x91, 0x63,
+	0x3c, 0x53, 0x17, 0x7b, 0xf7, 0x35, 0x00, 0x8e, 0x43, 0xd6, 0x05, 0xc0, 0x02, 0x00, 0x00,
+}
@@ -21,11 +21,13 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
 type ProposeRequest struct {
-	Identity             string     `protobuf:"bytes,1,opt,name=identity,proto3" json:"identity,omitempty"`
-	Op                   *Operation `protobuf:"bytes,2,opt,name=op,proto3" json:"op,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
-	XXX_unrecognized     []byte     `json:"-"`
-	XXX_sizecache        int32      `json:"-"`
+	Identity             string       `protobuf:"bytes,1,opt,name=identity,proto3" json:"identity,omitempty"`
+	Op                   *Operation   `protobuf:"bytes,2,opt,name=op,proto3" json:"op,omitempty"`
+	Sequence             uint64       `protobuf:"varint,3,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Txn                  []*Operation `protobuf:"bytes,4,rep,name=txn,proto3" json:"txn,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
 }
 
 func (m *ProposeRequest) Reset()         { *m = ProposeRequest{} }
@@ -67,15 +69,31 @@ func (m *ProposeRequest) GetOp() *Operation {
 	return nil
 }
 
+func (m *ProposeRequest) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *ProposeRequest) GetTxn() []*Operation {
+	if m != nil {
+		return m.Txn
+	}
+	return nil
+}
+
 type ProposeReply struct {
-	Success              bool     `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Error                string   `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
-	Slot                 int64    `protobuf:"varint,4,opt,name=slot,proto3" json:"slot,omitempty"`
-	Key                  string   `protobuf:"bytes,5,opt,name=key,proto3" json:"key,omitempty"`
-	Value                []byte   `protobuf:"bytes,6,opt,name=value,proto3" json:"value,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Success              bool      `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error                string    `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Slot                 int64     `protobuf:"varint,4,opt,name=slot,proto3" json:"slot,omitempty"`
+	Key                  string    `protobuf:"bytes,5,opt,name=key,proto3" json:"key,omitempty"`
+	Value                []byte    `protobuf:"bytes,6,opt,name=value,proto3" json:"value,omitempty"`
+	Version              uint64    `protobuf:"varint,7,opt,name=version,proto3" json:"version,omitempty"`
+	Results              []*Result `protobuf:"bytes,8,rep,name=results,proto3" json:"results,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
 }
 
 func (m *ProposeReply) Reset()         { *m = ProposeReply{} }
@@ -138,26 +156,117 @@ func (m *ProposeReply) GetValue() []byte {
 	return nil
 }
 
+func (m *ProposeReply) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *ProposeReply) GetResults() []*Result {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+type Result struct {
+	Success              bool     `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error                string   `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Key                  string   `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	Value                []byte   `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`
+	Version              uint64   `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Result) Reset()         { *m = Result{} }
+func (m *Result) String() string { return proto.CompactTextString(m) }
+func (*Result) ProtoMessage()    {}
+func (*Result) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{2}
+}
+
+func (m *Result) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Result.Unmarshal(m, b)
+}
+func (m *Result) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Result.Marshal(b, m, deterministic)
+}
+func (m *Result) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Result.Merge(m, src)
+}
+func (m *Result) XXX_Size() int {
+	return xxx_messageInfo_Result.Size(m)
+}
+func (m *Result) XXX_DiscardUnknown() {
+	xxx_messageInfo_Result.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Result proto.InternalMessageInfo
+
+func (m *Result) GetSuccess() bool {
+	if m != nil {
+		return m.Success
+	}
+	return false
+}
+
+func (m *Result) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *Result) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *Result) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *Result) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*ProposeRequest)(nil), "pb.ProposeRequest")
 	proto.RegisterType((*ProposeReply)(nil), "pb.ProposeReply")
+	proto.RegisterType((*Result)(nil), "pb.Result")
 }
 
 func init() { proto.RegisterFile("client.proto", fileDescriptor_014de31d7ac8c57c) }
 
 var fileDescriptor_014de31d7ac8c57c = []byte{
-	// 201 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x3c, 0x8f, 0xcd, 0x4a, 0x04, 0x31,
-	0x0c, 0x80, 0x69, 0xf7, 0xc7, 0xdd, 0x58, 0x45, 0x82, 0x87, 0xb2, 0x20, 0x94, 0x3d, 0xf5, 0x34,
-	0x07, 0x7d, 0x0c, 0x0f, 0x4a, 0xdf, 0x60, 0x66, 0xcc, 0xa1, 0x58, 0x26, 0xb1, 0xed, 0x88, 0xe3,
-	0xd3, 0xcb, 0x76, 0x70, 0x6f, 0xdf, 0x17, 0x92, 0x0f, 0x02, 0x66, 0x4c, 0x91, 0xa6, 0xda, 0x49,
-	0xe6, 0xca, 0xa8, 0x65, 0x38, 0x19, 0x92, 0xfe, 0x87, 0xcb, 0x3a, 0x39, 0xbf, 0xc2, 0xfd, 0x7b,
-	0x66, 0xe1, 0x42, 0x81, 0xbe, 0x66, 0x2a, 0x15, 0x4f, 0x70, 0x88, 0x1f, 0x34, 0xd5, 0x58, 0x17,
-	0xab, 0x9c, 0xf2, 0xc7, 0x70, 0x75, 0x7c, 0x02, 0xcd, 0x62, 0xb5, 0x53, 0xfe, 0xf6, 0xf9, 0xae,
-	0x93, 0xa1, 0x7b, 0x13, 0xca, 0x7d, 0x8d, 0x3c, 0x05, 0xcd, 0x72, 0xfe, 0x05, 0x73, 0x8d, 0x49,
-	0x5a, 0xd0, 0xc2, 0x4d, 0x99, 0xc7, 0x91, 0x4a, 0x69, 0xa5, 0x43, 0xf8, 0x57, 0x7c, 0x84, 0x1d,
-	0xe5, 0xcc, 0xb9, 0xb5, 0x8e, 0x61, 0x15, 0x44, 0xd8, 0x96, 0xc4, 0xd5, 0x6e, 0x9d, 0xf2, 0x9b,
-	0xd0, 0x18, 0x1f, 0x60, 0xf3, 0x49, 0x8b, 0xdd, 0xb5, 0xbd, 0x0b, 0x5e, 0x6e, 0xbf, 0xfb, 0x34,
-	0x93, 0xdd, 0x3b, 0xe5, 0x4d, 0x58, 0x65, 0xd8, 0xb7, 0x7f, 0x5e, 0xfe, 0x02, 0x00, 0x00, 0xff,
-	0xff, 0x28, 0x0f, 0x54, 0x39, 0xf1, 0x00, 0x00, 0x00,
+	// 278 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x91, 0x4d, 0x4a, 0xf5, 0x30,
+	0x14, 0x86, 0x49, 0xd3, 0xbf, 0x7b, 0xbe, 0x7e, 0x22, 0xc1, 0x41, 0xb8, 0x20, 0x86, 0xe2, 0xa0,
+	0xa3, 0x0e, 0x74, 0x21, 0x4a, 0x76, 0xd0, 0xd6, 0x33, 0x28, 0x96, 0x24, 0x26, 0xe9, 0xe5, 0xd6,
+	0x15, 0xb8, 0x26, 0x57, 0x27, 0x49, 0x6d, 0x41, 0xd4, 0x81, 0xb3, 0xf3, 0xbc, 0x27, 0x6f, 0xf2,
+	0x40, 0xa0, 0x1a, 0xa6, 0x11, 0x95, 0x6f, 0x8d, 0xd5, 0x5e, 0xb3, 0xc4, 0xf4, 0xc7, 0x0a, 0x4d,
+	0x77, 0xd6, 0x6e, 0x4d, 0xea, 0x37, 0x02, 0x17, 0x8f, 0x56, 0x1b, 0xed, 0x50, 0xe2, 0xcb, 0x8c,
+	0xce, 0xb3, 0x23, 0x94, 0xe3, 0x13, 0x2a, 0x3f, 0xfa, 0x85, 0x13, 0x41, 0x9a, 0x83, 0xdc, 0x99,
+	0x5d, 0x43, 0xa2, 0x0d, 0x4f, 0x04, 0x69, 0xfe, 0xdd, 0xfd, 0x6f, 0x4d, 0xdf, 0x3e, 0x18, 0xb4,
+	0x9d, 0x1f, 0xb5, 0x92, 0x89, 0x36, 0xa1, 0xea, 0xc2, 0x2d, 0x6a, 0x40, 0x4e, 0x05, 0x69, 0x52,
+	0xb9, 0x33, 0xbb, 0x01, 0xea, 0xcf, 0x8a, 0xa7, 0x82, 0x7e, 0xef, 0x86, 0x4d, 0xfd, 0x4e, 0xa0,
+	0xda, 0x55, 0xcc, 0xb4, 0x30, 0x0e, 0x85, 0x9b, 0x87, 0x01, 0x9d, 0x8b, 0x1e, 0xa5, 0xdc, 0x90,
+	0x5d, 0x41, 0x86, 0xd6, 0x6a, 0x1b, 0x4d, 0x0e, 0x72, 0x05, 0xc6, 0x20, 0x75, 0x93, 0xf6, 0x3c,
+	0x15, 0xa4, 0xa1, 0x32, 0xce, 0xec, 0x12, 0xe8, 0x33, 0x2e, 0x3c, 0x8b, 0xe7, 0xc2, 0x18, 0xba,
+	0xa7, 0x6e, 0x9a, 0x91, 0xe7, 0x82, 0x34, 0x95, 0x5c, 0x21, 0xbc, 0x75, 0x42, 0xeb, 0x46, 0xad,
+	0x78, 0x11, 0xc5, 0x37, 0x64, 0xb7, 0x50, 0x58, 0x74, 0xf3, 0xe4, 0x1d, 0x2f, 0xa3, 0x3b, 0x04,
+	0x77, 0x19, 0x23, 0xb9, 0xad, 0xea, 0x57, 0xc8, 0xd7, 0xe8, 0xcf, 0xd6, 0x9f, 0x86, 0xf4, 0x07,
+	0xc3, 0xf4, 0x17, 0xc3, 0xec, 0x8b, 0x61, 0x9f, 0xc7, 0xaf, 0xbc, 0xff, 0x18, 0x00, 0x92, 0x7e,
+	0x48, 0xbc, 0xec, 0x01, 0x00, 0x00,
 }
@@ -59,30 +59,48 @@ func (Status) EnumDescriptor() ([]byte, []int) {
 type AccessType int32
 
 const (
-	AccessType_NULL      AccessType = 0
-	AccessType_READ      AccessType = 1
-	AccessType_WRITE     AccessType = 2
-	AccessType_WRITEREAD AccessType = 3
-	AccessType_DELETE    AccessType = 4
-	AccessType_PAUSE     AccessType = 5
+	AccessType_NULL          AccessType = 0
+	AccessType_READ          AccessType = 1
+	AccessType_WRITE         AccessType = 2
+	AccessType_WRITEREAD     AccessType = 3
+	AccessType_DELETE        AccessType = 4
+	AccessType_PAUSE         AccessType = 5
+	AccessType_EXPIRE        AccessType = 6
+	AccessType_CHECK         AccessType = 7
+	AccessType_CAS           AccessType = 8
+	AccessType_PUT_IF_ABSENT AccessType = 9
+	AccessType_INCREMENT     AccessType = 10
+	AccessType_RECONFIGURE   AccessType = 11
 )
 
 var AccessType_name = map[int32]string{
-	0: "NULL",
-	1: "READ",
-	2: "WRITE",
-	3: "WRITEREAD",
-	4: "DELETE",
-	5: "PAUSE",
+	0:  "NULL",
+	1:  "READ",
+	2:  "WRITE",
+	3:  "WRITEREAD",
+	4:  "DELETE",
+	5:  "PAUSE",
+	6:  "EXPIRE",
+	7:  "CHECK",
+	8:  "CAS",
+	9:  "PUT_IF_ABSENT",
+	10: "INCREMENT",
+	11: "RECONFIGURE",
 }
 
 var AccessType_value = map[string]int32{
-	"NULL":      0,
-	"READ":      1,
-	"WRITE":     2,
-	"WRITEREAD": 3,
-	"DELETE":    4,
-	"PAUSE":     5,
+	"NULL":          0,
+	"READ":          1,
+	"WRITE":         2,
+	"WRITEREAD":     3,
+	"DELETE":        4,
+	"PAUSE":         5,
+	"EXPIRE":        6,
+	"CHECK":         7,
+	"CAS":           8,
+	"PUT_IF_ABSENT": 9,
+	"INCREMENT":     10,
+	"RECONFIGURE":   11,
 }
 
 func (x AccessType) String() string {
@@ -106,6 +124,7 @@ type Instance struct {
 	Reads                bool              `protobuf:"varint,8,opt,name=reads,proto3" json:"reads,omitempty"`
 	Visited              uint64            `protobuf:"varint,9,opt,name=visited,proto3" json:"visited,omitempty"`
 	Ops                  []*Operation      `protobuf:"bytes,10,rep,name=ops,proto3" json:"ops,omitempty"`
+	Epoch                uint64            `protobuf:"varint,11,opt,name=epoch,proto3" json:"epoch,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
 	XXX_unrecognized     []byte            `json:"-"`
 	XXX_sizecache        int32             `json:"-"`
@@ -206,12 +225,23 @@ func (m *Instance) GetOps() []*Operation {
 	return nil
 }
 
+func (m *Instance) GetEpoch() uint64 {
+	if m != nil {
+		return m.Epoch
+	}
+	return 0
+}
+
 // An operation is a command that will be applied to the key-value store.
 type Operation struct {
 	Type                 AccessType `protobuf:"varint,1,opt,name=type,proto3,enum=pb.AccessType" json:"type,omitempty"`
 	Key                  string     `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
 	Value                []byte     `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
 	Request              uint64     `protobuf:"varint,4,opt,name=request,proto3" json:"request,omitempty"`
+	Client               string     `protobuf:"bytes,5,opt,name=client,proto3" json:"client,omitempty"`
+	Sequence             uint64     `protobuf:"varint,6,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Version              uint64     `protobuf:"varint,7,opt,name=version,proto3" json:"version,omitempty"`
+	Expect               []byte     `protobuf:"bytes,8,opt,name=expect,proto3" json:"expect,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
 	XXX_unrecognized     []byte     `json:"-"`
 	XXX_sizecache        int32      `json:"-"`
@@ -270,6 +300,34 @@ func (m *Operation) GetRequest() uint64 {
 	return 0
 }
 
+func (m *Operation) GetClient() string {
+	if m != nil {
+		return m.Client
+	}
+	return ""
+}
+
+func (m *Operation) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *Operation) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *Operation) GetExpect() []byte {
+	if m != nil {
+		return m.Expect
+	}
+	return nil
+}
+
 // Send a preaccept request from leader to remote peers for the given instance.
 type PreacceptRequest struct {
 	Inst                 *Instance `protobuf:"bytes,1,opt,name=inst,proto3" json:"inst,omitempty"`
@@ -541,6 +599,7 @@ type BeaconRequest struct {
 	Replica              uint32            `protobuf:"varint,2,opt,name=replica,proto3" json:"replica,omitempty"`
 	Slots                map[uint32]uint64 `protobuf:"bytes,3,rep,name=slots,proto3" json:"slots,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
 	Commits              map[uint32]uint64 `protobuf:"bytes,4,rep,name=commits,proto3" json:"commits,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	Executed             map[uint32]uint64 `protobuf:"bytes,5,rep,name=executed,proto3" json:"executed,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
 	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
 	XXX_unrecognized     []byte            `json:"-"`
 	XXX_sizecache        int32             `json:"-"`
@@ -599,11 +658,19 @@ func (m *BeaconRequest) GetCommits() map[uint32]uint64 {
 	return nil
 }
 
+func (m *BeaconRequest) GetExecuted() map[uint32]uint64 {
+	if m != nil {
+		return m.Executed
+	}
+	return nil
+}
+
 type BeaconReply struct {
 	QuorumMember         bool              `protobuf:"varint,1,opt,name=quorumMember,proto3" json:"quorumMember,omitempty"`
 	Replica              uint32            `protobuf:"varint,2,opt,name=replica,proto3" json:"replica,omitempty"`
 	Slots                map[uint32]uint64 `protobuf:"bytes,3,rep,name=slots,proto3" json:"slots,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
 	Commits              map[uint32]uint64 `protobuf:"bytes,4,rep,name=commits,proto3" json:"commits,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	Executed             map[uint32]uint64 `protobuf:"bytes,5,rep,name=executed,proto3" json:"executed,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
 	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
 	XXX_unrecognized     []byte            `json:"-"`
 	XXX_sizecache        int32             `json:"-"`
@@ -662,6 +729,13 @@ func (m *BeaconReply) GetCommits() map[uint32]uint64 {
 	return nil
 }
 
+func (m *BeaconReply) GetExecuted() map[uint32]uint64 {
+	if m != nil {
+		return m.Executed
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterEnum("pb.Status", Status_name, Status_value)
 	proto.RegisterEnum("pb.AccessType", AccessType_name, AccessType_value)
@@ -677,54 +751,67 @@ func init() {
 	proto.RegisterType((*CommitReply)(nil), "pb.CommitReply")
 	proto.RegisterType((*BeaconRequest)(nil), "pb.BeaconRequest")
 	proto.RegisterMapType((map[uint32]uint64)(nil), "pb.BeaconRequest.CommitsEntry")
+	proto.RegisterMapType((map[uint32]uint64)(nil), "pb.BeaconRequest.ExecutedEntry")
 	proto.RegisterMapType((map[uint32]uint64)(nil), "pb.BeaconRequest.SlotsEntry")
 	proto.RegisterType((*BeaconReply)(nil), "pb.BeaconReply")
 	proto.RegisterMapType((map[uint32]uint64)(nil), "pb.BeaconReply.CommitsEntry")
+	proto.RegisterMapType((map[uint32]uint64)(nil), "pb.BeaconReply.ExecutedEntry")
 	proto.RegisterMapType((map[uint32]uint64)(nil), "pb.BeaconReply.SlotsEntry")
 }
 
 func init() { proto.RegisterFile("epaxos.proto", fileDescriptor_a89189ba059724a6) }
 
 var fileDescriptor_a89189ba059724a6 = []byte{
-	// 640 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x55, 0x4b, 0x8f, 0xd2, 0x50,
-	0x14, 0xb6, 0x0f, 0xa0, 0x1c, 0x1e, 0x92, 0x1b, 0x63, 0x1a, 0x32, 0x51, 0xec, 0x8a, 0xcc, 0x82,
-	0x8c, 0x68, 0x74, 0x32, 0xbb, 0xca, 0xdc, 0x05, 0xc9, 0x3c, 0x98, 0x3b, 0x4c, 0x74, 0x5b, 0xca,
-	0x8d, 0x92, 0x81, 0xf6, 0xd2, 0x7b, 0x99, 0xc8, 0x6f, 0x72, 0xe5, 0xda, 0x7f, 0xe1, 0x2f, 0x32,
-	0xe7, 0xb6, 0x65, 0x28, 0x44, 0x13, 0x74, 0xe3, 0xee, 0x3c, 0xbf, 0x73, 0xce, 0xf7, 0x71, 0x0b,
-	0xd4, 0xb9, 0x08, 0xbe, 0xc6, 0xb2, 0x27, 0x92, 0x58, 0xc5, 0xc4, 0x14, 0x13, 0xef, 0xa7, 0x09,
-	0xce, 0x30, 0x92, 0x2a, 0x88, 0x42, 0x4e, 0x5c, 0xa8, 0x24, 0x5c, 0xcc, 0x67, 0x61, 0xe0, 0x1a,
-	0x1d, 0xa3, 0xdb, 0x60, 0xb9, 0x4b, 0x08, 0xd8, 0x72, 0x1e, 0x2b, 0xd7, 0xec, 0x18, 0x5d, 0x9b,
-	0x69, 0x9b, 0xb4, 0xc0, 0x92, 0x7c, 0xe9, 0x5a, 0x3a, 0x84, 0x26, 0x39, 0x06, 0x7b, 0xca, 0x85,
-	0x74, 0xed, 0x8e, 0xd5, 0xad, 0xf5, 0x9f, 0xf7, 0xc4, 0xa4, 0x97, 0x63, 0xf7, 0xce, 0xb9, 0x90,
-	0x34, 0x52, 0xc9, 0x9a, 0xe9, 0x1a, 0xe2, 0x41, 0x59, 0xaa, 0x40, 0xad, 0xa4, 0x5b, 0xea, 0x18,
-	0xdd, 0x66, 0x1f, 0xb0, 0xfa, 0x56, 0x47, 0x58, 0x96, 0xc1, 0xa9, 0x41, 0x78, 0x2f, 0xdd, 0xb2,
-	0x5e, 0x46, 0xdb, 0xb8, 0x63, 0xf8, 0x25, 0x88, 0x3e, 0xf3, 0xa9, 0x5b, 0xe9, 0x18, 0x5d, 0x87,
-	0xe5, 0x2e, 0x79, 0x06, 0xa5, 0x84, 0x07, 0x53, 0xe9, 0x3a, 0x3a, 0x9e, 0x3a, 0x58, 0xff, 0x30,
-	0x93, 0x33, 0xc5, 0xa7, 0x6e, 0x55, 0x6f, 0x9a, 0xbb, 0xe4, 0x25, 0x58, 0xb1, 0x90, 0x2e, 0xe8,
-	0x65, 0x1b, 0x38, 0xfe, 0x5a, 0xf0, 0x24, 0x50, 0xb3, 0x38, 0x62, 0x98, 0x69, 0xbf, 0x87, 0xea,
-	0x66, 0x6b, 0xbc, 0xf6, 0x9e, 0xaf, 0x33, 0x5e, 0xd0, 0xc4, 0x79, 0x0f, 0xc1, 0x7c, 0xc5, 0x33,
-	0x52, 0x52, 0xe7, 0xcc, 0x3c, 0x35, 0xbc, 0x25, 0x54, 0x37, 0x50, 0xc4, 0x03, 0x5b, 0xad, 0x05,
-	0xd7, 0x9d, 0xcd, 0x7e, 0x13, 0xe7, 0xf8, 0x61, 0xc8, 0xa5, 0x1c, 0xaf, 0x05, 0x67, 0x3a, 0x97,
-	0x83, 0x23, 0x50, 0x75, 0x07, 0x1c, 0xe9, 0xad, 0x67, 0xe0, 0xa9, 0x40, 0xcb, 0x15, 0x97, 0xca,
-	0xb5, 0xd3, 0x63, 0x32, 0xd7, 0x7b, 0x0b, 0xad, 0x51, 0xc2, 0x83, 0x30, 0xe4, 0x42, 0xb1, 0x34,
-	0x46, 0x3a, 0x60, 0xcf, 0x22, 0xa9, 0xf4, 0xe4, 0x5a, 0xbf, 0xbe, 0x2d, 0x07, 0xd3, 0x19, 0xef,
-	0x87, 0x01, 0xcd, 0xad, 0x36, 0x31, 0x5f, 0x6f, 0x94, 0x36, 0xf6, 0x95, 0x36, 0x1f, 0x95, 0x3e,
-	0xc9, 0x94, 0xb6, 0x34, 0x79, 0x47, 0x08, 0x5d, 0xc4, 0xd9, 0xd3, 0x7b, 0x4b, 0x37, 0xbb, 0xa0,
-	0xdb, 0xdf, 0xd3, 0xfc, 0x1a, 0x1a, 0xfe, 0x81, 0x07, 0xbf, 0x82, 0x9a, 0xff, 0xe7, 0x63, 0x11,
-	0x75, 0x10, 0x2f, 0x16, 0xb3, 0xc3, 0x50, 0xf3, 0x96, 0xdf, 0xa1, 0x7e, 0x37, 0xa1, 0xf1, 0x81,
-	0x07, 0x61, 0x1c, 0xe5, 0xb0, 0x1e, 0xd4, 0x97, 0xab, 0x38, 0x59, 0x2d, 0x2e, 0xf9, 0x62, 0xc2,
-	0x13, 0x5d, 0xed, 0xb0, 0x42, 0x6c, 0xfb, 0x41, 0x9a, 0xc5, 0x07, 0xd9, 0x87, 0x12, 0xe2, 0x16,
-	0x14, 0x28, 0xe0, 0xf7, 0x6e, 0x31, 0x9d, 0x2a, 0x90, 0x96, 0x92, 0x53, 0xa8, 0x84, 0x7a, 0xcd,
-	0xfc, 0x85, 0xbe, 0xd8, 0xef, 0x4a, 0xef, 0xc8, 0xfa, 0xf2, 0xf2, 0xf6, 0x29, 0xc0, 0x23, 0xdc,
-	0x21, 0x1a, 0xb5, 0xcf, 0xa0, 0xbe, 0x0d, 0x79, 0x90, 0xbe, 0xdf, 0x4c, 0xa8, 0xe5, 0xdb, 0x21,
-	0xaf, 0xff, 0xc6, 0xd8, 0x49, 0x91, 0xb1, 0xf6, 0xf6, 0xed, 0xf8, 0x83, 0xdd, 0xe7, 0xeb, 0xdd,
-	0x2e, 0x5f, 0x47, 0xbb, 0x3d, 0xff, 0x11, 0x5b, 0xc7, 0x37, 0x50, 0x4e, 0x3f, 0x9f, 0xa4, 0x06,
-	0x95, 0xe1, 0xd5, 0x70, 0x3c, 0xf4, 0x2f, 0x5a, 0x4f, 0xc8, 0x53, 0xa8, 0x8d, 0x18, 0xf5, 0x07,
-	0x03, 0x3a, 0x1a, 0xd3, 0xf3, 0x96, 0x41, 0xea, 0xe0, 0x6c, 0x3c, 0x93, 0x34, 0xa0, 0x3a, 0xb8,
-	0xbe, 0xbc, 0x1c, 0x8e, 0xd1, 0xb5, 0x30, 0x49, 0x3f, 0xd1, 0xc1, 0x1d, 0x7a, 0xf6, 0xf1, 0x0d,
-	0xc0, 0xe3, 0xa7, 0x8a, 0x38, 0x60, 0x5f, 0xdd, 0x5d, 0x20, 0xa6, 0x03, 0x36, 0xa3, 0x3e, 0x82,
-	0x55, 0xa1, 0xf4, 0x91, 0x0d, 0xc7, 0x34, 0x45, 0xd2, 0xa6, 0xce, 0x58, 0x04, 0xa0, 0x7c, 0x4e,
-	0x2f, 0xe8, 0x98, 0xb6, 0x6c, 0xac, 0x1a, 0xf9, 0x77, 0xb7, 0xb4, 0x55, 0x9a, 0x94, 0xf5, 0x5f,
-	0xcf, 0x9b, 0x5f, 0x01, 0x00, 0x00, 0xff, 0xff, 0xe3, 0x8d, 0x07, 0x23, 0x8a, 0x06, 0x00, 0x00,
+	// 806 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xdc, 0x56, 0xc1, 0x8e, 0xe3, 0x44,
+	0x10, 0xc5, 0xb1, 0x93, 0xd8, 0xe5, 0x64, 0x30, 0x2d, 0xb4, 0xb2, 0xa2, 0x85, 0x0d, 0x39, 0x45,
+	0x73, 0x88, 0x96, 0x80, 0x60, 0xd8, 0x3d, 0x79, 0x3d, 0xbd, 0x60, 0x31, 0xc9, 0x84, 0x8e, 0x23,
+	0xf6, 0xb6, 0x72, 0x9c, 0x16, 0x6b, 0x6d, 0x62, 0x7b, 0xdc, 0xce, 0x68, 0xf2, 0x3d, 0x70, 0xe3,
+	0x13, 0xf8, 0x10, 0x7e, 0x81, 0xcf, 0x40, 0xd5, 0xb6, 0x33, 0x71, 0x02, 0x48, 0x81, 0x1b, 0xb7,
+	0x7e, 0xdd, 0xf5, 0x5e, 0x57, 0xd5, 0xab, 0xb4, 0x03, 0x1d, 0x9e, 0x06, 0x0f, 0x89, 0x18, 0xa5,
+	0x59, 0x92, 0x27, 0xa4, 0x91, 0x2e, 0x07, 0x7f, 0x34, 0x40, 0xf7, 0x62, 0x91, 0x07, 0x71, 0xc8,
+	0x89, 0x0d, 0xed, 0x8c, 0xa7, 0xeb, 0x28, 0x0c, 0x6c, 0xa5, 0xaf, 0x0c, 0xbb, 0xac, 0x82, 0x84,
+	0x80, 0x26, 0xd6, 0x49, 0x6e, 0x37, 0xfa, 0xca, 0x50, 0x63, 0x72, 0x4d, 0x2c, 0x50, 0x05, 0xbf,
+	0xb3, 0x55, 0xb9, 0x85, 0x4b, 0x72, 0x09, 0xda, 0x8a, 0xa7, 0xc2, 0xd6, 0xfa, 0xea, 0xd0, 0x1c,
+	0x3f, 0x19, 0xa5, 0xcb, 0x51, 0xa5, 0x3d, 0xba, 0xe6, 0xa9, 0xa0, 0x71, 0x9e, 0xed, 0x98, 0x8c,
+	0x21, 0x03, 0x68, 0x89, 0x3c, 0xc8, 0xb7, 0xc2, 0x6e, 0xf6, 0x95, 0xe1, 0xc5, 0x18, 0x30, 0x7a,
+	0x2e, 0x77, 0x58, 0x79, 0x82, 0xb7, 0x06, 0xe1, 0x7b, 0x61, 0xb7, 0x64, 0x32, 0x72, 0x8d, 0x39,
+	0x86, 0xef, 0x82, 0xf8, 0x27, 0xbe, 0xb2, 0xdb, 0x7d, 0x65, 0xa8, 0xb3, 0x0a, 0x92, 0x8f, 0xa1,
+	0x99, 0xf1, 0x60, 0x25, 0x6c, 0x5d, 0xee, 0x17, 0x00, 0xe3, 0xef, 0x23, 0x11, 0xe5, 0x7c, 0x65,
+	0x1b, 0x32, 0xd3, 0x0a, 0x92, 0x67, 0xa0, 0x26, 0xa9, 0xb0, 0x41, 0x26, 0xdb, 0xc5, 0xeb, 0x6f,
+	0x53, 0x9e, 0x05, 0x79, 0x94, 0xc4, 0x0c, 0x4f, 0x50, 0x90, 0xa7, 0x49, 0xf8, 0xce, 0x36, 0x25,
+	0xb1, 0x00, 0xbd, 0xaf, 0xc1, 0xd8, 0xd7, 0x82, 0x3d, 0x78, 0xcf, 0x77, 0x65, 0xb7, 0x70, 0x89,
+	0xa4, 0xfb, 0x60, 0xbd, 0xe5, 0x65, 0xab, 0x0a, 0xf0, 0xa2, 0x71, 0xa5, 0x0c, 0x7e, 0x57, 0xc0,
+	0xd8, 0xdf, 0x40, 0x06, 0xa0, 0xe5, 0xbb, 0x94, 0x4b, 0xea, 0xc5, 0xf8, 0x02, 0xaf, 0x77, 0xc2,
+	0x90, 0x0b, 0xe1, 0xef, 0x52, 0xce, 0xe4, 0x59, 0xa5, 0x8e, 0x4a, 0xc6, 0x91, 0x3a, 0x76, 0xbd,
+	0x53, 0xaa, 0x17, 0xbe, 0xdd, 0x6d, 0xb9, 0xc8, 0x6d, 0xad, 0xa8, 0xb1, 0x84, 0xe4, 0x09, 0xb4,
+	0xc2, 0x75, 0xc4, 0xe3, 0x5c, 0x76, 0xd9, 0x60, 0x25, 0x22, 0x3d, 0xd0, 0x05, 0x86, 0xc4, 0x21,
+	0x97, 0xdd, 0xd5, 0xd8, 0x1e, 0xcb, 0x8e, 0xf1, 0x4c, 0x44, 0x49, 0x6c, 0xb7, 0xcb, 0x8e, 0x15,
+	0x10, 0xd5, 0xf8, 0x43, 0xca, 0xc3, 0x5c, 0xb6, 0xb8, 0xc3, 0x4a, 0x34, 0xf8, 0x12, 0xac, 0x59,
+	0xc6, 0x83, 0x30, 0xe4, 0x69, 0xce, 0xca, 0x9b, 0xfb, 0xa0, 0x45, 0xb1, 0xc8, 0x65, 0x7d, 0xe6,
+	0xb8, 0x73, 0x38, 0x0b, 0x4c, 0x9e, 0x0c, 0x7e, 0x53, 0xe0, 0xe2, 0x80, 0x96, 0xae, 0x77, 0xfb,
+	0x31, 0x53, 0x4e, 0xc7, 0xac, 0xf1, 0x38, 0x66, 0xcf, 0xcb, 0x31, 0x53, 0xa5, 0x73, 0x4f, 0x51,
+	0xba, 0xae, 0x73, 0x32, 0x6c, 0x07, 0x43, 0xa3, 0xd5, 0x86, 0xe6, 0xdf, 0xbb, 0xf9, 0x39, 0x74,
+	0x9d, 0x33, 0x0b, 0xfe, 0x0c, 0x4c, 0xe7, 0x9f, 0x8b, 0x45, 0x55, 0x37, 0xd9, 0x6c, 0xa2, 0xf3,
+	0x54, 0x2b, 0xca, 0xdf, 0xa9, 0xfe, 0xaa, 0x42, 0xf7, 0x15, 0x0f, 0xc2, 0x24, 0xae, 0x64, 0x07,
+	0xd0, 0xb9, 0xdb, 0x26, 0xd9, 0x76, 0x33, 0xe1, 0x9b, 0x25, 0xcf, 0x64, 0xb4, 0xce, 0x6a, 0x7b,
+	0x87, 0xaf, 0x41, 0xa3, 0xfe, 0x1a, 0x8c, 0xa1, 0x89, 0xba, 0x35, 0x07, 0x6a, 0xfa, 0xa3, 0x39,
+	0x1e, 0x17, 0x0e, 0x14, 0xa1, 0xe4, 0x0a, 0xda, 0xa1, 0x4c, 0xb3, 0x7a, 0x1e, 0x3e, 0x3d, 0x65,
+	0x15, 0x75, 0x94, 0xbc, 0x2a, 0x9c, 0xbc, 0x04, 0x9d, 0x3f, 0xf0, 0x70, 0x8b, 0x3f, 0xe1, 0xa6,
+	0xa4, 0x3e, 0x3b, 0xa5, 0xd2, 0x32, 0xa2, 0xe0, 0xee, 0x09, 0xbd, 0x2b, 0x80, 0xc7, 0x5c, 0xce,
+	0x31, 0xb8, 0xf7, 0x02, 0x3a, 0x87, 0xf9, 0x9c, 0xc5, 0x7d, 0x09, 0xdd, 0x5a, 0x42, 0x67, 0x4d,
+	0xd6, 0xcf, 0x2a, 0x98, 0x55, 0x71, 0xe8, 0xe8, 0x7f, 0xf3, 0xea, 0x79, 0xdd, 0xab, 0xde, 0x61,
+	0xeb, 0xf0, 0xa7, 0x72, 0xea, 0xd4, 0x57, 0xc7, 0x4e, 0x3d, 0x3d, 0xe6, 0xfc, 0xb5, 0x4f, 0xdf,
+	0x9c, 0xf8, 0xf4, 0xc9, 0x31, 0xf1, 0xff, 0xe3, 0xd2, 0xe5, 0x0f, 0xd0, 0x2a, 0xbe, 0x56, 0xc4,
+	0x84, 0xb6, 0x37, 0xf5, 0x7c, 0xcf, 0xb9, 0xb1, 0x3e, 0x20, 0x1f, 0x82, 0x39, 0x63, 0xd4, 0x71,
+	0x5d, 0x3a, 0xf3, 0xe9, 0xb5, 0xa5, 0x90, 0x0e, 0xe8, 0x7b, 0xd4, 0x20, 0x5d, 0x30, 0xdc, 0xdb,
+	0xc9, 0xc4, 0xf3, 0x11, 0xaa, 0x78, 0x48, 0xdf, 0x50, 0x77, 0x81, 0x48, 0xbb, 0xfc, 0x45, 0x01,
+	0x78, 0xfc, 0x06, 0x10, 0x1d, 0xb4, 0xe9, 0xe2, 0x06, 0x45, 0x75, 0xd0, 0x18, 0x75, 0x50, 0xcd,
+	0x80, 0xe6, 0x8f, 0xcc, 0xf3, 0x69, 0x21, 0x25, 0x97, 0xf2, 0x44, 0x25, 0x00, 0xad, 0x6b, 0x7a,
+	0x43, 0x7d, 0x6a, 0x69, 0x18, 0x35, 0x73, 0x16, 0x73, 0x6a, 0x35, 0x71, 0x9b, 0xbe, 0x99, 0x79,
+	0x8c, 0x5a, 0x2d, 0xdc, 0x76, 0xbf, 0xa3, 0xee, 0xf7, 0x56, 0x9b, 0xb4, 0x41, 0x75, 0x9d, 0xb9,
+	0xa5, 0x93, 0x8f, 0xa0, 0x3b, 0x5b, 0xf8, 0x6f, 0xbd, 0xd7, 0x6f, 0x9d, 0x57, 0x73, 0x3a, 0xf5,
+	0x2d, 0x03, 0x85, 0xbd, 0xa9, 0xcb, 0xe8, 0x04, 0x21, 0x60, 0x45, 0x8c, 0xba, 0xb7, 0xd3, 0xd7,
+	0xde, 0xb7, 0x0b, 0x46, 0x2d, 0x73, 0xd9, 0x92, 0xff, 0x1e, 0xbe, 0xf8, 0x73, 0x00, 0x02, 0xf5,
+	0x56, 0x2e, 0x4d, 0x08, 0x00, 0x00,
 }
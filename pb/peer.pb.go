@@ -29,6 +29,7 @@ const (
 	Type_ACCEPT    Type = 2
 	Type_COMMIT    Type = 3
 	Type_BEACON    Type = 4
+	Type_SNAPSHOT  Type = 5
 )
 
 var Type_name = map[int32]string{
@@ -37,6 +38,7 @@ var Type_name = map[int32]string{
 	2: "ACCEPT",
 	3: "COMMIT",
 	4: "BEACON",
+	5: "SNAPSHOT",
 }
 
 var Type_value = map[string]int32{
@@ -45,6 +47,7 @@ var Type_value = map[string]int32{
 	"ACCEPT":    2,
 	"COMMIT":    3,
 	"BEACON":    4,
+	"SNAPSHOT":  5,
 }
 
 func (x Type) String() string {
@@ -67,6 +70,7 @@ type PeerRequest struct {
 	//	*PeerRequest_Accept
 	//	*PeerRequest_Commit
 	//	*PeerRequest_Beacon
+	//	*PeerRequest_Snapshot
 	Message              isPeerRequest_Message `protobuf_oneof:"message"`
 	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
 	XXX_unrecognized     []byte                `json:"-"`
@@ -132,6 +136,10 @@ type PeerRequest_Beacon struct {
 	Beacon *BeaconRequest `protobuf:"bytes,14,opt,name=beacon,proto3,oneof"`
 }
 
+type PeerRequest_Snapshot struct {
+	Snapshot *SnapshotRequest `protobuf:"bytes,15,opt,name=snapshot,proto3,oneof"`
+}
+
 func (*PeerRequest_Preaccept) isPeerRequest_Message() {}
 
 func (*PeerRequest_Accept) isPeerRequest_Message() {}
@@ -140,6 +148,8 @@ func (*PeerRequest_Commit) isPeerRequest_Message() {}
 
 func (*PeerRequest_Beacon) isPeerRequest_Message() {}
 
+func (*PeerRequest_Snapshot) isPeerRequest_Message() {}
+
 func (m *PeerRequest) GetMessage() isPeerRequest_Message {
 	if m != nil {
 		return m.Message
@@ -175,6 +185,13 @@ func (m *PeerRequest) GetBeacon() *BeaconRequest {
 	return nil
 }
 
+func (m *PeerRequest) GetSnapshot() *SnapshotRequest {
+	if x, ok := m.GetMessage().(*PeerRequest_Snapshot); ok {
+		return x.Snapshot
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*PeerRequest) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _PeerRequest_OneofMarshaler, _PeerRequest_OneofUnmarshaler, _PeerRequest_OneofSizer, []interface{}{
@@ -182,6 +199,7 @@ func (*PeerRequest) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) e
 		(*PeerRequest_Accept)(nil),
 		(*PeerRequest_Commit)(nil),
 		(*PeerRequest_Beacon)(nil),
+		(*PeerRequest_Snapshot)(nil),
 	}
 }
 
@@ -209,6 +227,11 @@ func _PeerRequest_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.Beacon); err != nil {
 			return err
 		}
+	case *PeerRequest_Snapshot:
+		b.EncodeVarint(15<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Snapshot); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("PeerRequest.Message has unexpected type %T", x)
@@ -251,6 +274,14 @@ func _PeerRequest_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Bu
 		err := b.DecodeMessage(msg)
 		m.Message = &PeerRequest_Beacon{msg}
 		return true, err
+	case 15: // message.snapshot
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(SnapshotRequest)
+		err := b.DecodeMessage(msg)
+		m.Message = &PeerRequest_Snapshot{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -280,6 +311,11 @@ func _PeerRequest_OneofSizer(msg proto.Message) (n int) {
 		n += 1 // tag and wire
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *PeerRequest_Snapshot:
+		s := proto.Size(x.Snapshot)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -300,6 +336,7 @@ type PeerReply struct {
 	//	*PeerReply_Accept
 	//	*PeerReply_Commit
 	//	*PeerReply_Beacon
+	//	*PeerReply_Snapshot
 	Message              isPeerReply_Message `protobuf_oneof:"message"`
 	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
 	XXX_unrecognized     []byte              `json:"-"`
@@ -372,6 +409,10 @@ type PeerReply_Beacon struct {
 	Beacon *BeaconReply `protobuf:"bytes,14,opt,name=beacon,proto3,oneof"`
 }
 
+type PeerReply_Snapshot struct {
+	Snapshot *SnapshotReply `protobuf:"bytes,15,opt,name=snapshot,proto3,oneof"`
+}
+
 func (*PeerReply_Preaccept) isPeerReply_Message() {}
 
 func (*PeerReply_Accept) isPeerReply_Message() {}
@@ -380,6 +421,8 @@ func (*PeerReply_Commit) isPeerReply_Message() {}
 
 func (*PeerReply_Beacon) isPeerReply_Message() {}
 
+func (*PeerReply_Snapshot) isPeerReply_Message() {}
+
 func (m *PeerReply) GetMessage() isPeerReply_Message {
 	if m != nil {
 		return m.Message
@@ -415,6 +458,13 @@ func (m *PeerReply) GetBeacon() *BeaconReply {
 	return nil
 }
 
+func (m *PeerReply) GetSnapshot() *SnapshotReply {
+	if x, ok := m.GetMessage().(*PeerReply_Snapshot); ok {
+		return x.Snapshot
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*PeerReply) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _PeerReply_OneofMarshaler, _PeerReply_OneofUnmarshaler, _PeerReply_OneofSizer, []interface{}{
@@ -422,6 +472,7 @@ func (*PeerReply) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) err
 		(*PeerReply_Accept)(nil),
 		(*PeerReply_Commit)(nil),
 		(*PeerReply_Beacon)(nil),
+		(*PeerReply_Snapshot)(nil),
 	}
 }
 
@@ -449,6 +500,11 @@ func _PeerReply_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.Beacon); err != nil {
 			return err
 		}
+	case *PeerReply_Snapshot:
+		b.EncodeVarint(15<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Snapshot); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("PeerReply.Message has unexpected type %T", x)
@@ -491,6 +547,14 @@ func _PeerReply_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buff
 		err := b.DecodeMessage(msg)
 		m.Message = &PeerReply_Beacon{msg}
 		return true, err
+	case 15: // message.snapshot
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(SnapshotReply)
+		err := b.DecodeMessage(msg)
+		m.Message = &PeerReply_Snapshot{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -520,6 +584,11 @@ func _PeerReply_OneofSizer(msg proto.Message) (n int) {
 		n += 1 // tag and wire
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *PeerReply_Snapshot:
+		s := proto.Size(x.Snapshot)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -536,26 +605,29 @@ func init() {
 func init() { proto.RegisterFile("peer.proto", fileDescriptor_055ae5a865fc1c9e) }
 
 var fileDescriptor_055ae5a865fc1c9e = []byte{
-	// 336 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x92, 0xcd, 0x6a, 0x83, 0x40,
-	0x14, 0x85, 0xa3, 0x09, 0x26, 0x5e, 0xf3, 0x63, 0x87, 0x52, 0x86, 0xd2, 0x85, 0x64, 0x65, 0x5a,
-	0xc8, 0x22, 0xed, 0x0b, 0x24, 0x92, 0xd2, 0x52, 0x62, 0x44, 0x52, 0xba, 0xd6, 0xe9, 0xa5, 0x14,
-	0x92, 0x38, 0x75, 0x0c, 0xd4, 0x67, 0xe8, 0x23, 0xf4, 0x65, 0xcb, 0xe8, 0x04, 0x8d, 0xd9, 0x75,
-	0x77, 0xee, 0xe1, 0x3b, 0x82, 0x1f, 0x03, 0xc0, 0x11, 0xd3, 0x29, 0x4f, 0x93, 0x2c, 0x21, 0x3a,
-	0x8f, 0xaf, 0xfb, 0xc8, 0xa3, 0xef, 0x44, 0x94, 0xcd, 0xf8, 0x47, 0x07, 0x2b, 0x40, 0x4c, 0x43,
-	0xfc, 0x3a, 0xa0, 0xc8, 0xc8, 0x0d, 0x74, 0xb2, 0x9c, 0x23, 0xd5, 0x1c, 0xcd, 0x1d, 0xce, 0x7a,
-	0x53, 0x1e, 0x4f, 0x37, 0x39, 0xc7, 0xb0, 0x68, 0xc9, 0x15, 0x18, 0x02, 0xf7, 0xef, 0x98, 0x52,
-	0xdd, 0xd1, 0x5c, 0x33, 0x54, 0x17, 0x79, 0x00, 0x93, 0xa7, 0x18, 0x31, 0x86, 0x3c, 0xa3, 0x96,
-	0xa3, 0xb9, 0xd6, 0xec, 0x52, 0x4e, 0x83, 0x63, 0xa9, 0x3e, 0xff, 0xd4, 0x0a, 0x2b, 0x90, 0xdc,
-	0x81, 0xa1, 0x26, 0xfd, 0x62, 0x72, 0x21, 0x27, 0xf3, 0x06, 0x6f, 0x54, 0x30, 0x4b, 0x76, 0xbb,
-	0xcf, 0x8c, 0x0e, 0x2a, 0xd8, 0x2b, 0x9a, 0x1a, 0x5c, 0x22, 0x12, 0x8e, 0x31, 0x62, 0xc9, 0x9e,
-	0x0e, 0x2b, 0x78, 0x51, 0x34, 0x35, 0xb8, 0x44, 0x16, 0x26, 0x74, 0x77, 0x28, 0x44, 0xf4, 0x81,
-	0xe3, 0x5f, 0x1d, 0xcc, 0xd2, 0x06, 0xdf, 0xe6, 0xff, 0x74, 0x41, 0xa1, 0x2b, 0x0e, 0x8c, 0xa1,
-	0x10, 0xb4, 0xed, 0x68, 0x6e, 0x2f, 0x3c, 0x9e, 0x64, 0x76, 0x6e, 0x89, 0x34, 0x2c, 0xf1, 0x6d,
-	0x7e, 0xea, 0x68, 0xd2, 0x70, 0x34, 0xaa, 0x3b, 0x2a, 0x69, 0xa3, 0x42, 0x4f, 0x0c, 0x8d, 0xea,
-	0x86, 0x14, 0xaa, 0xfc, 0x4c, 0x1a, 0x7e, 0x46, 0x75, 0x3f, 0x0a, 0x3d, 0xb3, 0x73, 0xfb, 0x08,
-	0x1d, 0xf9, 0xff, 0xc4, 0x82, 0xee, 0xab, 0xff, 0xe2, 0xaf, 0xdf, 0x7c, 0xbb, 0x45, 0x06, 0x60,
-	0x06, 0xe1, 0x72, 0xee, 0x79, 0xcb, 0x60, 0x63, 0x6b, 0x04, 0xc0, 0x50, 0x59, 0x97, 0xd9, 0x5b,
-	0xaf, 0x56, 0xcf, 0x1b, 0xbb, 0x2d, 0xf3, 0x62, 0x39, 0xf7, 0xd6, 0xbe, 0xdd, 0x89, 0x8d, 0xe2,
-	0xe9, 0xdd, 0xff, 0x05, 0x00, 0x00, 0xff, 0xff, 0x58, 0x60, 0x01, 0x63, 0x9a, 0x02, 0x00, 0x00,
+	// 381 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x92, 0xc1, 0xce, 0x93, 0x40,
+	0x14, 0x85, 0x0b, 0xad, 0x14, 0x2e, 0x6d, 0xa1, 0xa3, 0x31, 0x93, 0xc6, 0x05, 0xe9, 0x8a, 0x6a,
+	0x52, 0x63, 0xf5, 0x05, 0x28, 0x69, 0x52, 0x63, 0x0a, 0x84, 0x62, 0x5c, 0x03, 0xde, 0xa8, 0x49,
+	0x5b, 0x46, 0x86, 0x26, 0xf2, 0x72, 0x3e, 0x81, 0x0f, 0x65, 0x80, 0xa9, 0x50, 0xea, 0xea, 0xdf,
+	0xdd, 0x73, 0xf2, 0x9d, 0x59, 0x7c, 0x19, 0x00, 0x86, 0x98, 0xaf, 0x59, 0x9e, 0x15, 0x19, 0x91,
+	0x59, 0xb2, 0x98, 0x20, 0x8b, 0x7f, 0x65, 0xbc, 0x69, 0x16, 0x33, 0x7e, 0x89, 0x19, 0xff, 0x9e,
+	0x15, 0x4d, 0x5e, 0xfe, 0x96, 0x41, 0x0f, 0x10, 0xf3, 0x10, 0x7f, 0x5e, 0x91, 0x17, 0xe4, 0x15,
+	0x8c, 0x8a, 0x92, 0x21, 0x95, 0x2c, 0xc9, 0x9e, 0x6d, 0xd4, 0x35, 0x4b, 0xd6, 0x51, 0xc9, 0x30,
+	0xac, 0x5b, 0xf2, 0x12, 0x14, 0x8e, 0x97, 0xaf, 0x98, 0x53, 0xd9, 0x92, 0x6c, 0x2d, 0x14, 0x89,
+	0x7c, 0x00, 0x8d, 0xe5, 0x18, 0xa7, 0x29, 0xb2, 0x82, 0xea, 0x96, 0x64, 0xeb, 0x9b, 0x17, 0xd5,
+	0x34, 0xb8, 0x95, 0xe2, 0xf9, 0xfd, 0x20, 0x6c, 0x41, 0xf2, 0x06, 0x14, 0x31, 0x99, 0xd4, 0x93,
+	0x79, 0x35, 0x71, 0x7a, 0xbc, 0xd2, 0xc2, 0x69, 0x76, 0x3e, 0xff, 0x28, 0xe8, 0xb4, 0x85, 0xdd,
+	0xba, 0xe9, 0xc0, 0x0d, 0x52, 0xc1, 0x09, 0xc6, 0x69, 0x76, 0xa1, 0xb3, 0x16, 0xde, 0xd6, 0x4d,
+	0x07, 0x6e, 0x10, 0xf2, 0x0e, 0xd4, 0x9b, 0x14, 0x6a, 0xd4, 0xf8, 0xf3, 0x0a, 0x3f, 0x8a, 0xae,
+	0x1d, 0xfc, 0xc3, 0xb6, 0x1a, 0x8c, 0xcf, 0xc8, 0x79, 0xfc, 0x0d, 0x97, 0x7f, 0x64, 0xd0, 0x1a,
+	0x81, 0xec, 0x54, 0x3e, 0x51, 0x1f, 0x85, 0x31, 0xbf, 0xa6, 0x29, 0x72, 0x4e, 0x87, 0x96, 0x64,
+	0xab, 0xe1, 0x2d, 0x92, 0xcd, 0xa3, 0x58, 0xd2, 0x13, 0xcb, 0x4e, 0xe5, 0xbd, 0xd6, 0x55, 0x4f,
+	0xab, 0xd1, 0xd5, 0xda, 0xd0, 0x4a, 0x8b, 0xde, 0x49, 0x35, 0xba, 0x52, 0x05, 0x2a, 0x94, 0xae,
+	0x7a, 0x4a, 0x8d, 0xae, 0x52, 0x81, 0x0a, 0xa1, 0x6f, 0x1f, 0x84, 0xce, 0xef, 0x85, 0x36, 0xf8,
+	0xff, 0x74, 0xbe, 0x8e, 0x60, 0x54, 0x09, 0x23, 0x3a, 0x8c, 0x3f, 0x7b, 0x9f, 0x3c, 0xff, 0x8b,
+	0x67, 0x0e, 0xc8, 0x14, 0xb4, 0x20, 0xdc, 0x39, 0xae, 0xbb, 0x0b, 0x22, 0x53, 0x22, 0x00, 0x8a,
+	0xb8, 0xe5, 0xea, 0x76, 0xfd, 0xc3, 0xe1, 0x63, 0x64, 0x0e, 0xab, 0x7b, 0xbb, 0x73, 0x5c, 0xdf,
+	0x33, 0x47, 0x64, 0x02, 0xea, 0xd1, 0x73, 0x82, 0xe3, 0xde, 0x8f, 0xcc, 0x67, 0x89, 0x52, 0x7f,
+	0xf6, 0xf7, 0x7f, 0x07, 0x00, 0xf0, 0x92, 0x92, 0x97, 0x1c, 0x03, 0x00, 0x00,
 }
@@ -25,16 +25,22 @@ const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 func init() { proto.RegisterFile("service.proto", fileDescriptor_a0b84a42fa06f626) }
 
 var fileDescriptor_a0b84a42fa06f626 = []byte{
-	// 141 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x2d, 0x4e, 0x2d, 0x2a,
-	0xcb, 0x4c, 0x4e, 0xd5, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x2a, 0x48, 0x92, 0xe2, 0x49,
-	0xce, 0xc9, 0x4c, 0xcd, 0x2b, 0x81, 0x88, 0x48, 0x71, 0x15, 0xa4, 0xa6, 0x16, 0x41, 0xd8, 0x46,
-	0x79, 0x5c, 0x6c, 0xae, 0x05, 0x89, 0x15, 0xf9, 0xc5, 0x42, 0x86, 0x5c, 0xec, 0x01, 0x45, 0xf9,
-	0x05, 0xf9, 0xc5, 0xa9, 0x42, 0x42, 0x7a, 0x05, 0x49, 0x7a, 0x50, 0x4e, 0x50, 0x6a, 0x61, 0x69,
-	0x6a, 0x71, 0x89, 0x94, 0x00, 0x8a, 0x58, 0x41, 0x4e, 0xa5, 0x12, 0x83, 0x90, 0x21, 0x17, 0xa7,
-	0x73, 0x7e, 0x5e, 0x71, 0x6a, 0x5e, 0x71, 0x69, 0xb1, 0x10, 0x3f, 0x58, 0x41, 0x6a, 0x6a, 0x11,
-	0x4c, 0x07, 0x2f, 0x42, 0x00, 0xac, 0x5c, 0x83, 0xd1, 0x80, 0x31, 0x89, 0x0d, 0x6c, 0xad, 0x31,
-	0x20, 0x00, 0x00, 0xff, 0xff, 0x6a, 0x76, 0x4c, 0x9c, 0xa5, 0x00, 0x00, 0x00,
+	// 233 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x90, 0xcf, 0x4a, 0x03, 0x31,
+	0x10, 0xc6, 0xbb, 0xc2, 0xae, 0x38, 0xb6, 0x56, 0xe7, 0xb8, 0xc7, 0x9e, 0x14, 0x21, 0xb4, 0xf5,
+	0x09, 0x44, 0x54, 0xbc, 0x89, 0x1e, 0x7a, 0xce, 0xc6, 0x01, 0x17, 0x6a, 0x32, 0x66, 0xb2, 0xa2,
+	0xef, 0xe6, 0xc3, 0x49, 0x92, 0x8d, 0x7f, 0xc0, 0xde, 0xe6, 0xfb, 0xcd, 0x6f, 0x76, 0x3f, 0x02,
+	0x33, 0x21, 0xff, 0xd6, 0x1b, 0x52, 0xec, 0x5d, 0x70, 0xb8, 0xc7, 0x5d, 0x7b, 0xa8, 0x9f, 0x5e,
+	0x7a, 0x9b, 0x41, 0x3b, 0x35, 0xdb, 0x9e, 0x6c, 0x28, 0x89, 0x58, 0xbf, 0x3b, 0x19, 0x13, 0x30,
+	0x91, 0xcf, 0xf3, 0xda, 0x42, 0x73, 0x9d, 0x76, 0xb8, 0x82, 0xfd, 0x7b, 0xef, 0xd8, 0x09, 0x21,
+	0x2a, 0xee, 0xd4, 0x18, 0x1e, 0xe8, 0x75, 0x20, 0x09, 0xed, 0xf1, 0x1f, 0xc6, 0xdb, 0x8f, 0xc5,
+	0x04, 0x57, 0x70, 0x70, 0xe5, 0xac, 0x90, 0x95, 0x41, 0x70, 0x9e, 0x04, 0x22, 0x5f, 0x2e, 0x66,
+	0x3f, 0x20, 0xe9, 0xa7, 0xd5, 0xb2, 0x5a, 0x7f, 0x56, 0x50, 0x5f, 0xc6, 0x9e, 0xa8, 0xa0, 0x79,
+	0x0c, 0x3a, 0x0c, 0x82, 0x27, 0x51, 0xcc, 0x73, 0xb9, 0x9d, 0xff, 0x46, 0xf9, 0x67, 0x67, 0x50,
+	0xdf, 0x50, 0x30, 0xcf, 0x98, 0x9a, 0xa4, 0xb1, 0xd8, 0xd3, 0x48, 0xee, 0xac, 0x04, 0x6d, 0x0d,
+	0x2d, 0x26, 0x78, 0x0e, 0xf5, 0x46, 0x7f, 0xab, 0x1b, 0xbd, 0x5b, 0x5d, 0x56, 0x51, 0xbe, 0xf5,
+	0x9a, 0xff, 0xfb, 0xee, 0x51, 0x24, 0x69, 0x39, 0x96, 0xe8, 0x9a, 0xf4, 0x6a, 0x17, 0x5f, 0x03,
+	0x00, 0xb7, 0x2d, 0x85, 0x52, 0x7f, 0x01, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -174,3 +180,194 @@ var _Epaxos_serviceDesc = grpc.ServiceDesc{
 	},
 	Metadata: "service.proto",
 }
+
+// AdminClient is the client API for Admin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AdminClient interface {
+	Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusReply, error)
+	Fetch(ctx context.Context, in *FetchRequest, opts ...grpc.CallOption) (*Instance, error)
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Admin_WatchClient, error)
+	Graph(ctx context.Context, in *FetchRequest, opts ...grpc.CallOption) (*GraphReply, error)
+}
+
+type adminClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAdminClient(cc *grpc.ClientConn) AdminClient {
+	return &adminClient{cc}
+}
+
+func (c *adminClient) Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusReply, error) {
+	out := new(StatusReply)
+	err := c.cc.Invoke(ctx, "/pb.Admin/Status", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Fetch(ctx context.Context, in *FetchRequest, opts ...grpc.CallOption) (*Instance, error) {
+	out := new(Instance)
+	err := c.cc.Invoke(ctx, "/pb.Admin/Fetch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Admin_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Admin_serviceDesc.Streams[0], "/pb.Admin/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &adminWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Admin_WatchClient interface {
+	Recv() (*Instance, error)
+	grpc.ClientStream
+}
+
+type adminWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *adminWatchClient) Recv() (*Instance, error) {
+	m := new(Instance)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *adminClient) Graph(ctx context.Context, in *FetchRequest, opts ...grpc.CallOption) (*GraphReply, error) {
+	out := new(GraphReply)
+	err := c.cc.Invoke(ctx, "/pb.Admin/Graph", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServer is the server API for Admin service.
+type AdminServer interface {
+	Status(context.Context, *StatusRequest) (*StatusReply, error)
+	Fetch(context.Context, *FetchRequest) (*Instance, error)
+	Watch(*WatchRequest, Admin_WatchServer) error
+	Graph(context.Context, *FetchRequest) (*GraphReply, error)
+}
+
+func RegisterAdminServer(s *grpc.Server, srv AdminServer) {
+	s.RegisterService(&_Admin_serviceDesc, srv)
+}
+
+func _Admin_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Admin/Status",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Status(ctx, req.(*StatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Fetch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Fetch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Admin/Fetch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Fetch(ctx, req.(*FetchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AdminServer).Watch(m, &adminWatchServer{stream})
+}
+
+type Admin_WatchServer interface {
+	Send(*Instance) error
+	grpc.ServerStream
+}
+
+type adminWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *adminWatchServer) Send(m *Instance) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Admin_Graph_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Graph(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Admin/Graph",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Graph(ctx, req.(*FetchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Admin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.Admin",
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Status",
+			Handler:    _Admin_Status_Handler,
+		},
+		{
+			MethodName: "Fetch",
+			Handler:    _Admin_Fetch_Handler,
+		},
+		{
+			MethodName: "Graph",
+			Handler:    _Admin_Graph_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _Admin_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "service.proto",
+}
@@ -0,0 +1,440 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: snapshot.proto
+
+package pb
+
+import (
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+// A snapshot of the key/value store and client sessions after executing every instance
+// below the executed frontier of each replica's log.
+type Snapshot struct {
+	Executed             map[uint32]uint64 `protobuf:"bytes,1,rep,name=executed,proto3" json:"executed,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	Sequence             uint64            `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Revision             uint64            `protobuf:"varint,3,opt,name=revision,proto3" json:"revision,omitempty"`
+	Data                 []*KeyValue       `protobuf:"bytes,4,rep,name=data,proto3" json:"data,omitempty"`
+	Sessions             []*Session        `protobuf:"bytes,5,rep,name=sessions,proto3" json:"sessions,omitempty"`
+	Epoch                uint64            `protobuf:"varint,6,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	Peers                []byte            `protobuf:"bytes,7,opt,name=peers,proto3" json:"peers,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *Snapshot) Reset()         { *m = Snapshot{} }
+func (m *Snapshot) String() string { return proto.CompactTextString(m) }
+func (*Snapshot) ProtoMessage()    {}
+func (*Snapshot) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c8aab8e59648e0b, []int{0}
+}
+
+func (m *Snapshot) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Snapshot.Unmarshal(m, b)
+}
+func (m *Snapshot) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Snapshot.Marshal(b, m, deterministic)
+}
+func (m *Snapshot) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Snapshot.Merge(m, src)
+}
+func (m *Snapshot) XXX_Size() int {
+	return xxx_messageInfo_Snapshot.Size(m)
+}
+func (m *Snapshot) XXX_DiscardUnknown() {
+	xxx_messageInfo_Snapshot.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Snapshot proto.InternalMessageInfo
+
+func (m *Snapshot) GetExecuted() map[uint32]uint64 {
+	if m != nil {
+		return m.Executed
+	}
+	return nil
+}
+
+func (m *Snapshot) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *Snapshot) GetRevision() uint64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+func (m *Snapshot) GetData() []*KeyValue {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *Snapshot) GetSessions() []*Session {
+	if m != nil {
+		return m.Sessions
+	}
+	return nil
+}
+
+func (m *Snapshot) GetEpoch() uint64 {
+	if m != nil {
+		return m.Epoch
+	}
+	return 0
+}
+
+func (m *Snapshot) GetPeers() []byte {
+	if m != nil {
+		return m.Peers
+	}
+	return nil
+}
+
+// A key in the store with its value and the revision it was last modified at.
+type KeyValue struct {
+	Key                  string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value                []byte   `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Version              uint64   `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *KeyValue) Reset()         { *m = KeyValue{} }
+func (m *KeyValue) String() string { return proto.CompactTextString(m) }
+func (*KeyValue) ProtoMessage()    {}
+func (*KeyValue) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c8aab8e59648e0b, []int{1}
+}
+
+func (m *KeyValue) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeyValue.Unmarshal(m, b)
+}
+func (m *KeyValue) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeyValue.Marshal(b, m, deterministic)
+}
+func (m *KeyValue) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeyValue.Merge(m, src)
+}
+func (m *KeyValue) XXX_Size() int {
+	return xxx_messageInfo_KeyValue.Size(m)
+}
+func (m *KeyValue) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeyValue.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeyValue proto.InternalMessageInfo
+
+func (m *KeyValue) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *KeyValue) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *KeyValue) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+// The cached results of a client session used to deduplicate requests.
+type Session struct {
+	Client               string                   `protobuf:"bytes,1,opt,name=client,proto3" json:"client,omitempty"`
+	Latest               uint64                   `protobuf:"varint,2,opt,name=latest,proto3" json:"latest,omitempty"`
+	Results              map[uint64]*ProposeReply `protobuf:"bytes,4,rep,name=results,proto3" json:"results,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}                 `json:"-"`
+	XXX_unrecognized     []byte                   `json:"-"`
+	XXX_sizecache        int32                    `json:"-"`
+}
+
+func (m *Session) Reset()         { *m = Session{} }
+func (m *Session) String() string { return proto.CompactTextString(m) }
+func (*Session) ProtoMessage()    {}
+func (*Session) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c8aab8e59648e0b, []int{2}
+}
+
+func (m *Session) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Session.Unmarshal(m, b)
+}
+func (m *Session) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Session.Marshal(b, m, deterministic)
+}
+func (m *Session) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Session.Merge(m, src)
+}
+func (m *Session) XXX_Size() int {
+	return xxx_messageInfo_Session.Size(m)
+}
+func (m *Session) XXX_DiscardUnknown() {
+	xxx_messageInfo_Session.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Session proto.InternalMessageInfo
+
+func (m *Session) GetClient() string {
+	if m != nil {
+		return m.Client
+	}
+	return ""
+}
+
+func (m *Session) GetLatest() uint64 {
+	if m != nil {
+		return m.Latest
+	}
+	return 0
+}
+
+func (m *Session) GetResults() map[uint64]*ProposeReply {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+// A snapshot with the instances of each replica's log that have not been executed at
+// the snapshot, which is transferred to a replica that cannot catch up from the log.
+type Transfer struct {
+	Snapshot             *Snapshot   `protobuf:"bytes,1,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	Tail                 []*Instance `protobuf:"bytes,2,rep,name=tail,proto3" json:"tail,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *Transfer) Reset()         { *m = Transfer{} }
+func (m *Transfer) String() string { return proto.CompactTextString(m) }
+func (*Transfer) ProtoMessage()    {}
+func (*Transfer) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c8aab8e59648e0b, []int{3}
+}
+
+func (m *Transfer) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Transfer.Unmarshal(m, b)
+}
+func (m *Transfer) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Transfer.Marshal(b, m, deterministic)
+}
+func (m *Transfer) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Transfer.Merge(m, src)
+}
+func (m *Transfer) XXX_Size() int {
+	return xxx_messageInfo_Transfer.Size(m)
+}
+func (m *Transfer) XXX_DiscardUnknown() {
+	xxx_messageInfo_Transfer.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Transfer proto.InternalMessageInfo
+
+func (m *Transfer) GetSnapshot() *Snapshot {
+	if m != nil {
+		return m.Snapshot
+	}
+	return nil
+}
+
+func (m *Transfer) GetTail() []*Instance {
+	if m != nil {
+		return m.Tail
+	}
+	return nil
+}
+
+// A chunk of a marshaled transfer sent to a replica that has fallen behind the log.
+type SnapshotRequest struct {
+	Offset               uint64   `protobuf:"varint,1,opt,name=offset,proto3" json:"offset,omitempty"`
+	Size                 uint64   `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	Data                 []byte   `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SnapshotRequest) Reset()         { *m = SnapshotRequest{} }
+func (m *SnapshotRequest) String() string { return proto.CompactTextString(m) }
+func (*SnapshotRequest) ProtoMessage()    {}
+func (*SnapshotRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c8aab8e59648e0b, []int{4}
+}
+
+func (m *SnapshotRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SnapshotRequest.Unmarshal(m, b)
+}
+func (m *SnapshotRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SnapshotRequest.Marshal(b, m, deterministic)
+}
+func (m *SnapshotRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SnapshotRequest.Merge(m, src)
+}
+func (m *SnapshotRequest) XXX_Size() int {
+	return xxx_messageInfo_SnapshotRequest.Size(m)
+}
+func (m *SnapshotRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SnapshotRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SnapshotRequest proto.InternalMessageInfo
+
+func (m *SnapshotRequest) GetOffset() uint64 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+func (m *SnapshotRequest) GetSize() uint64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *SnapshotRequest) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+// Reply to a chunk of a transfer, installing the transfer after the last chunk.
+type SnapshotReply struct {
+	Offset               uint64            `protobuf:"varint,1,opt,name=offset,proto3" json:"offset,omitempty"`
+	Installed            bool              `protobuf:"varint,2,opt,name=installed,proto3" json:"installed,omitempty"`
+	Error                string            `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Executed             map[uint32]uint64 `protobuf:"bytes,4,rep,name=executed,proto3" json:"executed,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *SnapshotReply) Reset()         { *m = SnapshotReply{} }
+func (m *SnapshotReply) String() string { return proto.CompactTextString(m) }
+func (*SnapshotReply) ProtoMessage()    {}
+func (*SnapshotReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0c8aab8e59648e0b, []int{5}
+}
+
+func (m *SnapshotReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SnapshotReply.Unmarshal(m, b)
+}
+func (m *SnapshotReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SnapshotReply.Marshal(b, m, deterministic)
+}
+func (m *SnapshotReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SnapshotReply.Merge(m, src)
+}
+func (m *SnapshotReply) XXX_Size() int {
+	return xxx_messageInfo_SnapshotReply.Size(m)
+}
+func (m *SnapshotReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_SnapshotReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SnapshotReply proto.InternalMessageInfo
+
+func (m *SnapshotReply) GetOffset() uint64 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+func (m *SnapshotReply) GetInstalled() bool {
+	if m != nil {
+		return m.Installed
+	}
+	return false
+}
+
+func (m *SnapshotReply) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *SnapshotReply) GetExecuted() map[uint32]uint64 {
+	if m != nil {
+		return m.Executed
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Snapshot)(nil), "pb.Snapshot")
+	proto.RegisterMapType((map[uint32]uint64)(nil), "pb.Snapshot.ExecutedEntry")
+	proto.RegisterType((*KeyValue)(nil), "pb.KeyValue")
+	proto.RegisterType((*Session)(nil), "pb.Session")
+	proto.RegisterMapType((map[uint64]*ProposeReply)(nil), "pb.Session.ResultsEntry")
+	proto.RegisterType((*Transfer)(nil), "pb.Transfer")
+	proto.RegisterType((*SnapshotRequest)(nil), "pb.SnapshotRequest")
+	proto.RegisterType((*SnapshotReply)(nil), "pb.SnapshotReply")
+	proto.RegisterMapType((map[uint32]uint64)(nil), "pb.SnapshotReply.ExecutedEntry")
+}
+
+func init() { proto.RegisterFile("snapshot.proto", fileDescriptor_0c8aab8e59648e0b) }
+
+var fileDescriptor_0c8aab8e59648e0b = []byte{
+	// 482 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x53, 0xcd, 0x8e, 0xd3, 0x30,
+	0x10, 0x56, 0x7e, 0x76, 0x9b, 0x4e, 0x53, 0xa8, 0x2c, 0x84, 0xac, 0x08, 0x89, 0x28, 0x07, 0xc8,
+	0x29, 0x87, 0x22, 0x21, 0xc4, 0x9e, 0xf7, 0x00, 0xec, 0x01, 0x0c, 0xda, 0x7b, 0x9a, 0x4e, 0xb5,
+	0x11, 0x51, 0x6c, 0x6c, 0xb7, 0xda, 0xf0, 0x50, 0xbc, 0x03, 0x0f, 0xc1, 0xfb, 0x20, 0xff, 0x24,
+	0xcd, 0x4a, 0xec, 0x89, 0x9b, 0xbf, 0xf9, 0x3c, 0x9f, 0x67, 0xe6, 0x1b, 0xc3, 0x13, 0xd5, 0xd7,
+	0x42, 0xdd, 0x71, 0x5d, 0x09, 0xc9, 0x35, 0x27, 0xa1, 0xd8, 0x65, 0x69, 0xd3, 0xb5, 0xd8, 0xfb,
+	0x48, 0x96, 0xa2, 0xa8, 0xef, 0xb9, 0x72, 0xa8, 0xf8, 0x15, 0x42, 0xf2, 0xd5, 0xa7, 0x90, 0xb7,
+	0x90, 0xe0, 0x3d, 0x36, 0x47, 0x8d, 0x7b, 0x1a, 0xe4, 0x51, 0xb9, 0xda, 0x66, 0x95, 0xd8, 0x55,
+	0x23, 0x5f, 0x5d, 0x7b, 0xf2, 0xba, 0xd7, 0x72, 0x60, 0xd3, 0x5d, 0x92, 0x41, 0xa2, 0xf0, 0xc7,
+	0x11, 0xfb, 0x06, 0x69, 0x98, 0x07, 0x65, 0xcc, 0x26, 0x6c, 0x38, 0x89, 0xa7, 0x56, 0xb5, 0xbc,
+	0xa7, 0x91, 0xe3, 0x46, 0x4c, 0x72, 0x88, 0xf7, 0xb5, 0xae, 0x69, 0x6c, 0xdf, 0x4a, 0xcd, 0x5b,
+	0x9f, 0x70, 0xb8, 0xad, 0xbb, 0x23, 0x32, 0xcb, 0x90, 0xd7, 0x46, 0x59, 0x99, 0xcb, 0x8a, 0x5e,
+	0xd8, 0x5b, 0x2b, 0x5b, 0x91, 0x8b, 0xb1, 0x89, 0x24, 0xcf, 0xe0, 0x02, 0x05, 0x6f, 0xee, 0xe8,
+	0xa5, 0x7d, 0xc3, 0x01, 0x13, 0x15, 0x88, 0x52, 0xd1, 0x45, 0x1e, 0x94, 0x29, 0x73, 0x20, 0xbb,
+	0x82, 0xf5, 0x83, 0x4e, 0xc8, 0x06, 0xa2, 0xef, 0x38, 0xd0, 0x20, 0x0f, 0xca, 0x35, 0x33, 0x47,
+	0x93, 0x78, 0x32, 0x65, 0xf8, 0x76, 0x1c, 0x78, 0x1f, 0xbe, 0x0b, 0x8a, 0x1b, 0x48, 0xc6, 0x1a,
+	0xe7, 0x79, 0xcb, 0x7f, 0xe4, 0xa5, 0x3e, 0x8f, 0x50, 0x58, 0x9c, 0x50, 0xce, 0x46, 0x30, 0xc2,
+	0xe2, 0x77, 0x00, 0x0b, 0xdf, 0x0c, 0x79, 0x0e, 0x97, 0xce, 0x28, 0x2f, 0xe8, 0x91, 0x89, 0x77,
+	0xb5, 0x46, 0xa5, 0x7d, 0x31, 0x1e, 0x91, 0x2d, 0x2c, 0x24, 0xaa, 0x63, 0xa7, 0x95, 0x1f, 0x20,
+	0x9d, 0x8d, 0xa6, 0x62, 0x8e, 0x72, 0x56, 0x8d, 0x17, 0xb3, 0x1b, 0x48, 0xe7, 0xc4, 0xbc, 0x83,
+	0xd8, 0x75, 0xf0, 0x6a, 0xde, 0xc1, 0x6a, 0xbb, 0x31, 0x9a, 0x9f, 0x25, 0x17, 0x5c, 0x21, 0x43,
+	0xd1, 0x0d, 0xb3, 0x59, 0x7c, 0x8c, 0x93, 0x68, 0x13, 0x17, 0xb7, 0x90, 0x7c, 0x93, 0x75, 0xaf,
+	0x0e, 0x28, 0x49, 0x09, 0xc9, 0xb8, 0x80, 0x56, 0xd4, 0xbb, 0x3a, 0x6e, 0x10, 0x9b, 0x58, 0xe3,
+	0xbd, 0xae, 0xdb, 0x8e, 0x86, 0x67, 0xef, 0x3f, 0xf4, 0x4a, 0xd7, 0x7d, 0x83, 0xcc, 0x32, 0xc5,
+	0x17, 0x78, 0x3a, 0xe5, 0x99, 0x6d, 0x52, 0x76, 0x14, 0xfc, 0x70, 0x50, 0xa8, 0x7d, 0xc5, 0x1e,
+	0x11, 0x02, 0xb1, 0x6a, 0x7f, 0x8e, 0x6e, 0xd9, 0xb3, 0x89, 0xd9, 0xe5, 0x8a, 0xac, 0x13, 0xf6,
+	0x5c, 0xfc, 0x09, 0x60, 0x7d, 0xd6, 0x14, 0xdd, 0xf0, 0xa8, 0xe2, 0x0b, 0x58, 0xb6, 0xa6, 0x9c,
+	0xae, 0xc3, 0xbd, 0x95, 0x4d, 0xd8, 0x39, 0x60, 0xb7, 0x4d, 0x4a, 0x2e, 0xad, 0xf8, 0x92, 0x39,
+	0x40, 0xae, 0x66, 0xdf, 0xc7, 0x39, 0xf2, 0xf2, 0x41, 0xf3, 0xe6, 0xc1, 0xc7, 0xfe, 0xd0, 0x7f,
+	0x2d, 0xe5, 0xee, 0xd2, 0x7e, 0xe6, 0x37, 0x7f, 0x07, 0x00, 0xc3, 0x7a, 0x85, 0x3c, 0xfe, 0x03,
+	0x00, 0x00,
+}
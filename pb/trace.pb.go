@@ -0,0 +1,156 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: trace.proto
+
+package pb
+
+import (
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+// A single event handled by the replica, written to a trace file in the order that
+// the events were handled so that the events can be replayed offline.
+type TraceRecord struct {
+	Type                 uint32   `protobuf:"varint,1,opt,name=type,proto3" json:"type,omitempty"`
+	Peer                 string   `protobuf:"bytes,2,opt,name=peer,proto3" json:"peer,omitempty"`
+	Time                 int64    `protobuf:"varint,3,opt,name=time,proto3" json:"time,omitempty"`
+	Value                []byte   `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TraceRecord) Reset()         { *m = TraceRecord{} }
+func (m *TraceRecord) String() string { return proto.CompactTextString(m) }
+func (*TraceRecord) ProtoMessage()    {}
+func (*TraceRecord) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0571941a1d628a80, []int{0}
+}
+
+func (m *TraceRecord) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TraceRecord.Unmarshal(m, b)
+}
+func (m *TraceRecord) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TraceRecord.Marshal(b, m, deterministic)
+}
+func (m *TraceRecord) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TraceRecord.Merge(m, src)
+}
+func (m *TraceRecord) XXX_Size() int {
+	return xxx_messageInfo_TraceRecord.Size(m)
+}
+func (m *TraceRecord) XXX_DiscardUnknown() {
+	xxx_messageInfo_TraceRecord.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TraceRecord proto.InternalMessageInfo
+
+func (m *TraceRecord) GetType() uint32 {
+	if m != nil {
+		return m.Type
+	}
+	return 0
+}
+
+func (m *TraceRecord) GetPeer() string {
+	if m != nil {
+		return m.Peer
+	}
+	return ""
+}
+
+func (m *TraceRecord) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *TraceRecord) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+// Resume a protocol phase when a pause ends. The generation identifies the pause so that
+// the phase is not resumed by the timer of a pause that has since been replaced.
+type Resume struct {
+	Phase                string   `protobuf:"bytes,1,opt,name=phase,proto3" json:"phase,omitempty"`
+	Generation           uint64   `protobuf:"varint,2,opt,name=generation,proto3" json:"generation,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Resume) Reset()         { *m = Resume{} }
+func (m *Resume) String() string { return proto.CompactTextString(m) }
+func (*Resume) ProtoMessage()    {}
+func (*Resume) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0571941a1d628a80, []int{1}
+}
+
+func (m *Resume) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Resume.Unmarshal(m, b)
+}
+func (m *Resume) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Resume.Marshal(b, m, deterministic)
+}
+func (m *Resume) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Resume.Merge(m, src)
+}
+func (m *Resume) XXX_Size() int {
+	return xxx_messageInfo_Resume.Size(m)
+}
+func (m *Resume) XXX_DiscardUnknown() {
+	xxx_messageInfo_Resume.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Resume proto.InternalMessageInfo
+
+func (m *Resume) GetPhase() string {
+	if m != nil {
+		return m.Phase
+	}
+	return ""
+}
+
+func (m *Resume) GetGeneration() uint64 {
+	if m != nil {
+		return m.Generation
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*TraceRecord)(nil), "pb.TraceRecord")
+	proto.RegisterType((*Resume)(nil), "pb.Resume")
+}
+
+func init() { proto.RegisterFile("trace.proto", fileDescriptor_0571941a1d628a80) }
+
+var fileDescriptor_0571941a1d628a80 = []byte{
+	// 158 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x4c, 0x8e, 0xb1, 0xae, 0xc2, 0x30,
+	0x0c, 0x45, 0x95, 0x36, 0xaf, 0x52, 0xdd, 0xc7, 0x12, 0x31, 0x64, 0x42, 0x51, 0xa7, 0x4c, 0x2c,
+	0xec, 0x7c, 0x84, 0xc5, 0x8e, 0xd2, 0x62, 0x41, 0x25, 0x68, 0x22, 0x37, 0x45, 0xe2, 0xef, 0x51,
+	0x9d, 0x85, 0xed, 0xdc, 0x63, 0xcb, 0xbe, 0xd0, 0x65, 0x0e, 0x23, 0x1d, 0x13, 0xc7, 0x1c, 0x4d,
+	0x95, 0x86, 0xfe, 0x0a, 0xdd, 0x65, 0x53, 0x48, 0x63, 0xe4, 0x9b, 0x31, 0xa0, 0xf3, 0x27, 0x91,
+	0x55, 0x4e, 0xf9, 0x1d, 0x0a, 0x6f, 0x2e, 0x11, 0xb1, 0xad, 0x9c, 0xf2, 0x2d, 0x0a, 0xcb, 0xde,
+	0xf4, 0x22, 0x5b, 0x3b, 0xe5, 0x6b, 0x14, 0x36, 0x7b, 0xf8, 0x7b, 0x87, 0xe7, 0x4a, 0x56, 0x3b,
+	0xe5, 0xff, 0xb1, 0x84, 0xfe, 0x0c, 0x0d, 0xd2, 0xb2, 0x96, 0x79, 0x7a, 0x84, 0xa5, 0x1c, 0x6f,
+	0xb1, 0x04, 0x73, 0x00, 0xb8, 0xd3, 0x4c, 0x1c, 0xf2, 0x14, 0x67, 0xf9, 0xa1, 0xf1, 0xc7, 0x0c,
+	0x8d, 0x74, 0x3d, 0x7d, 0x07, 0x00, 0x53, 0xc4, 0x80, 0xfe, 0xba, 0x00, 0x00, 0x00,
+}
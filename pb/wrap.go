@@ -95,3 +95,26 @@ func WrapBeaconReply(sender string, msg *BeaconReply) *PeerReply {
 		},
 	}
 }
+
+// WrapSnapshotRequest in a PeerRequest for transmission on a single streaming channel.
+func WrapSnapshotRequest(sender string, msg *SnapshotRequest) *PeerRequest {
+	return &PeerRequest{
+		Type:   Type_SNAPSHOT,
+		Sender: sender,
+		Message: &PeerRequest_Snapshot{
+			Snapshot: msg,
+		},
+	}
+}
+
+// WrapSnapshotReply in a PeerReply for transmission on a single streaming channel.
+func WrapSnapshotReply(sender string, msg *SnapshotReply) *PeerReply {
+	return &PeerReply{
+		Type:    Type_SNAPSHOT,
+		Sender:  sender,
+		Success: true,
+		Message: &PeerReply_Snapshot{
+			Snapshot: msg,
+		},
+	}
+}
@@ -0,0 +1,199 @@
+package epaxos
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fatih/structs"
+)
+
+// ConfigPollInterval is how often the configuration file is checked for changes.
+const ConfigPollInterval = time.Second
+
+// Configuration fields that can be changed while the replica is running; changes to
+// any other field require the replica to be restarted.
+var reloadable = map[string]bool{
+	"Timeout":  true,
+	"LogLevel": true,
+	"Thrifty":  true,
+}
+
+//===========================================================================
+// Configuration Reloading
+//===========================================================================
+
+// Reload the configuration from the configuration file the replica was created with
+// and the environment, then apply the changes to the replica. The options the replica
+// was created with are applied to the settings that cannot be reloaded, so that the
+// reloadable settings are read from the file even if they were passed as options.
+func (r *Replica) Reload() error {
+	conf := &Config{Path: r.config.Path}
+	if err := conf.Load(); err != nil {
+		return err
+	}
+
+	if r.options != nil {
+		options := *r.options
+		fields := structs.New(&options)
+		for name := range reloadable {
+			fields.Field(name).Zero()
+		}
+
+		if err := conf.Update(&options); err != nil {
+			return err
+		}
+	}
+
+	return r.ReloadConfig(conf)
+}
+
+// ReloadConfig validates the configuration and applies its changes to the replica in
+// the event loop. Only the timeout, log level and thrifty settings can be changed at
+// runtime; if any other setting has changed, e.g. the peers, an error is returned and
+// none of the changes are applied. The peers are changed by membership changes once
+// the replica is running, so the configuration may list either the replicas the
+// replica was started with or the replicas of the current epoch.
+func (r *Replica) ReloadConfig(conf *Config) error {
+	if err := conf.Validate(); err != nil {
+		return err
+	}
+
+	r.members.RLock()
+	current := *r.config
+	if reflect.DeepEqual(conf.Peers, r.rosters[0]) {
+		current.Peers = conf.Peers
+	}
+	changed := changedFields(&current, conf)
+	r.members.RUnlock()
+
+	if len(changed) == 0 {
+		return nil
+	}
+
+	rejected := make([]string, 0, len(changed))
+	for _, field := range changed {
+		if !reloadable[field.Name()] {
+			rejected = append(rejected, fieldName(field))
+		}
+	}
+
+	if len(rejected) > 0 {
+		return fmt.Errorf("cannot reload %s without restarting the replica", strings.Join(rejected, ", "))
+	}
+
+	return r.Dispatch(&event{etype: ReloadEvent, value: conf})
+}
+
+// Apply the reloaded configuration to the replica.
+func (r *Replica) onReload(e Event) error {
+	conf := e.Value().(*Config)
+
+	r.members.Lock()
+	r.config.Timeout = conf.Timeout
+	r.config.LogLevel = conf.LogLevel
+	r.config.Thrifty = conf.Thrifty
+	r.members.Unlock()
+
+	r.log.SetLevel(uint8(conf.LogLevel))
+
+	timeout, err := r.config.GetTimeout()
+	if err != nil {
+		return err
+	}
+
+	for _, remote := range r.remotes {
+		remote.SetTimeout(timeout)
+	}
+
+	// Remotes that were not thrifty peers may not be connected yet
+	r.thrifty = r.config.GetThrifty()
+	if r.thrifty == nil {
+		for _, remote := range r.remotes {
+			if !remote.Running() {
+				if err := remote.Connect(); err != nil {
+					r.log.Warn("could not connect to %s: %s", remote.Name, err)
+				}
+			}
+		}
+	}
+
+	r.log.Status("configuration reloaded")
+	return nil
+}
+
+// Reload the configuration when the process receives a SIGHUP or when the modification
+// time of the configuration file changes, until the replica stops listening.
+func (r *Replica) watchConfig() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(ConfigPollInterval)
+	defer ticker.Stop()
+
+	path, _ := r.config.GetPath()
+	modified := modTime(path)
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-hup:
+		case <-ticker.C:
+			if path == "" {
+				continue
+			}
+
+			mtime := modTime(path)
+			if mtime.Equal(modified) {
+				continue
+			}
+			modified = mtime
+		}
+
+		if err := r.Reload(); err != nil {
+			if err == ErrNotListening {
+				return
+			}
+			r.log.Warn("could not reload configuration: %s", err)
+		}
+	}
+}
+
+// Returns the fields of the other configuration whose values differ from the config.
+func changedFields(c, o *Config) []*structs.Field {
+	conf := structs.New(c)
+	changed := make([]*structs.Field, 0)
+	for _, field := range structs.Fields(o) {
+		if !reflect.DeepEqual(conf.Field(field.Name()).Value(), field.Value()) {
+			changed = append(changed, field)
+		}
+	}
+	return changed
+}
+
+// Returns the name of the field as it is written in the configuration file.
+func fieldName(field *structs.Field) string {
+	if name := strings.Split(field.Tag("json"), ",")[0]; name != "" {
+		return name
+	}
+	return field.Name()
+}
+
+// Returns the modification time of the file or the zero time if it cannot be read.
+func modTime(path string) time.Time {
+	if path == "" {
+		return time.Time{}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,177 @@
+package epaxos_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/bbengfort/epaxos"
+	"github.com/bbengfort/x/peers"
+)
+
+var _ = Describe("Reload", func() {
+
+	var network []peers.Peer
+
+	BeforeEach(func() {
+		network = makeNetwork(54264, 3)
+	})
+
+	// Reloaded configurations are loaded the same way as the replica's configuration
+	reloaded := func(options *Config) *Config {
+		conf := new(Config)
+		Ω(conf.Load()).Should(Succeed())
+		Ω(conf.Update(options)).Should(Succeed())
+		return conf
+	}
+
+	It("should reject changes that require a restart", func() {
+		options := &Config{Name: "alpha", Timeout: "500ms", LogLevel: int(LogSilent), Peers: network}
+		replica, err := New(options)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		// Unchanged configurations are not applied
+		Ω(replica.ReloadConfig(reloaded(options))).Should(Succeed())
+
+		conf := reloaded(&Config{Name: "alpha", Timeout: "1s", Thrifty: true, LogLevel: int(LogSilent), Peers: network})
+		Ω(replica.ReloadConfig(conf)).Should(Equal(ErrNotListening))
+
+		conf = reloaded(&Config{Name: "alpha", Timeout: "500ms", LogLevel: int(LogSilent), Peers: network[:2]})
+		Ω(replica.ReloadConfig(conf)).Should(MatchError("cannot reload peers without restarting the replica"))
+
+		conf = reloaded(&Config{Name: "alpha", Timeout: "1s", Aggregate: true, ClusterKey: "secret", LogLevel: int(LogSilent), Peers: network})
+		Ω(replica.ReloadConfig(conf)).Should(MatchError("cannot reload aggregate, cluster_key without restarting the replica"))
+
+		conf = reloaded(&Config{Name: "alpha", Timeout: "500ms", Window: 128, LogLevel: int(LogSilent), Peers: network})
+		Ω(replica.ReloadConfig(conf)).Should(MatchError("cannot reload window without restarting the replica"))
+
+		conf = reloaded(options)
+		conf.Timeout = "soon"
+		Ω(replica.ReloadConfig(conf)).Should(MatchError(ContainSubstring("could not validate Timeout")))
+	})
+
+	It("should reload from the configuration file the replica was created with", func() {
+		tmp, err := ioutil.TempDir("", "epaxos-reload")
+		Ω(err).ShouldNot(HaveOccurred())
+		defer os.RemoveAll(tmp)
+
+		// Write the configuration file and pass its contents as options like the CLI
+		path := filepath.Join(tmp, "config.json")
+		write := func(conf *Config) {
+			data, err := json.Marshal(conf)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(ioutil.WriteFile(path, data, 0644)).Should(Succeed())
+		}
+
+		options := &Config{Name: "alpha", Timeout: "500ms", LogLevel: int(LogSilent), Peers: network}
+		write(options)
+		options.Path = path
+
+		replica, err := New(options)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		sink := &messageSink{}
+		replica.SetLogger(NewLogger(LogSilent, sink))
+		serve(replica)
+
+		// Reloadable settings are read from the file rather than the options; the file
+		// is rewritten until the replica is watching it for changes
+		Eventually(func() []string {
+			write(&Config{Name: "alpha", Timeout: "1s", LogLevel: int(LogStatus), Peers: network})
+			return sink.Messages()
+		}, "5s", "250ms").Should(ContainElement("configuration reloaded"))
+
+		Eventually(func() []string {
+			write(&Config{Name: "alpha", Timeout: "1s", Aggregate: true, LogLevel: int(LogStatus), Peers: network})
+			return sink.Messages()
+		}, "5s", "250ms").Should(ContainElement("could not reload configuration: cannot reload aggregate without restarting the replica"))
+	})
+
+	It("should reload the starting or current peers after a membership change", func() {
+		network = makeNetwork(61264, 3)
+		options := &Config{Name: "alpha", Timeout: "500ms", LogLevel: int(LogSilent), Peers: network}
+		withAdmin(options)
+
+		replica, err := New(options)
+		Ω(err).ShouldNot(HaveOccurred())
+		serve(replica)
+
+		for _, peer := range network[1:] {
+			runReplica(peer.Name, network, withAdmin)
+		}
+
+		client, err := NewClient("alpha", &Config{Timeout: "2s", Token: adminToken, Peers: network[:1]})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(client.RemovePeer("charlie")).Should(Succeed())
+
+		// Unchanged configurations are not applied, so only the peers are compared
+		current := &Config{Name: "alpha", Timeout: "500ms", LogLevel: int(LogSilent), Peers: network[:2]}
+		withAdmin(current)
+		Eventually(func() error {
+			return replica.ReloadConfig(reloaded(current))
+		}).Should(Succeed())
+
+		Ω(replica.ReloadConfig(reloaded(options))).Should(Succeed())
+
+		other := &Config{Name: "alpha", Timeout: "500ms", LogLevel: int(LogSilent), Peers: network[:1]}
+		withAdmin(other)
+		Ω(replica.ReloadConfig(reloaded(other))).Should(MatchError("cannot reload peers without restarting the replica"))
+	})
+
+	It("should reload the configuration on SIGHUP", func() {
+		// The log level is not set so that it can be changed by the environment
+		replica, err := New(&Config{Name: "alpha", Timeout: "500ms", Peers: network})
+		Ω(err).ShouldNot(HaveOccurred())
+
+		sink := &messageSink{}
+		replica.SetLogger(NewLogger(LogCaution, sink))
+		serve(replica)
+
+		// Do not terminate the tests if the replica is not yet handling the signal
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		defer signal.Stop(hup)
+
+		os.Setenv("EPAXOS_LOG_LEVEL", "4")
+		defer os.Unsetenv("EPAXOS_LOG_LEVEL")
+
+		Eventually(func() []string {
+			Ω(syscall.Kill(os.Getpid(), syscall.SIGHUP)).Should(Succeed())
+			return sink.Messages()
+		}).Should(ContainElement("configuration reloaded"))
+
+		os.Setenv("EPAXOS_AGGREGATE", "true")
+		defer os.Unsetenv("EPAXOS_AGGREGATE")
+
+		Eventually(func() []string {
+			Ω(syscall.Kill(os.Getpid(), syscall.SIGHUP)).Should(Succeed())
+			return sink.Messages()
+		}).Should(ContainElement("could not reload configuration: cannot reload aggregate without restarting the replica"))
+	})
+})
+
+// messageSink records the messages of log entries so that they can be inspected.
+type messageSink struct {
+	sync.Mutex
+	messages []string
+}
+
+func (s *messageSink) Write(e *Entry) error {
+	s.Lock()
+	defer s.Unlock()
+	s.messages = append(s.messages, e.Message)
+	return nil
+}
+
+func (s *messageSink) Messages() []string {
+	s.Lock()
+	defer s.Unlock()
+	return append([]string{}, s.messages...)
+}
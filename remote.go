@@ -10,6 +10,7 @@ import (
 	"github.com/bbengfort/epaxos/pb"
 	"github.com/bbengfort/x/peers"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
 // MessageBufferSize represents the number of messages that can be queued to send to the
@@ -22,6 +23,9 @@ type Remote struct {
 	peers.Peer
 
 	sender   string                    // the name of the sender to attach to all messages
+	creds    grpc.DialOption           // transport credentials to connect to the remote
+	auth     *Config                   // signs consensus streams with the cluster key
+	log      Logger                    // logs messages with the context of the remote peer
 	actor    Actor                     // the listener to dispatch events to
 	timeout  time.Duration             // timeout before dropping message
 	conn     *grpc.ClientConn          // grpc dial connection to the remote
@@ -46,7 +50,13 @@ func NewRemote(p peers.Peer, r *Replica) (*Remote, error) {
 		return nil, err
 	}
 
-	remote := &Remote{Peer: p, actor: r, sender: r.Name, timeout: timeout}
+	creds, err := r.config.GetDialOption(p.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	remote := &Remote{Peer: p, actor: r, sender: r.Name, creds: creds, auth: r.config, timeout: timeout}
+	remote.log = r.log.With(Fields{FieldPeer: p.Name})
 	return remote, nil
 }
 
@@ -86,6 +96,21 @@ func (c *Remote) Close() (err error) {
 	return <-c.done
 }
 
+// Running returns true if the messenger of the remote has been started.
+func (c *Remote) Running() bool {
+	c.Lock()
+	defer c.Unlock()
+	return c.messages != nil
+}
+
+// SetTimeout modifies the timeout to connect to the remote, which is used the next
+// time the messenger connects to the remote peer.
+func (c *Remote) SetTimeout(timeout time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	c.timeout = timeout
+}
+
 // Send a message to the remote. This places the message on a buffered channel, which
 // will be sent in the order they are received. The response is dispatched to the actor
 // event listener to be handled in the order responses are received.
@@ -120,39 +145,63 @@ func (c *Remote) messenger() {
 		// If we're not online try to re-establish the connection
 		if !c.online {
 			if err := c.connect(); err != nil {
-				caution("dropped %s message to %s (%s): could not connect", msg.Type, c.Name, c.Endpoint(true))
+				c.log.Caution("dropped %s message: could not connect to %s", msg.Type, c.Endpoint(true))
 				c.close()
 				continue
 			}
 		}
 
-		// Send the peer request message
-		if err := c.stream.Send(msg); err != nil {
-			// go offline if there was an error sending the message
-			caution("dropped %s message to %s (%s): could not send", msg.Type, c.Name, c.Endpoint(true))
-			c.close()
-			continue
-		}
+		c.exchange(msg)
+	}
 
-		// Wait for the peer reply message
-		rep, err := c.stream.Recv()
-		if err != nil {
-			if err != io.EOF {
-				caution(err.Error())
-			} else {
-				caution("stream to %s (%s) closed by remote", c.Name, c.Endpoint(true))
-			}
-			c.stream = nil
-			c.close()
-			continue
-		}
+	// The messages channel has been closed, clean up the connection to the remote
+	c.done <- c.close()
+}
+
+// Send the message on the stream and dispatch the reply to the actor. If the message
+// cannot be sent or the reply cannot be received, the connection is closed so that it
+// is re-established for the next message and an error is returned.
+func (c *Remote) exchange(msg *pb.PeerRequest) error {
+	// Send the peer request message
+	if err := c.stream.Send(msg); err != nil {
+		// go offline if there was an error sending the message
+		c.log.Caution("dropped %s message: could not send to %s", msg.Type, c.Endpoint(true))
+		c.close()
+		return err
+	}
 
-		// Dispatch the event to the replica
-		if err := c.actor.Dispatch(replyEvent(rep)); err != nil {
-			caution("could not dispatch message from %s (%s): %s", c.Name, c.Endpoint(true), err)
+	// Wait for the peer reply message
+	rep, err := c.stream.Recv()
+	if err != nil {
+		if err != io.EOF {
+			c.log.Caution("could not receive reply: %s", err)
+		} else {
+			c.log.Caution("stream to %s closed by remote", c.Endpoint(true))
 		}
+		c.stream = nil
+		c.close()
+		return err
+	}
+
+	// Only accept replies from the remote peer
+	if rep.Sender != c.Name {
+		c.log.Caution("dropped reply from %s: sender does not match remote", rep.Sender)
+		return nil
+	}
+
+	// Replies without a message indicate the remote could not handle the request
+	if !rep.Success {
+		c.log.Caution("%s request was not successful on %s", rep.Type, c.Endpoint(true))
+		return nil
+	}
 
+	// Dispatch the event to the replica
+	e := replyEvent(rep)
+	e.peer = rep.Sender
+	if err := c.actor.Dispatch(e); err != nil {
+		c.log.Caution("could not dispatch message from %s: %s", c.Endpoint(true), err)
 	}
+	return nil
 }
 
 // Connect to the remote using the specified timeout. Connect is usually not explicitly
@@ -165,24 +214,34 @@ func (c *Remote) connect() (err error) {
 
 	addr := c.Endpoint(true)
 
-	if c.conn, err = grpc.Dial(addr, grpc.WithInsecure(), grpc.WithTimeout(c.timeout)); err != nil {
+	c.Lock()
+	timeout := c.timeout
+	c.Unlock()
+
+	if c.conn, err = grpc.Dial(addr, c.creds, grpc.WithTimeout(timeout)); err != nil {
 		return fmt.Errorf("could not connect to '%s': %s", addr, err)
 	}
 
 	// NOTE: do not set online to true until after a response from remote.
 	c.client = pb.NewEpaxosClient(c.conn)
 
+	// Authenticate the stream with the cluster key if TLS is not configured
+	ctx := context.Background()
+	if md := c.auth.peerToken(c.sender, time.Now()); md != nil {
+		ctx = metadata.NewOutgoingContext(ctx, md)
+	}
+
 	// Create the messages stream
-	if c.stream, err = c.client.Consensus(context.Background()); err != nil {
+	if c.stream, err = c.client.Consensus(ctx); err != nil {
 		return fmt.Errorf("could not create peer to peer stream to '%s': %s", addr, err)
 	}
 
-	// Always send beacon when connected to establish link.
-	c.SendBeacon()
-
 	// mark connection as online
 	c.online = true
-	return nil
+
+	// Always send beacon when connected to establish link. The beacon is exchanged
+	// directly rather than queued, since the messages channel may have been closed.
+	return c.exchange(pb.WrapBeaconRequest(c.sender, &pb.BeaconRequest{}))
 }
 
 // Close the connection to the remote and clean up the connection objects.
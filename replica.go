@@ -4,9 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"sync"
+	"time"
 
 	"github.com/bbengfort/epaxos/pb"
 	"github.com/bbengfort/x/peers"
+	"github.com/golang/protobuf/proto"
 	"google.golang.org/grpc"
 )
 
@@ -15,14 +18,38 @@ import (
 type Replica struct {
 	peers.Peer
 
-	quorum  uint32                           // number of replicas required for a quorum
-	logs    *Logs                            // a 2D log of operations to apply to state machine
-	config  *Config                          // the static configuration of the replica
-	events  chan Event                       // serialize events in the system in the order they're received
-	remotes Remotes                          // connections to remote peers to send messages to
-	thrifty []uint32                         // the peers to send broadcast messages to
-	nops    uint64                           // the number of operations recieved (TODO: replace with instances)
-	clients map[uint64]chan *pb.ProposeReply // connected clients awaiting a reply
+	quorum    uint32                                 // number of replicas required for a quorum
+	logs      *Logs                                  // a 2D log of operations to apply to state machine
+	config    *Config                                // the configuration of the replica
+	options   *Config                                // the options the replica was created with
+	events    chan Event                             // serialize events in the system in the order they're received
+	done      chan struct{}                          // closed when the replica stops handling events
+	stop      sync.Once                              // ensures the done channel is closed once
+	remotes   Remotes                                // connections to remote peers to send messages to
+	thrifty   []uint32                               // the peers to send broadcast messages to
+	nops      uint64                                 // the number of operations recieved (TODO: replace with instances)
+	clients   map[uint64]chan *pb.ProposeReply       // connected clients awaiting a reply
+	watchers  map[chan *pb.Instance]*pb.WatchRequest // admin clients watching for instance state changes
+	recorder  *Recorder                              // records handled events if tracing is enabled
+	log       Logger                                 // structured logger with the replica's context
+	authn     Authenticator                          // identifies clients that propose operations
+	authz     Authorizer                             // allows or denies operations proposed by clients
+	quotas    *quotas                                // limits the rate of proposals by each client
+	store     *store                                 // the key/value state that instances are executed on
+	sessions  *sessions                              // client sessions to deduplicate requests on execution
+	pauses    map[string]*pause                      // protocol phases delayed by PAUSE operations
+	npauses   uint64                                 // the number of PAUSE operations applied to the replica
+	snapshot  *pb.Snapshot                           // the latest snapshot of the state machine
+	frontiers map[uint32]map[uint32]uint64           // the executed frontier last reported by each peer
+	transfers map[uint32]time.Time                   // when the latest snapshot transfer to each peer started
+	installs  map[string][]byte                      // the chunks of snapshot transfers received from each peer
+	epoch     uint64                                 // the number of membership changes executed
+	quorums   map[uint64]uint32                      // the quorum size of each membership epoch
+	rosters   map[uint64][]peers.Peer                // the replicas of each membership epoch
+	pending   uint64                                 // the oldest epoch with instances that have not been committed
+	votes     map[uint64]map[string]bool             // the replicas that voted for the phase of each instance led by this replica
+	joining   []uint32                               // replicas added by this replica awaiting the state
+	members   sync.RWMutex                           // guards the peers of the config, the rosters and the pending epoch
 }
 
 // Listen for messages from peers and clients and run the event loop.
@@ -34,21 +61,53 @@ func (r *Replica) Listen() error {
 		return fmt.Errorf("could not listen on %s", addr)
 	}
 	defer sock.Close()
-	info("listening for requests on %s", addr)
+	r.log.Info("listening for requests on %s", addr)
 
 	// Create the events channel
 	r.events = make(chan Event, actorEventBufferSize)
 
+	// Open the trace file to record handled events if configured
+	if r.config.Trace != "" {
+		if r.recorder, err = NewRecorder(r.config.Trace); err != nil {
+			return err
+		}
+		r.log.Info("recording handled events to %s", r.config.Trace)
+	}
+
 	// Initialize and run the gRPC server in its own thread
-	srv := grpc.NewServer()
+	opts, err := r.config.GetServerOptions()
+	if err != nil {
+		return err
+	}
+
+	if len(opts) == 0 {
+		r.log.Status("TLS is not configured, connections are not encrypted or authenticated")
+	}
+
+	srv := grpc.NewServer(opts...)
 	pb.RegisterEpaxosServer(srv, r)
+	pb.RegisterAdminServer(srv, r)
 	go srv.Serve(sock)
+	defer r.shutdown(srv)
 
 	// Open up connections to remote peers
 	if err := r.Connect(); err != nil {
 		return err
 	}
 
+	// Periodically snapshot the state machine and truncate the log
+	interval, err := r.config.GetSnapshotInterval()
+	if err != nil {
+		return err
+	}
+
+	if interval > 0 {
+		go r.runSnapshots(interval)
+	}
+
+	// Reload the configuration when it changes or on SIGHUP
+	go r.watchConfig()
+
 	// Run the event handling loop
 	if r.config.Aggregate {
 		if err := r.runAggregatingEventLoop(); err != nil {
@@ -63,29 +122,62 @@ func (r *Replica) Listen() error {
 	return nil
 }
 
-// Close the event handler and stop listening for events.
-// TODO: gracefully shutdown the grpc server as well.
+// Close the event handler and stop listening for events. Listen returns once the event
+// loop has stopped, the server is stopped and the connections to peers are closed. If
+// the replica is closed before it is listening, Listen returns immediately.
 func (r *Replica) Close() error {
-	if r.events == nil {
-		return ErrNotListening
-	}
-	close(r.events)
+	r.stop.Do(func() { close(r.done) })
 	return nil
 }
 
-// Dispatch events by clients to the replica.
+// Stop serving requests from clients and peers and close the connections to the remote
+// peers once the replica no longer handles events. The remotes are only closed after
+// the event loop has stopped since messages are sent to them by the event handlers.
+func (r *Replica) shutdown(srv *grpc.Server) {
+	r.stop.Do(func() { close(r.done) })
+	srv.Stop()
+
+	for _, remote := range r.remotes {
+		if remote.Running() {
+			if err := remote.Close(); err != nil {
+				r.log.Warne(err)
+			}
+		}
+	}
+}
+
+// Dispatch events by clients to the replica. Once the replica has stopped handling
+// events, ErrNotListening is returned rather than blocking on the events channel.
 func (r *Replica) Dispatch(e Event) error {
 	if r.events == nil {
 		return ErrNotListening
 	}
 
-	r.events <- e
-	return nil
+	select {
+	case <-r.done:
+		return ErrNotListening
+	default:
+	}
+
+	select {
+	case r.events <- e:
+		return nil
+	case <-r.done:
+		return ErrNotListening
+	}
 }
 
 // Handle the events in serial order.
 func (r *Replica) Handle(e Event) error {
-	trace("%s event received: %v", e.Type(), e.Value())
+	if r.log.Level() <= LogTrace {
+		r.log.With(Fields{FieldEvent: e.Type().String()}).Trace("event received: %v", e.Value())
+	}
+
+	if r.recorder != nil {
+		if err := r.recorder.Record(e); err != nil {
+			return err
+		}
+	}
 
 	switch e.Type() {
 	case ProposeRequestEvent:
@@ -94,10 +186,38 @@ func (r *Replica) Handle(e Event) error {
 		return r.onPreacceptRequest(e)
 	case PreacceptReplyEvent:
 		return r.onPreacceptReply(e)
+	case AcceptRequestEvent:
+		return r.onAcceptRequest(e)
+	case AcceptReplyEvent:
+		return r.onAcceptReply(e)
+	case CommitRequestEvent:
+		return r.onCommitRequest(e)
+	case CommitReplyEvent:
+		return r.onCommitReply(e)
 	case BeaconRequestEvent:
 		return r.onBeaconRequest(e)
 	case BeaconReplyEvent:
 		return r.onBeaconReply(e)
+	case ResumeEvent:
+		return r.onResume(e)
+	case SnapshotEvent:
+		return r.onSnapshot(e)
+	case SnapshotRequestEvent:
+		return r.onSnapshotRequest(e)
+	case SnapshotReplyEvent:
+		return r.onSnapshotReply(e)
+	case ReloadEvent:
+		return r.onReload(e)
+	case StatusRequestEvent:
+		return r.onStatusRequest(e)
+	case FetchRequestEvent:
+		return r.onFetchRequest(e)
+	case WatchRequestEvent:
+		return r.onWatchRequest(e)
+	case UnwatchRequestEvent:
+		return r.onUnwatchRequest(e)
+	case GraphRequestEvent:
+		return r.onGraphRequest(e)
 	case ErrorEvent:
 		return e.Value().(error)
 	default:
@@ -127,15 +247,37 @@ func (r *Replica) Connect() error {
 	return nil
 }
 
+// SetLogger replaces the logger of the replica and its remotes, e.g. to write log
+// entries to custom sinks. It must be called before the replica is listening.
+func (r *Replica) SetLogger(logger Logger) {
+	r.log = logger.With(Fields{FieldReplica: r.Name})
+	for _, remote := range r.remotes {
+		remote.log = r.log.With(Fields{FieldPeer: remote.Name})
+	}
+}
+
+// Logs returns the 2D log of the replica. The log is not thread safe, so it should
+// only be inspected when the replica is not listening for events, e.g. after a replay.
+func (r *Replica) Logs() *Logs {
+	return r.logs
+}
+
 //===========================================================================
 // Communication Helpers
 //===========================================================================
 
 // Broadcast a request to all members in the quorum using thrifty communications if
-// so configured. The toall flag forces the request to be broadcast even if thrifty.
+// so configured. The toall flag forces the request to be broadcast even if thrifty, in
+// which case remotes that are not thrifty peers are connected when first sent to.
 func (r *Replica) Broadcast(req *pb.PeerRequest, toall bool) {
 	if r.thrifty == nil || toall {
 		for _, remote := range r.remotes {
+			if !remote.Running() {
+				if err := remote.Connect(); err != nil {
+					r.log.Warn("could not connect to %s: %s", remote.Name, err)
+					continue
+				}
+			}
 			remote.Send(req)
 		}
 	} else {
@@ -145,20 +287,29 @@ func (r *Replica) Broadcast(req *pb.PeerRequest, toall bool) {
 	}
 }
 
-// Commit an instance and broadcast the commit to all members in the quroum and reply
-// to the client(s) that initiated the proposal.
+// Commit an instance and broadcast the commit to all members in the quroum, then
+// execute it; the client(s) that initiated the proposal are replied to on execution.
 func (r *Replica) Commit(inst *pb.Instance) {
-	// TODO: Add commit pause to debug slow path
-	// Send commit messages to other replicas and ignore thrifty
-	r.Broadcast(pb.WrapCommitRequest(r.Name, &pb.CommitRequest{Inst: inst}), true)
-
-	// Mark the instance as executed and prepare to execute it
+	// Mark the instance as committed before it is sent to the other replicas
 	inst.Status = pb.Status_COMMITTED
+	r.notify(inst)
+	r.log.With(instanceFields(inst)).Debug("instance committed")
+
+	// Send commit messages to other replicas and ignore thrifty; the instance is
+	// cloned since it will be modified on execution while it's being sent. Sending
+	// is delayed if the commit phase is paused to debug the slow path.
+	req := pb.WrapCommitRequest(r.Name, &pb.CommitRequest{Inst: proto.Clone(inst).(*pb.Instance)})
+	send := func() error {
+		r.Broadcast(req, true)
+		r.disconnect()
 
-	// TODO: move response to client to execute thread
-	for _, op := range inst.Ops {
-		r.clients[op.Request] <- &pb.ProposeReply{Success: true}
-		delete(r.clients, op.Request)
+		// Execute the instance and any instances that were waiting for it to commit
+		r.execute()
+		return nil
+	}
+
+	if !r.paused(PhaseCommit, send) {
+		send()
 	}
 }
 
@@ -169,17 +320,28 @@ func (r *Replica) Commit(inst *pb.Instance) {
 // Runs a normal event loop, handling one event at a time.
 func (r *Replica) runEventLoop() error {
 	defer func() {
-		// nilify the events channel when we stop running it
-		r.events = nil
+		// signal that events are no longer handled when we stop running the loop
+		r.stop.Do(func() { close(r.done) })
+
+		// flush the recorded events to the trace file
+		if r.recorder != nil {
+			if err := r.recorder.Close(); err != nil {
+				r.log.Warne(err)
+			}
+			r.recorder = nil
+		}
 	}()
 
-	for e := range r.events {
-		if err := r.Handle(e); err != nil {
-			return err
+	for {
+		select {
+		case e := <-r.events:
+			if err := r.Handle(e); err != nil {
+				return err
+			}
+		case <-r.done:
+			return nil
 		}
 	}
-
-	return nil
 }
 
 // Runs an event loop that aggregates multiple propose requests into a single
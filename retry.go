@@ -0,0 +1,170 @@
+package epaxos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Classes of request errors returned by the client so that callers can distinguish
+// why a request failed with IsTimeout, IsRejected and IsUnavailable.
+var (
+	ErrTimeout     = errors.New("request timed out")
+	ErrRejected    = errors.New("request rejected")
+	ErrUnavailable = errors.New("cluster unavailable")
+)
+
+// RequestError is returned by the client when a request fails. The class of the error
+// is one of ErrTimeout, ErrRejected, or ErrUnavailable and the underlying error is the
+// error of the last attempt of the request.
+type RequestError struct {
+	Class    error // the class of the error
+	Attempts int   // the number of attempts made to send the request
+	Err      error // the error of the last attempt
+}
+
+// Error implements the error interface.
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("%s after %d attempt(s): %s", e.Class, e.Attempts, e.Err)
+}
+
+// Is returns true if the target is the class of the error.
+func (e *RequestError) Is(target error) bool {
+	return target == e.Class
+}
+
+// Unwrap returns the error of the last attempt.
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// IsTimeout returns true if the request failed because it timed out.
+func IsTimeout(err error) bool {
+	return isClass(err, ErrTimeout)
+}
+
+// IsRejected returns true if the request was rejected by the replica, e.g. because the
+// client is not authorized or the condition of the operation was not met.
+func IsRejected(err error) bool {
+	return isClass(err, ErrRejected)
+}
+
+// IsUnavailable returns true if the request failed because no replica could serve it.
+func IsUnavailable(err error) bool {
+	return isClass(err, ErrUnavailable)
+}
+
+// Returns true if the error is a request error of the class or is the class itself.
+func isClass(err error, class error) bool {
+	if e, ok := err.(*RequestError); ok {
+		return e.Class == class
+	}
+	return err == class
+}
+
+// Classify the error of an attempt as a timeout, a rejection by the replica, or an
+// unavailable replica or cluster.
+func classify(err error) error {
+	if err == context.DeadlineExceeded {
+		return ErrTimeout
+	}
+
+	switch status.Code(err) {
+	case codes.DeadlineExceeded:
+		return ErrTimeout
+	case codes.Unauthenticated, codes.PermissionDenied, codes.ResourceExhausted, codes.InvalidArgument:
+		return ErrRejected
+	default:
+		return ErrUnavailable
+	}
+}
+
+//===========================================================================
+// Retry Policy
+//===========================================================================
+
+// DefaultRetryPolicy returns the retry policy used by clients unless another policy is
+// set: up to DefaultRetries attempts with exponential backoff starting at 50ms, doubling
+// up to 2s with 20% jitter, retrying timeouts and unavailable replicas.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: DefaultRetries,
+		Backoff:     50 * time.Millisecond,
+		MaxBackoff:  2 * time.Second,
+		Multiplier:  2,
+		Jitter:      0.2,
+		Retry:       []error{ErrTimeout, ErrUnavailable},
+	}
+}
+
+// RetryPolicy determines how many times and how often the client attempts a request
+// and which classes of errors are retried. Between attempts the client waits for an
+// exponentially increasing backoff, randomized by the jitter so that many clients
+// that fail at the same time do not retry at the same time.
+type RetryPolicy struct {
+	MaxAttempts int           // the maximum number of attempts including the first
+	Backoff     time.Duration // the wait before the second attempt
+	MaxBackoff  time.Duration // the maximum wait between attempts
+	Multiplier  float64       // the factor the backoff is increased by after each attempt
+	Jitter      float64       // the fraction of the backoff to randomize, between 0 and 1
+	Retry       []error       // the classes of errors to retry
+}
+
+// Retryable returns true if the class of the error is retried by the policy.
+func (p *RetryPolicy) Retryable(class error) bool {
+	for _, retry := range p.Retry {
+		if retry == class {
+			return true
+		}
+	}
+	return false
+}
+
+// Delay returns the time to wait after the specified attempt (starting at 1) before
+// making the next attempt.
+func (p *RetryPolicy) Delay(attempt int) time.Duration {
+	if p.Backoff <= 0 {
+		return 0
+	}
+
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(p.Backoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		delay += delay * jitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(delay)
+}
+
+// Wait for the delay after the specified attempt or until the context is done.
+func (p *RetryPolicy) wait(ctx context.Context, attempt int) error {
+	delay := p.Delay(attempt)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
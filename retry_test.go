@@ -0,0 +1,111 @@
+package epaxos_test
+
+import (
+	"context"
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/bbengfort/epaxos"
+	"github.com/bbengfort/x/peers"
+)
+
+var _ = Describe("Retry Policy", func() {
+
+	It("should back off exponentially up to the maximum", func() {
+		policy := &RetryPolicy{Backoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond, Multiplier: 2}
+		Ω(policy.Delay(1)).Should(Equal(10 * time.Millisecond))
+		Ω(policy.Delay(2)).Should(Equal(20 * time.Millisecond))
+		Ω(policy.Delay(3)).Should(Equal(40 * time.Millisecond))
+		Ω(policy.Delay(4)).Should(Equal(50 * time.Millisecond))
+		Ω(policy.Delay(10)).Should(Equal(50 * time.Millisecond))
+	})
+
+	It("should randomize the backoff by the jitter", func() {
+		policy := &RetryPolicy{Backoff: 100 * time.Millisecond, Multiplier: 2, Jitter: 0.2}
+		for i := 0; i < 100; i++ {
+			Ω(policy.Delay(2)).Should(BeNumerically("~", 200*time.Millisecond, 40*time.Millisecond))
+		}
+	})
+
+	It("should only retry the specified classes of errors", func() {
+		policy := DefaultRetryPolicy()
+		Ω(policy.Retryable(ErrTimeout)).Should(BeTrue())
+		Ω(policy.Retryable(ErrUnavailable)).Should(BeTrue())
+		Ω(policy.Retryable(ErrRejected)).Should(BeFalse())
+	})
+
+	It("should compare request errors by class", func() {
+		var err error = &RequestError{Class: ErrTimeout, Attempts: 2, Err: context.DeadlineExceeded}
+		Ω(IsTimeout(err)).Should(BeTrue())
+		Ω(IsUnavailable(err)).Should(BeFalse())
+		Ω(err.(*RequestError).Err).Should(Equal(context.DeadlineExceeded))
+		Ω(err.Error()).Should(ContainSubstring("after 2 attempt(s)"))
+	})
+
+	Describe("Client", func() {
+
+		It("should return an unavailable error after all attempts", func() {
+			network := []peers.Peer{{PID: 1, Name: "alpha", IPAddr: "127.0.0.1", Port: 47264}}
+			client, _ := NewClient("alpha", &Config{Timeout: "100ms", Peers: network})
+			client.SetRetryPolicy(&RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, Retry: []error{ErrUnavailable}})
+
+			err := client.Put("foo", []byte("bar"), false)
+			Ω(IsUnavailable(err)).Should(BeTrue())
+			Ω(err.(*RequestError).Attempts).Should(Equal(3))
+		})
+
+		It("should return a timeout error when the context deadline passes", func() {
+			// A replica that never replies so that every attempt times out
+			sock, err := net.Listen("tcp", "127.0.0.1:47265")
+			Ω(err).ShouldNot(HaveOccurred())
+			defer sock.Close()
+
+			network := []peers.Peer{{PID: 1, Name: "alpha", IPAddr: "127.0.0.1", Port: 47265}}
+			client, err := NewClient("alpha", &Config{Timeout: "50ms", Peers: network})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			defer cancel()
+
+			_, err = client.GetContext(ctx, "foo")
+			Ω(IsTimeout(err)).Should(BeTrue())
+
+			// Canceled requests return the context's error rather than a request error
+			ctx, cancel = context.WithCancel(context.Background())
+			cancel()
+			_, err = client.GetContext(ctx, "foo")
+			Ω(err).Should(Equal(context.Canceled))
+		})
+
+		It("should not retry rejected requests", func() {
+			network := []peers.Peer{{PID: 1, Name: "alpha", IPAddr: "127.0.0.1", Port: 47266}}
+			replica, err := New(&Config{
+				Name:     "alpha",
+				LogLevel: int(LogSilent),
+				Peers:    network,
+				Clients:  []ClientPolicy{{Identity: "reader", Token: "r3ad", Access: []string{"read"}}},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+			serve(replica)
+
+			// Wait for the replica to listen so that the only attempt is rejected
+			Eventually(func() error {
+				conn, err := net.Dial("tcp", "127.0.0.1:47266")
+				if err == nil {
+					conn.Close()
+				}
+				return err
+			}).Should(Succeed())
+
+			client, err := NewClient("alpha", &Config{Timeout: "2s", Token: "r3ad", Peers: network})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			err = client.PutContext(context.Background(), "foo", []byte("bar"), false)
+			Ω(IsRejected(err)).Should(BeTrue())
+			Ω(err.(*RequestError).Attempts).Should(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,237 @@
+package epaxos
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bbengfort/x/peers"
+)
+
+// FailureCooldown is how long a client avoids connecting to a replica after a request
+// to it failed, unless no other replica is available.
+const FailureCooldown = 10 * time.Second
+
+// Peer metadata keys that specify the region and zone of a replica, e.g. in the
+// aws_instance section of the peer configuration.
+const (
+	metadataRegion = "region"
+	metadataZone   = "zone"
+)
+
+// Weight of the most recent observation in the moving average of peer latencies.
+const latencyWeight = 0.2
+
+// Selector chooses the replica that a client connects to from the candidates, which
+// never include replicas that recently failed unless all of them have. Selectors are
+// only called when the client is connecting and are not called concurrently by the
+// same client, but may be shared by many clients.
+type Selector interface {
+	Select(candidates []peers.Peer, health *Health) *peers.Peer
+}
+
+// GetSelector returns the replica selection strategy specified by the configuration:
+// local (the default), random, round-robin, latency, or affinity.
+func (c *Config) GetSelector() (Selector, error) {
+	switch strings.ToLower(c.Selection) {
+	case "", "local":
+		hostname, _ := c.GetName()
+		return &LocalSelector{Hostname: hostname}, nil
+	case "random":
+		return &RandomSelector{}, nil
+	case "round-robin", "roundrobin":
+		return &RoundRobinSelector{}, nil
+	case "latency":
+		return &LatencySelector{}, nil
+	case "affinity":
+		return &AffinitySelector{Region: c.Region, Zone: c.Zone, Fallback: &LatencySelector{}}, nil
+	default:
+		return nil, fmt.Errorf("unknown replica selection strategy '%s'", c.Selection)
+	}
+}
+
+//===========================================================================
+// Peer Health
+//===========================================================================
+
+// NewHealth creates an empty record of peer health.
+func NewHealth() *Health {
+	return &Health{
+		latency: make(map[string]time.Duration),
+		failed:  make(map[string]time.Time),
+	}
+}
+
+// Health records the observed latency of requests to each peer and when requests to a
+// peer last failed so that clients can select responsive replicas. It is safe to use
+// concurrently.
+type Health struct {
+	sync.RWMutex
+	latency map[string]time.Duration // moving average of request latency by peer name
+	failed  map[string]time.Time     // time of the last failed request by peer name
+}
+
+// Observe the latency of a successful request to the named peer, which also clears
+// any previous failure of the peer.
+func (h *Health) Observe(name string, latency time.Duration) {
+	h.Lock()
+	defer h.Unlock()
+
+	if avg, ok := h.latency[name]; ok {
+		latency = time.Duration(latencyWeight*float64(latency) + (1-latencyWeight)*float64(avg))
+	}
+	h.latency[name] = latency
+	delete(h.failed, name)
+}
+
+// Fail records that a request to the named peer failed.
+func (h *Health) Fail(name string) {
+	h.Lock()
+	defer h.Unlock()
+	h.failed[name] = time.Now()
+}
+
+// Latency returns the average latency of requests to the named peer and false if no
+// requests to the peer have succeeded.
+func (h *Health) Latency(name string) (time.Duration, bool) {
+	h.RLock()
+	defer h.RUnlock()
+	latency, ok := h.latency[name]
+	return latency, ok
+}
+
+// Failed returns true if a request to the named peer failed within the cooldown.
+func (h *Health) Failed(name string) bool {
+	h.RLock()
+	defer h.RUnlock()
+	failed, ok := h.failed[name]
+	return ok && time.Since(failed) < FailureCooldown
+}
+
+// Returns the peers that have not recently failed, or all of the peers if every one
+// of them has recently failed, so that the client keeps trying the cluster.
+func (h *Health) available(network []peers.Peer) []peers.Peer {
+	candidates := make([]peers.Peer, 0, len(network))
+	for _, peer := range network {
+		if !h.Failed(peer.Name) {
+			candidates = append(candidates, peer)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return network
+	}
+	return candidates
+}
+
+//===========================================================================
+// Selection Strategies
+//===========================================================================
+
+// LocalSelector selects the replica on the same host as the client, otherwise a
+// random replica.
+type LocalSelector struct {
+	Hostname string // the name or hostname of the client's host
+}
+
+// Select implements the Selector interface.
+func (s *LocalSelector) Select(candidates []peers.Peer, health *Health) *peers.Peer {
+	if s.Hostname != "" {
+		for idx := range candidates {
+			if candidates[idx].Name == s.Hostname || candidates[idx].Hostname == s.Hostname {
+				return &candidates[idx]
+			}
+		}
+	}
+	return &candidates[rand.Intn(len(candidates))]
+}
+
+// RandomSelector selects a replica uniformly at random.
+type RandomSelector struct{}
+
+// Select implements the Selector interface.
+func (s *RandomSelector) Select(candidates []peers.Peer, health *Health) *peers.Peer {
+	return &candidates[rand.Intn(len(candidates))]
+}
+
+// RoundRobinSelector selects each of the replicas in turn.
+type RoundRobinSelector struct {
+	next uint32
+}
+
+// Select implements the Selector interface.
+func (s *RoundRobinSelector) Select(candidates []peers.Peer, health *Health) *peers.Peer {
+	idx := atomic.AddUint32(&s.next, 1) - 1
+	return &candidates[int(idx%uint32(len(candidates)))]
+}
+
+// LatencySelector selects the replica with the lowest observed latency. Replicas whose
+// latency has not been observed are selected first so that every replica is measured.
+type LatencySelector struct{}
+
+// Select implements the Selector interface.
+func (s *LatencySelector) Select(candidates []peers.Peer, health *Health) *peers.Peer {
+	var (
+		best       *peers.Peer
+		lowest     time.Duration
+		unobserved []int
+	)
+
+	for idx := range candidates {
+		latency, ok := health.Latency(candidates[idx].Name)
+		if !ok {
+			unobserved = append(unobserved, idx)
+			continue
+		}
+
+		if best == nil || latency < lowest {
+			best, lowest = &candidates[idx], latency
+		}
+	}
+
+	if len(unobserved) > 0 {
+		return &candidates[unobserved[rand.Intn(len(unobserved))]]
+	}
+	return best
+}
+
+// AffinitySelector selects a replica in the same zone as the client, otherwise in the
+// same region, otherwise any replica. The region and zone of a replica are specified
+// by the "region" and "zone" keys of its aws_instance metadata. The fallback selects
+// among the replicas with the closest affinity, randomly if it is not specified.
+type AffinitySelector struct {
+	Region   string   // the region of the client
+	Zone     string   // the zone of the client
+	Fallback Selector // selects among the replicas with the same affinity
+}
+
+// Select implements the Selector interface.
+func (s *AffinitySelector) Select(candidates []peers.Peer, health *Health) *peers.Peer {
+	fallback := s.Fallback
+	if fallback == nil {
+		fallback = &RandomSelector{}
+	}
+
+	affinities := []struct{ key, value string }{{metadataZone, s.Zone}, {metadataRegion, s.Region}}
+	for _, affinity := range affinities {
+		if affinity.value == "" {
+			continue
+		}
+
+		nearby := make([]peers.Peer, 0, len(candidates))
+		for _, peer := range candidates {
+			if peer.AWSInstance[affinity.key] == affinity.value {
+				nearby = append(nearby, peer)
+			}
+		}
+
+		if len(nearby) > 0 {
+			return fallback.Select(nearby, health)
+		}
+	}
+
+	return fallback.Select(candidates, health)
+}
@@ -0,0 +1,101 @@
+package epaxos_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/bbengfort/epaxos"
+	"github.com/bbengfort/x/peers"
+)
+
+var _ = Describe("Replica Selection", func() {
+
+	var (
+		network []peers.Peer
+		health  *Health
+	)
+
+	BeforeEach(func() {
+		network = []peers.Peer{
+			{PID: 1, Name: "alpha", AWSInstance: map[string]string{"region": "us-east-1", "zone": "us-east-1a"}},
+			{PID: 2, Name: "bravo", AWSInstance: map[string]string{"region": "us-east-1", "zone": "us-east-1b"}},
+			{PID: 3, Name: "charlie", AWSInstance: map[string]string{"region": "eu-west-1", "zone": "eu-west-1a"}},
+		}
+		health = NewHealth()
+	})
+
+	It("should select replicas in turn", func() {
+		selector := &RoundRobinSelector{}
+		names := make([]string, 0, 6)
+		for i := 0; i < 6; i++ {
+			names = append(names, selector.Select(network, health).Name)
+		}
+		Ω(names).Should(Equal([]string{"alpha", "bravo", "charlie", "alpha", "bravo", "charlie"}))
+	})
+
+	It("should select the replica with the lowest latency", func() {
+		selector := &LatencySelector{}
+		health.Observe("alpha", 30*time.Millisecond)
+		health.Observe("bravo", 10*time.Millisecond)
+
+		// Unobserved replicas are selected so that their latency is measured
+		Ω(selector.Select(network, health).Name).Should(Equal("charlie"))
+
+		health.Observe("charlie", 20*time.Millisecond)
+		Ω(selector.Select(network, health).Name).Should(Equal("bravo"))
+
+		// Latency is a moving average of the observations
+		for i := 0; i < 10; i++ {
+			health.Observe("bravo", 50*time.Millisecond)
+		}
+		Ω(selector.Select(network, health).Name).Should(Equal("charlie"))
+	})
+
+	It("should select replicas in the same zone or region", func() {
+		selector := &AffinitySelector{Region: "us-east-1", Zone: "us-east-1b"}
+		Ω(selector.Select(network, health).Name).Should(Equal("bravo"))
+
+		// Without a replica in the zone, any replica in the region is selected
+		selector.Zone = "us-east-1c"
+		for i := 0; i < 10; i++ {
+			Ω(selector.Select(network, health).Name).Should(Or(Equal("alpha"), Equal("bravo")))
+		}
+
+		selector = &AffinitySelector{Region: "eu-west-1"}
+		Ω(selector.Select(network, health).Name).Should(Equal("charlie"))
+	})
+
+	It("should record recent failures", func() {
+		Ω(health.Failed("alpha")).Should(BeFalse())
+		health.Fail("alpha")
+		Ω(health.Failed("alpha")).Should(BeTrue())
+
+		// A successful request clears the failure
+		health.Observe("alpha", time.Millisecond)
+		Ω(health.Failed("alpha")).Should(BeFalse())
+	})
+
+	It("should configure the selection strategy", func() {
+		config := &Config{Selection: "round-robin"}
+		Ω(config.GetSelector()).Should(BeAssignableToTypeOf(&RoundRobinSelector{}))
+
+		config.Selection = "nearest"
+		Ω(config.Validate()).ShouldNot(Succeed())
+	})
+
+	It("should fail over from an unreachable replica", func() {
+		// The client is configured with a replica that is not running
+		network := append(runNetwork(48264), peers.Peer{PID: 4, Name: "delta", IPAddr: "127.0.0.1", Port: 48299})
+		client, err := NewClient("delta", &Config{Timeout: "2s", Peers: network})
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Ω(client.Put("foo", []byte("bar"), false)).Should(Succeed())
+		Ω(client.Health().Failed("delta")).Should(BeTrue())
+
+		val, err := client.Get("foo")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(val).Should(Equal([]byte("bar")))
+	})
+})
@@ -6,12 +6,20 @@ import (
 	"io"
 
 	"github.com/bbengfort/epaxos/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Propose is the primary entry point for client requests. This method is the gRPC
 // handler that essentially dispatches the propose event to the replica and listens for
-// the replica to send back a response so the client can be replied to.
+// the replica to send back a response so the client can be replied to. Requests are
+// authenticated, authorized, and checked against the client's quota before dispatch.
 func (r *Replica) Propose(ctx context.Context, in *pb.ProposeRequest) (*pb.ProposeReply, error) {
+	if err := r.admit(ctx, in); err != nil {
+		r.log.With(Fields{FieldPeer: in.Identity}).Caution("rejected propose request: %s", err)
+		return nil, err
+	}
+
 	// Record the request
 	// TODO: add metrics measurements here
 	// go func() { r.Metrics.Request(in.Identity) }()
@@ -20,25 +28,41 @@ func (r *Replica) Propose(ctx context.Context, in *pb.ProposeRequest) (*pb.Propo
 	source := make(chan *pb.ProposeReply, 1)
 
 	// Dispatch the event and wait for it to be handled
-	event := &event{etype: ProposeRequestEvent, source: source, value: in}
+	event := &event{etype: ProposeRequestEvent, source: source, value: in, peer: in.Identity}
 	if err := r.Dispatch(event); err != nil {
 		return nil, err
 	}
 
-	out := <-source
-	return out, nil
+	select {
+	case out := <-source:
+		return out, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-r.done:
+		return nil, ErrNotListening
+	}
 }
 
 // Consensus receives PeerRequest messages from remote peers and dispatches them to the
 // primary replica process. This method waits for the handler to create a reply before
-// receiving the next message.
+// receiving the next message. If TLS or a cluster key is configured, the remote must
+// authenticate as one of the configured peers to open the stream. Every message must be
+// sent by a configured peer that matches the authenticated identity of the stream and
+// may only contain instances led by the sender, otherwise the stream is closed.
 func (r *Replica) Consensus(stream pb.Epaxos_ConsensusServer) (err error) {
 	// currently connected remote peer for logging
 	var peer string
 
+	// Authenticate the remote peer before receiving any messages
+	var identity string
+	if identity, err = r.authenticate(stream.Context()); err != nil {
+		r.log.Warn("rejected consensus stream: %s", err)
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+
 	defer func() {
 		if peer != "" {
-			info("%s disconnected", peer)
+			r.log.With(Fields{FieldPeer: peer}).Info("%s disconnected", peer)
 		}
 	}()
 
@@ -52,9 +76,15 @@ func (r *Replica) Consensus(stream pb.Epaxos_ConsensusServer) (err error) {
 			return err
 		}
 
+		// Reject messages from unknown peers or that impersonate another peer
+		if err = r.verifyPeerRequest(identity, in); err != nil {
+			r.log.Warn("rejected consensus stream: %s", err)
+			return status.Error(codes.PermissionDenied, err.Error())
+		}
+
 		if peer == "" {
 			peer = in.Sender
-			info("%s connected", peer)
+			r.log.With(Fields{FieldPeer: peer}).Info("%s connected", peer)
 		}
 
 		// Unwrap the message and create the specific event type
@@ -66,6 +96,7 @@ func (r *Replica) Consensus(stream pb.Epaxos_ConsensusServer) (err error) {
 		// Create the source to wait for the reply
 		source := make(chan *pb.PeerReply, 1)
 		e.source = source
+		e.peer = in.Sender
 
 		// Dispatch the event to the serialized event handler
 		// backpressure from this channel will prevent more RECV
@@ -75,10 +106,17 @@ func (r *Replica) Consensus(stream pb.Epaxos_ConsensusServer) (err error) {
 
 		// Wait for the event to be handled before receiving the next
 		// message on the stream; this ensures that the order of messages
-		// received matches the order of replies sent.
-		out := <-source
-		if err = stream.Send(out); err != nil {
-			return err
+		// received matches the order of replies sent. Stop waiting if the replica stops
+		// handling events, e.g. when it is closed while a phase is paused.
+		select {
+		case out := <-source:
+			if err = stream.Send(out); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-r.done:
+			return ErrNotListening
 		}
 	}
 }
@@ -0,0 +1,153 @@
+package epaxos
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bbengfort/epaxos/pb"
+)
+
+// SessionResults is the number of results of the most recent requests cached by each
+// client session. Clients must not have more than this many requests outstanding,
+// otherwise retries of the oldest requests are rejected as stale.
+const SessionResults = 128
+
+// Prefix of the pseudo-key used to make all requests of a client session conflict, so
+// that every replica executes the requests of a client in the same order.
+const sessionKeyPrefix = "\x00session:"
+
+// newSessions creates an empty session table that proposes the expiration of sessions
+// that have been idle for the specified timeout.
+func newSessions(timeout time.Duration) *sessions {
+	return &sessions{
+		table:   make(map[string]*session),
+		timeout: timeout,
+		swept:   time.Now(),
+	}
+}
+
+// sessions is the table of client sessions that is used to provide exactly-once
+// semantics for client requests. Clients assign monotonically increasing sequence
+// numbers to their requests and retry a request with the same sequence number; when a
+// duplicate request is executed, the cached result of the original request is returned
+// instead of applying the operation again.
+//
+// The table is updated during execution, so all replicas have the same sessions after
+// executing the same instances. Because the requests of a client conflict with each
+// other, they are executed in the same order on every replica. Sessions are expired by
+// replicating an EXPIRE operation that conflicts with the client's requests, so every
+// replica expires the session at the same point in the client's history. Only the time
+// of the last request is local to the replica; every replica proposes to expire the
+// session when it has not executed a request of the client within the timeout, so the
+// session is expired even if the replica that led the last request has failed. The
+// proposals are idempotent since a session is only expired at the proposed request.
+type sessions struct {
+	table   map[string]*session // sessions by client identity
+	timeout time.Duration       // idle time before proposing to expire a session
+	swept   time.Time           // the last time idle sessions were checked
+}
+
+// session tracks the results of the most recent requests of a client.
+type session struct {
+	latest  uint64             // the highest sequence number executed for the client
+	results map[uint64]*result // the cached results of the most recent requests
+	touched time.Time          // the local time the latest request was executed
+}
+
+// execute the request of the operation for the client session, applying it with the
+// function if it has not been executed before, otherwise returning the cached result.
+// The operations of a transaction share the request of the operation. Requests without
+// a client sequence number are always applied.
+func (s *sessions) execute(op *pb.Operation, apply func() *result) *result {
+	if op.Type == pb.AccessType_EXPIRE {
+		// Only expire the session if there have been no requests since the proposal
+		if sess, ok := s.table[op.Client]; ok && sess.latest == op.Sequence {
+			delete(s.table, op.Client)
+		}
+		return &result{}
+	}
+
+	if op.Client == "" || op.Sequence == 0 {
+		return apply()
+	}
+
+	sess, ok := s.table[op.Client]
+	if !ok {
+		sess = &session{results: make(map[uint64]*result)}
+		s.table[op.Client] = sess
+	}
+
+	// Return the cached result for duplicate requests
+	if res, ok := sess.results[op.Sequence]; ok {
+		return res
+	}
+
+	// The result of the request is no longer cached, so it cannot be safely reapplied
+	if sess.latest >= SessionResults && op.Sequence <= sess.latest-SessionResults {
+		return &result{err: fmt.Sprintf("request %d of client %q is stale", op.Sequence, op.Client)}
+	}
+
+	res := apply()
+	sess.results[op.Sequence] = res
+	sess.touched = time.Now()
+
+	if op.Sequence > sess.latest {
+		sess.latest = op.Sequence
+
+		// Evict the results that are outside of the window of cached results
+		if sess.latest > SessionResults {
+			for seq := range sess.results {
+				if seq <= sess.latest-SessionResults {
+					delete(sess.results, seq)
+				}
+			}
+		}
+	}
+
+	return res
+}
+
+// idle returns the operations to expire the sessions that have not executed a request
+// on this replica within the timeout. Sessions are checked at most every half
+// timeout to reduce the cost of scanning the session table.
+func (s *sessions) idle() []*pb.Operation {
+	if s.timeout <= 0 || time.Since(s.swept) < s.timeout/2 {
+		return nil
+	}
+	s.swept = time.Now()
+
+	ops := make([]*pb.Operation, 0)
+	for client, sess := range s.table {
+		if time.Since(sess.touched) > s.timeout {
+			ops = append(ops, &pb.Operation{
+				Type:     pb.AccessType_EXPIRE,
+				Key:      sessionKey(client),
+				Client:   client,
+				Sequence: sess.latest,
+			})
+
+			// Do not propose to expire the session again until the timeout passes
+			sess.touched = time.Now()
+		}
+	}
+	return ops
+}
+
+// Returns the pseudo-key of the client session.
+func sessionKey(client string) string {
+	return sessionKeyPrefix + client
+}
+
+// Returns the keys that the operation conflicts on: the key of the operation and, if
+// the operation is part of a client session, the pseudo-key of the session. RECONFIGURE
+// operations conflict on the membership pseudo-key rather than their key.
+func conflictKeys(op *pb.Operation) []string {
+	if op.Type == pb.AccessType_RECONFIGURE {
+		return []string{membershipKey}
+	}
+
+	if op.Client != "" && op.Sequence > 0 && op.Type != pb.AccessType_EXPIRE {
+		return []string{op.Key, sessionKey(op.Client)}
+	}
+	return []string{op.Key}
+}
@@ -0,0 +1,111 @@
+package epaxos_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/bbengfort/epaxos"
+	"github.com/bbengfort/epaxos/pb"
+	"github.com/bbengfort/x/peers"
+	"google.golang.org/grpc"
+)
+
+var _ = Describe("Sessions", func() {
+
+	var (
+		port    = 45264
+		network []peers.Peer
+		conns   map[string]*grpc.ClientConn
+	)
+
+	// Proposes the operation with the client sequence number to the named replica.
+	propose := func(name string, sequence uint64, op *pb.Operation) *pb.ProposeReply {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		rep, err := pb.NewEpaxosClient(conns[name]).Propose(ctx, &pb.ProposeRequest{Identity: "session", Sequence: sequence, Op: op})
+		Ω(err).ShouldNot(HaveOccurred())
+		return rep
+	}
+
+	// Connects to every replica of the network.
+	dial := func() {
+		conns = make(map[string]*grpc.ClientConn)
+		for _, peer := range network {
+			var err error
+			conns[peer.Name], err = grpc.Dial(fmt.Sprintf("127.0.0.1:%d", peer.Port), grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(2*time.Second))
+			Ω(err).ShouldNot(HaveOccurred())
+		}
+	}
+
+	AfterEach(func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	})
+
+	Context("with the default session timeout", func() {
+		BeforeEach(func() {
+			network = runNetwork(port)
+			port += len(network)
+			dial()
+		})
+
+		It("should execute duplicate requests exactly once", func() {
+			rep := propose("alpha", 1, &pb.Operation{Type: pb.AccessType_WRITEREAD, Key: "foo", Value: []byte("first")})
+			Ω(rep.Success).Should(BeTrue())
+			Ω(rep.Value).Should(Equal([]byte("first")))
+
+			// A retry on another replica returns the cached result without applying it
+			rep = propose("bravo", 1, &pb.Operation{Type: pb.AccessType_WRITEREAD, Key: "foo", Value: []byte("second")})
+			Ω(rep.Success).Should(BeTrue())
+			Ω(rep.Value).Should(Equal([]byte("first")))
+
+			rep = propose("charlie", 2, &pb.Operation{Type: pb.AccessType_READ, Key: "foo"})
+			Ω(rep.Success).Should(BeTrue())
+			Ω(rep.Value).Should(Equal([]byte("first")))
+
+			// Requests without a sequence number are not deduplicated
+			propose("alpha", 0, &pb.Operation{Type: pb.AccessType_WRITE, Key: "foo", Value: []byte("third")})
+			propose("alpha", 0, &pb.Operation{Type: pb.AccessType_WRITE, Key: "foo", Value: []byte("fourth")})
+			rep = propose("bravo", 3, &pb.Operation{Type: pb.AccessType_READ, Key: "foo"})
+			Ω(rep.Value).Should(Equal([]byte("fourth")))
+		})
+
+		It("should reject stale requests that are no longer cached", func() {
+			op := &pb.Operation{Type: pb.AccessType_READ, Key: "foo"}
+			Ω(propose("alpha", SessionResults+2, op).Success).Should(BeTrue())
+
+			rep := propose("bravo", 1, op)
+			Ω(rep.Success).Should(BeFalse())
+			Ω(rep.Error).Should(ContainSubstring("stale"))
+		})
+	})
+
+	It("should expire idle sessions when the replica that led the last request has failed", func() {
+		network = makeNetwork(port, 3)
+		port += len(network)
+
+		stops := make(map[string]func())
+		for _, peer := range network {
+			stops[peer.Name] = runReplica(peer.Name, network, func(conf *Config) {
+				conf.SessionTimeout = "500ms"
+			})
+		}
+		dial()
+
+		rep := propose("alpha", 1, &pb.Operation{Type: pb.AccessType_WRITEREAD, Key: "foo", Value: []byte("first")})
+		Ω(rep.Value).Should(Equal([]byte("first")))
+		stops["alpha"]()
+
+		// Once the session is expired the request is no longer a duplicate and is applied
+		Eventually(func() []byte {
+			propose("bravo", 0, &pb.Operation{Type: pb.AccessType_READ, Key: "bar"})
+			return propose("charlie", 1, &pb.Operation{Type: pb.AccessType_WRITEREAD, Key: "foo", Value: []byte("second")}).Value
+		}, "5s", "250ms").Should(Equal([]byte("second")))
+	})
+})
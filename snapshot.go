@@ -0,0 +1,481 @@
+package epaxos
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/bbengfort/epaxos/pb"
+	"github.com/bbengfort/x/peers"
+	"github.com/golang/protobuf/proto"
+)
+
+// Extension of the snapshot files written to the data directory.
+const snapshotExt = ".snapshot"
+
+// SnapshotChunkSize is the maximum number of bytes of a snapshot transfer that are sent
+// to a remote replica in a single message.
+const SnapshotChunkSize = 1 << 20
+
+// TransferTimeout is the time to wait for a snapshot transfer to a remote replica to be
+// installed before the snapshot is transferred to the replica again.
+const TransferTimeout = 10 * time.Second
+
+//===========================================================================
+// Snapshots and Log Truncation
+//===========================================================================
+
+// Dispatch snapshot events to the event loop at the interval until the replica stops
+// listening for events.
+func (r *Replica) runSnapshots(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.Dispatch(&event{etype: SnapshotEvent}); err != nil {
+				return
+			}
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// Snapshot the state machine if any instances have been executed since the last
+// snapshot, then truncate the instances that are covered by the snapshot and that
+// every replica has executed. The executed frontier of the replica is sent to the
+// other replicas in a beacon so that they can also truncate their logs.
+func (r *Replica) onSnapshot(e Event) (err error) {
+	executed := r.logs.Executed()
+	r.updateSnapshot(executed)
+
+	r.Broadcast(pb.WrapBeaconRequest(r.Name, &pb.BeaconRequest{
+		QuorumMember: true,
+		Replica:      r.PID,
+		Executed:     executed,
+	}), true)
+
+	if n := r.logs.Truncate(r.truncatable()); n > 0 {
+		r.log.Debug("truncated %d executed instances from the log", n)
+	}
+	return nil
+}
+
+// Snapshot the state machine and write the snapshot to disk if any instances have been
+// executed since the last snapshot.
+func (r *Replica) updateSnapshot(executed map[uint32]uint64) {
+	if r.snapshot != nil && frontierEqual(r.snapshot.Executed, executed) {
+		return
+	}
+
+	r.snapshot = r.takeSnapshot(executed)
+	if err := r.writeSnapshot(); err != nil {
+		r.log.Warn("could not write snapshot: %s", err)
+	}
+}
+
+// Record the executed frontier that a remote replica sent in a beacon message. If the
+// remote has not executed instances that have been truncated from the log, it cannot
+// catch up from the log, so the latest snapshot is transferred to it instead.
+func (r *Replica) observeFrontier(replica uint32, executed map[uint32]uint64) {
+	if replica == r.PID || executed == nil {
+		return
+	}
+	r.frontiers[replica] = executed
+
+	for pid, slot := range executed {
+		if r.logs.truncated(pid, slot) {
+			r.transfer(replica)
+			return
+		}
+	}
+}
+
+// Returns the slot of each replica's log below which the instances can be truncated:
+// the minimum of the frontier of the snapshot and of the frontier that every other
+// replica last reported. Nothing is truncated until every replica has reported.
+func (r *Replica) truncatable() map[uint32]uint64 {
+	if r.snapshot == nil {
+		return nil
+	}
+
+	slots := make(map[uint32]uint64, len(r.snapshot.Executed))
+	for pid, slot := range r.snapshot.Executed {
+		slots[pid] = slot
+	}
+
+	for _, peer := range r.config.Peers {
+		if peer.PID == r.PID {
+			continue
+		}
+
+		executed, ok := r.frontiers[peer.PID]
+		if !ok {
+			return nil
+		}
+
+		for pid, slot := range slots {
+			if executed[pid] < slot {
+				slots[pid] = executed[pid]
+			}
+		}
+	}
+
+	return slots
+}
+
+// Create a snapshot of the store, client sessions, and membership at the executed
+// frontier. Keys and sessions are sorted so that replicas with the same state create
+// the same snapshot.
+func (r *Replica) takeSnapshot(executed map[uint32]uint64) *pb.Snapshot {
+	snap := &pb.Snapshot{
+		Executed: executed,
+		Sequence: r.logs.Sequence(),
+		Revision: r.store.revision,
+		Data:     make([]*pb.KeyValue, 0, len(r.store.data)),
+		Sessions: make([]*pb.Session, 0, len(r.sessions.table)),
+		Epoch:    r.epoch,
+	}
+
+	// Peers only contain strings and integers, so they cannot fail to marshal
+	snap.Peers, _ = json.Marshal(r.config.Peers)
+
+	for key, value := range r.store.data {
+		snap.Data = append(snap.Data, &pb.KeyValue{Key: key, Value: value, Version: r.store.versions[key]})
+	}
+	sort.Slice(snap.Data, func(i, j int) bool { return snap.Data[i].Key < snap.Data[j].Key })
+
+	for client, sess := range r.sessions.table {
+		session := &pb.Session{
+			Client:  client,
+			Latest:  sess.latest,
+			Results: make(map[uint64]*pb.ProposeReply, len(sess.results)),
+		}
+
+		for seq, res := range sess.results {
+			session.Results[seq] = res.reply(0)
+		}
+		snap.Sessions = append(snap.Sessions, session)
+	}
+	sort.Slice(snap.Sessions, func(i, j int) bool { return snap.Sessions[i].Client < snap.Sessions[j].Client })
+
+	return snap
+}
+
+// Restore the store and client sessions from the snapshot, replacing the current state.
+func (r *Replica) restoreSnapshot(snap *pb.Snapshot) {
+	r.store.data = make(map[string][]byte, len(snap.Data))
+	r.store.versions = make(map[string]uint64, len(snap.Data))
+	r.store.revision = snap.Revision
+
+	for _, kv := range snap.Data {
+		r.store.data[kv.Key] = kv.Value
+		r.store.versions[kv.Key] = kv.Version
+	}
+
+	r.sessions.table = make(map[string]*session, len(snap.Sessions))
+	for _, sess := range snap.Sessions {
+		session := &session{
+			latest:  sess.Latest,
+			results: make(map[uint64]*result, len(sess.Results)),
+			touched: time.Now(),
+		}
+
+		for seq, rep := range sess.Results {
+			res := &result{err: rep.Error, results: make([]*result, 0, len(rep.Results))}
+			for _, op := range rep.Results {
+				res.results = append(res.results, &result{key: op.Key, value: op.Value, version: op.Version, err: op.Error})
+			}
+			session.results[seq] = res
+		}
+		r.sessions.table[sess.Client] = session
+	}
+}
+
+// Write the latest snapshot to the data directory if one is configured, replacing
+// the previous snapshot of the replica so that older snapshots are removed from disk.
+func (r *Replica) writeSnapshot() (err error) {
+	if r.config.Data == "" || r.snapshot == nil {
+		return nil
+	}
+
+	var data []byte
+	if data, err = proto.Marshal(r.snapshot); err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(r.config.Data, 0755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file and rename it so a crash never leaves a partial snapshot
+	path := SnapshotPath(r.config.Data, r.Name)
+	if err = ioutil.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// Restore the store, client sessions, membership, and log offsets from the snapshot of
+// the replica in the data directory, if one has been written, so that a restarted
+// replica does not execute the instances covered by the snapshot again. The instances
+// after the snapshot are not written to disk, so they are received from the other
+// replicas, or a snapshot is transferred if they have been truncated.
+func (r *Replica) loadSnapshot() (err error) {
+	if r.config.Data == "" {
+		return nil
+	}
+
+	var snap *pb.Snapshot
+	if snap, err = ReadSnapshot(SnapshotPath(r.config.Data, r.Name)); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("could not read snapshot: %s", err)
+	}
+
+	var members []peers.Peer
+	if err = json.Unmarshal(snap.Peers, &members); err != nil {
+		return fmt.Errorf("could not parse replicas of snapshot: %s", err)
+	}
+
+	if err = r.logs.Install(snap.Executed, snap.Sequence, nil); err != nil {
+		return err
+	}
+
+	r.setMembership(snap.Epoch, members)
+	r.thrifty = r.config.GetThrifty()
+	r.restoreSnapshot(snap)
+	r.snapshot = snap
+
+	r.log.Info("restored snapshot at epoch %d with %d keys", snap.Epoch, len(snap.Data))
+	return nil
+}
+
+//===========================================================================
+// State Transfer
+//===========================================================================
+
+// Transfer the latest snapshot and the instances that have not been executed at the
+// snapshot to the remote replica, split into chunks that are sent in order on the
+// consensus stream so that the remote receives later messages after the transfer. The
+// snapshot is not transferred again until the transfer times out or is installed.
+func (r *Replica) transfer(pid uint32) {
+	remote, ok := r.remotes[pid]
+	if !ok {
+		return
+	}
+
+	if started, ok := r.transfers[pid]; ok && time.Since(started) < TransferTimeout {
+		return
+	}
+
+	r.updateSnapshot(r.logs.Executed())
+
+	data, err := proto.Marshal(&pb.Transfer{Snapshot: r.snapshot, Tail: r.logs.tail(r.snapshot.Executed)})
+	if err != nil {
+		r.log.Warn("could not marshal snapshot transfer: %s", err)
+		return
+	}
+
+	r.transfers[pid] = time.Now()
+	r.log.With(Fields{FieldPeer: remote.Name}).Info("transferring %d byte snapshot to %s", len(data), remote.Name)
+
+	for offset := 0; offset < len(data); offset += SnapshotChunkSize {
+		end := offset + SnapshotChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+
+		remote.Send(pb.WrapSnapshotRequest(r.Name, &pb.SnapshotRequest{
+			Offset: uint64(offset),
+			Size:   uint64(len(data)),
+			Data:   data[offset:end],
+		}))
+	}
+}
+
+// Receive a chunk of a snapshot transfer from a remote replica, installing the transfer
+// once every chunk has been received. The reply reports the executed frontier of the
+// replica so that the remote knows when the replica has caught up.
+func (r *Replica) onSnapshotRequest(e Event) (err error) {
+	req := e.Value().(*pb.SnapshotRequest)
+	peer := e.(*event).peer
+	rep := &pb.SnapshotReply{}
+
+	if err = r.receive(peer, req, rep); err != nil {
+		r.log.With(Fields{FieldPeer: peer}).Caution("could not install snapshot from %s: %s", peer, err)
+		rep.Error = err.Error()
+	}
+
+	rep.Executed = r.logs.Executed()
+	e.Source().(chan *pb.PeerReply) <- pb.WrapSnapshotReply(r.Name, rep)
+	return nil
+}
+
+// Append the chunk to the transfer received from the peer and install the transfer if
+// it is complete. Chunks must be received in order, otherwise the transfer is dropped.
+func (r *Replica) receive(peer string, req *pb.SnapshotRequest, rep *pb.SnapshotReply) error {
+	data := r.installs[peer]
+	if req.Offset == 0 {
+		data = nil
+	}
+
+	if req.Offset != uint64(len(data)) {
+		delete(r.installs, peer)
+		return fmt.Errorf("received chunk at offset %d, expected offset %d", req.Offset, len(data))
+	}
+
+	data = append(data, req.Data...)
+	rep.Offset = uint64(len(data))
+	if rep.Offset < req.Size {
+		r.installs[peer] = data
+		return nil
+	}
+	delete(r.installs, peer)
+
+	transfer := new(pb.Transfer)
+	if err := proto.Unmarshal(data, transfer); err != nil {
+		return err
+	}
+
+	if err := r.install(transfer); err != nil {
+		return err
+	}
+
+	rep.Installed = true
+	r.log.With(Fields{FieldPeer: peer}).Info("installed snapshot from %s with %d instances", peer, len(transfer.Tail))
+	return nil
+}
+
+// Install the snapshot and the instances of the transfer, replacing the state machine,
+// the membership, and the log of the replica, then execute the committed instances of
+// the transfer. The snapshot is only installed if it is ahead of the replica in every
+// log or in a later membership epoch, since the replica would otherwise lose instances
+// that it has already executed.
+func (r *Replica) install(transfer *pb.Transfer) (err error) {
+	snap := transfer.Snapshot
+	if snap == nil {
+		return errors.New("transfer does not contain a snapshot")
+	}
+
+	executed := r.logs.Executed()
+	if (frontierEqual(snap.Executed, executed) && snap.Epoch <= r.epoch) || !frontierCovers(snap.Executed, executed) {
+		return fmt.Errorf("snapshot at %v is not ahead of executed frontier %v", snap.Executed, executed)
+	}
+
+	var members []peers.Peer
+	if err = json.Unmarshal(snap.Peers, &members); err != nil {
+		return fmt.Errorf("could not parse replicas of snapshot: %s", err)
+	}
+
+	// The log is installed first since it is not modified if the transfer is invalid
+	if err = r.logs.Install(snap.Executed, snap.Sequence, transfer.Tail); err != nil {
+		return err
+	}
+
+	r.setMembership(snap.Epoch, members)
+	r.restoreSnapshot(snap)
+	r.snapshot = snap
+	if err = r.writeSnapshot(); err != nil {
+		r.log.Warn("could not write snapshot: %s", err)
+	}
+
+	r.releaseClients()
+	r.execute()
+	return nil
+}
+
+// Reply to the clients waiting on instances of this replica that are covered by the
+// installed snapshot, since those instances are not executed by this replica and the
+// result of the request is not known. The reply does not contain an error, so the
+// client retries the request, which is deduplicated by the restored client sessions.
+func (r *Replica) releaseClients() {
+	pending := make(map[uint64]bool)
+	for _, inst := range r.logs.tail(r.logs.Executed()) {
+		if inst.Replica == r.PID {
+			for _, op := range inst.Ops {
+				pending[op.Request] = true
+			}
+		}
+	}
+
+	for request, source := range r.clients {
+		if !pending[request] {
+			source <- &pb.ProposeReply{Success: false}
+			delete(r.clients, request)
+		}
+	}
+}
+
+// Handle the reply to a chunk of a snapshot transfer, recording the executed frontier
+// of the remote replica. Once the transfer is installed the snapshot can be transferred
+// again if the remote falls behind the log again.
+func (r *Replica) onSnapshotReply(e Event) (err error) {
+	rep := e.Value().(*pb.SnapshotReply)
+	peer, ok := r.config.lookupPeer(e.(*event).peer)
+	if !ok {
+		return nil
+	}
+
+	if rep.Error != "" {
+		r.log.With(Fields{FieldPeer: peer.Name}).Caution("%s did not install snapshot: %s", peer.Name, rep.Error)
+	}
+
+	if rep.Installed {
+		delete(r.transfers, peer.PID)
+	}
+
+	r.observeFrontier(peer.PID, rep.Executed)
+	return nil
+}
+
+// SnapshotPath returns the path of the snapshot of the named replica in the directory.
+func SnapshotPath(dir, name string) string {
+	return filepath.Join(dir, name+snapshotExt)
+}
+
+// ReadSnapshot reads the snapshot at the specified path.
+func ReadSnapshot(path string) (*pb.Snapshot, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	snap := new(pb.Snapshot)
+	if err = proto.Unmarshal(data, snap); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// Returns true if both frontiers have the same slot for every replica.
+func frontierEqual(a, b map[uint32]uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for pid, slot := range a {
+		if other, ok := b[pid]; !ok || other != slot {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns true if the first frontier is at or after the second for every replica.
+func frontierCovers(a, b map[uint32]uint64) bool {
+	for pid, slot := range b {
+		if a[pid] < slot {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,223 @@
+package epaxos_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/bbengfort/epaxos"
+	"github.com/bbengfort/epaxos/pb"
+	"google.golang.org/grpc"
+)
+
+var _ = Describe("Snapshots", func() {
+
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "epaxos-snapshots")
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("should snapshot the state and truncate executed instances", func() {
+		network := runNetwork(51264, withAdmin, func(conf *Config) {
+			conf.Snapshot = "50ms"
+			conf.Data = dir
+		})
+
+		client, err := NewClient("alpha", &Config{Timeout: "2s", Token: adminToken, Peers: network})
+		Ω(err).ShouldNot(HaveOccurred())
+
+		for i := 0; i < 10; i++ {
+			Ω(client.Put(fmt.Sprintf("key%d", i), []byte("value"), false)).Should(Succeed())
+		}
+
+		// Every replica snapshots the state after executing the instances
+		var leader uint32
+		for _, peer := range network {
+			path := SnapshotPath(dir, peer.Name)
+			Eventually(func() uint64 {
+				snap, err := ReadSnapshot(path)
+				if err != nil {
+					return 0
+				}
+
+				var executed uint64
+				for pid, slot := range snap.Executed {
+					if slot > 0 {
+						leader = pid
+					}
+					executed += slot
+				}
+				return executed
+			}, "2s").Should(Equal(uint64(10)))
+
+			snap, err := ReadSnapshot(path)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(snap.Executed[leader]).Should(Equal(uint64(10)))
+			Ω(snap.Data).Should(HaveLen(10))
+			Ω(snap.Data[0].Key).Should(Equal("key0"))
+			Ω(snap.Sessions).Should(HaveLen(1))
+		}
+
+		// Once every replica has executed the instances they are truncated
+		Eventually(func() error {
+			_, err := client.Fetch(leader, 9)
+			return err
+		}, "2s").Should(Equal(ErrTruncated))
+
+		status, err := client.Status()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(status.Offsets[leader]).Should(Equal(uint64(10)))
+
+		// The replicas continue to execute new instances after truncation
+		Ω(client.Put("key0", []byte("updated"), false)).Should(Succeed())
+		inst, err := client.Fetch(leader, 10)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(inst.Status).Should(Equal(pb.Status_EXECUTED))
+		Ω(client.Get("key0")).Should(Equal([]byte("updated")))
+	})
+
+	It("should exchange executed frontiers with every replica in thrifty mode", func() {
+		network := runNetwork(64264, withAdmin, func(conf *Config) {
+			conf.Snapshot = "50ms"
+			conf.Thrifty = true
+		})
+
+		client, err := NewClient("alpha", &Config{Timeout: "2s", Token: adminToken, Peers: network[:1]})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(client.Put("foo", []byte("bar"), false)).Should(Succeed())
+
+		// Instances are only truncated once every replica has reported its frontier
+		Eventually(func() error {
+			_, err := client.Fetch(1, 0)
+			return err
+		}, "2s").Should(Equal(ErrTruncated))
+
+		Ω(client.Put("foo", []byte("baz"), false)).Should(Succeed())
+		Ω(client.Get("foo")).Should(Equal([]byte("baz")))
+	})
+
+	It("should transfer the snapshot to a replica that is behind the log", func() {
+		// Charlie is not running when the instances are executed
+		network := makeNetwork(52264, 3)
+		options := func(conf *Config) {
+			conf.Snapshot = "50ms"
+			conf.Data = dir
+		}
+		runReplica("alpha", network, withAdmin, options)
+		runReplica("bravo", network, withAdmin, options)
+
+		client, err := NewClient("alpha", &Config{Timeout: "2s", Token: adminToken, Peers: network[:1]})
+		Ω(err).ShouldNot(HaveOccurred())
+
+		for i := 0; i < 10; i++ {
+			Ω(client.Put(fmt.Sprintf("key%d", i), []byte("value"), false)).Should(Succeed())
+		}
+
+		// Report that charlie executed the instances before it went down so that the
+		// other replicas truncate their logs
+		for _, peer := range network[:2] {
+			conn, err := grpc.Dial(peer.Endpoint(false), grpc.WithInsecure())
+			Ω(err).ShouldNot(HaveOccurred())
+			defer conn.Close()
+
+			stream, err := pb.NewEpaxosClient(conn).Consensus(context.Background())
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(stream.Send(pb.WrapBeaconRequest("charlie", &pb.BeaconRequest{
+				QuorumMember: true,
+				Replica:      3,
+				Executed:     map[uint32]uint64{1: 10, 2: 0, 3: 0},
+			}))).Should(Succeed())
+			_, err = stream.Recv()
+			Ω(err).ShouldNot(HaveOccurred())
+		}
+
+		Eventually(func() error {
+			_, err := client.Fetch(1, 9)
+			return err
+		}, "2s").Should(Equal(ErrTruncated))
+
+		// Charlie restarts without any state and cannot catch up from the log
+		runReplica("charlie", network, withAdmin, options)
+		Eventually(func() uint64 {
+			snap, err := ReadSnapshot(SnapshotPath(dir, "charlie"))
+			if err != nil {
+				return 0
+			}
+			return snap.Executed[1]
+		}, "2s").Should(Equal(uint64(10)))
+
+		// Charlie continues to execute instances after installing the snapshot
+		Ω(client.Put("key0", []byte("updated"), false)).Should(Succeed())
+
+		charlie, err := NewClient("charlie", &Config{Timeout: "2s", Token: adminToken, Peers: network[2:]})
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Eventually(func() pb.Status {
+			inst, err := charlie.Fetch(1, 10)
+			if err != nil {
+				return pb.Status_INITIAL
+			}
+			return inst.Status
+		}, "2s").Should(Equal(pb.Status_EXECUTED))
+
+		Ω(charlie.Get("key0")).Should(Equal([]byte("updated")))
+		Ω(charlie.Get("key9")).Should(Equal([]byte("value")))
+	})
+
+	It("should restore the snapshot when a replica restarts", func() {
+		network := makeNetwork(63264, 3)
+		options := func(conf *Config) {
+			conf.Snapshot = "50ms"
+			conf.Data = dir
+		}
+
+		stop := runReplica("alpha", network, withAdmin, options)
+		runReplica("bravo", network, withAdmin, options)
+		runReplica("charlie", network, withAdmin, options)
+
+		client, err := NewClient("alpha", &Config{Timeout: "2s", Token: adminToken, Peers: network[:1]})
+		Ω(err).ShouldNot(HaveOccurred())
+
+		for i := 0; i < 10; i++ {
+			Ω(client.Put(fmt.Sprintf("key%d", i), []byte("value"), false)).Should(Succeed())
+		}
+
+		Eventually(func() uint64 {
+			snap, err := ReadSnapshot(SnapshotPath(dir, "alpha"))
+			if err != nil {
+				return 0
+			}
+			return snap.Executed[1]
+		}, "2s").Should(Equal(uint64(10)))
+
+		// Alpha resumes from its snapshot rather than from an empty log and store
+		stop()
+		runReplica("alpha", network, withAdmin, options)
+
+		client, err = NewClient("alpha", &Config{Timeout: "2s", Token: adminToken, Peers: network[:1]})
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Eventually(func() uint64 {
+			status, err := client.Status()
+			if err != nil {
+				return 0
+			}
+			return status.Offsets[1]
+		}, "2s").Should(Equal(uint64(10)))
+
+		Ω(client.Put("key0", []byte("updated"), false)).Should(Succeed())
+		Ω(client.Get("key0")).Should(Equal([]byte("updated")))
+		Ω(client.Get("key9")).Should(Equal([]byte("value")))
+	})
+})
@@ -0,0 +1,216 @@
+package epaxos
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/bbengfort/epaxos/pb"
+)
+
+// newStore creates an empty key/value store.
+func newStore() *store {
+	return &store{data: make(map[string][]byte), versions: make(map[string]uint64)}
+}
+
+// store is the key/value state machine that operations are applied to when their
+// instance is executed. The store is not thread safe and must only be modified by the
+// event loop; since instances are executed in the same order on every replica, the
+// store is identical on all replicas after the same instances are executed.
+//
+// Every modification of the store increments its revision, and the version of a key
+// is the revision of its last modification, so versions are never reused even if a
+// key is deleted and written again. A key that does not exist has version zero.
+type store struct {
+	data     map[string][]byte // the value of each key
+	versions map[string]uint64 // the revision each key was last modified at
+	revision uint64            // the number of modifications applied to the store
+}
+
+// result is the outcome of applying an operation or transaction to the store, which is
+// cached by the client session so that duplicate requests can be replied to without
+// reapplying them.
+type result struct {
+	key     string    // the key of the operation
+	value   []byte    // the value of the key for reads
+	version uint64    // the version of the key after the operation
+	err     string    // the error applying the operation, if any
+	results []*result // the results of the operations of a transaction
+}
+
+// reply creates the reply to the client for the result of the request executed in the
+// instance at the slot. The result of each operation is included in the reply and, if
+// the request has a single operation, its result is also the result of the reply.
+func (res *result) reply(slot uint64) *pb.ProposeReply {
+	rep := &pb.ProposeReply{
+		Success: res.err == "",
+		Error:   res.err,
+		Slot:    int64(slot),
+		Results: make([]*pb.Result, 0, len(res.results)),
+	}
+
+	for _, op := range res.results {
+		rep.Results = append(rep.Results, &pb.Result{
+			Success: op.err == "",
+			Error:   op.err,
+			Key:     op.key,
+			Value:   op.value,
+			Version: op.version,
+		})
+	}
+
+	if len(res.results) == 1 {
+		rep.Key, rep.Value, rep.Version = res.results[0].key, res.results[0].value, res.results[0].version
+	}
+	return rep
+}
+
+// transact applies the operations atomically: if a condition of the transaction is not
+// met or an operation cannot be applied, the operations that were already applied are
+// rolled back so that none of the operations are applied. Operations are applied in
+// order, so conditions and reads observe the earlier operations of the transaction.
+func (s *store) transact(ops []*pb.Operation) *result {
+	var (
+		txn      = &result{results: make([]*result, 0, len(ops))}
+		revision = s.revision
+		undo     = make(map[string]*entry)
+	)
+
+	for _, op := range ops {
+		// Record the state of the key before the transaction modifies it
+		if _, ok := undo[op.Key]; !ok {
+			undo[op.Key] = s.get(op.Key)
+		}
+
+		res := s.apply(op)
+		txn.results = append(txn.results, res)
+		if res.err != "" {
+			txn.err = res.err
+			break
+		}
+	}
+
+	if txn.err == "" {
+		return txn
+	}
+
+	// Roll back the transaction and mark the other operations as aborted
+	for key, prev := range undo {
+		s.set(key, prev)
+	}
+	s.revision = revision
+
+	for idx, op := range ops {
+		if idx >= len(txn.results) {
+			txn.results = append(txn.results, &result{key: op.Key})
+		}
+
+		if res := txn.results[idx]; res.err == "" {
+			res.value, res.version, res.err = nil, s.versions[op.Key], "transaction aborted"
+		}
+	}
+	return txn
+}
+
+// apply the operation to the store and return the result. Conditional operations
+// whose conditions are not met return an error result without modifying the store.
+func (s *store) apply(op *pb.Operation) *result {
+	current, exists := s.data[op.Key]
+	version := s.versions[op.Key]
+
+	switch op.Type {
+	case pb.AccessType_NULL:
+		return &result{key: op.Key}
+	case pb.AccessType_READ:
+		return &result{key: op.Key, value: current, version: version}
+	case pb.AccessType_WRITE:
+		return &result{key: op.Key, version: s.put(op.Key, op.Value)}
+	case pb.AccessType_WRITEREAD:
+		return &result{key: op.Key, value: op.Value, version: s.put(op.Key, op.Value)}
+	case pb.AccessType_DELETE:
+		if exists {
+			s.revision++
+			delete(s.data, op.Key)
+			delete(s.versions, op.Key)
+		}
+		return &result{key: op.Key}
+	case pb.AccessType_CHECK:
+		if version != op.Version {
+			return &result{key: op.Key, version: version, err: fmt.Sprintf("key %q is at version %d not %d", op.Key, version, op.Version)}
+		}
+		return &result{key: op.Key, version: version}
+	case pb.AccessType_CAS:
+		if len(op.Expect) > 0 {
+			if !exists || !bytes.Equal(current, op.Expect) {
+				return &result{key: op.Key, value: current, version: version, err: fmt.Sprintf("key %q does not have the expected value", op.Key)}
+			}
+		} else if version != op.Version {
+			return &result{key: op.Key, value: current, version: version, err: fmt.Sprintf("key %q is at version %d not %d", op.Key, version, op.Version)}
+		}
+		return &result{key: op.Key, value: op.Value, version: s.put(op.Key, op.Value)}
+	case pb.AccessType_PUT_IF_ABSENT:
+		if exists {
+			return &result{key: op.Key, value: current, version: version, err: fmt.Sprintf("key %q already exists", op.Key)}
+		}
+		return &result{key: op.Key, value: op.Value, version: s.put(op.Key, op.Value)}
+	case pb.AccessType_INCREMENT:
+		return s.increment(op, current, version)
+	default:
+		return &result{key: op.Key, version: version, err: fmt.Sprintf("cannot apply %s operation", op.Type)}
+	}
+}
+
+// Add the integer delta of the operation to the integer value of the key, which is
+// stored as a decimal string. A key that does not exist has the value zero.
+func (s *store) increment(op *pb.Operation, current []byte, version uint64) *result {
+	delta := int64(1)
+	if len(op.Value) > 0 {
+		var err error
+		if delta, err = strconv.ParseInt(string(op.Value), 10, 64); err != nil {
+			return &result{key: op.Key, value: current, version: version, err: fmt.Sprintf("cannot increment by %q: not an integer", op.Value)}
+		}
+	}
+
+	var total int64
+	if len(current) > 0 {
+		var err error
+		if total, err = strconv.ParseInt(string(current), 10, 64); err != nil {
+			return &result{key: op.Key, value: current, version: version, err: fmt.Sprintf("cannot increment key %q: value is not an integer", op.Key)}
+		}
+	}
+
+	value := []byte(strconv.FormatInt(total+delta, 10))
+	return &result{key: op.Key, value: value, version: s.put(op.Key, value)}
+}
+
+// entry is the value and version of a key, used to roll back transactions.
+type entry struct {
+	value   []byte
+	version uint64
+	exists  bool
+}
+
+// Get the entry of the key.
+func (s *store) get(key string) *entry {
+	value, exists := s.data[key]
+	return &entry{value: value, version: s.versions[key], exists: exists}
+}
+
+// Set the key to the entry without modifying the revision of the store.
+func (s *store) set(key string, e *entry) {
+	if !e.exists {
+		delete(s.data, key)
+		delete(s.versions, key)
+		return
+	}
+	s.data[key] = e.value
+	s.versions[key] = e.version
+}
+
+// Set the value of the key at the next revision of the store and return its version.
+func (s *store) put(key string, value []byte) uint64 {
+	s.revision++
+	s.data[key] = value
+	s.versions[key] = s.revision
+	return s.revision
+}
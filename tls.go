@@ -0,0 +1,163 @@
+package epaxos
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// TLSConfig specifies the certificate authority used to verify remote hosts along
+// with the certificate and private key that identify the local host. Replicas must
+// specify all three paths to enable mutual TLS; clients only require the certificate
+// authority to verify replicas but may present a certificate to identify themselves.
+type TLSConfig struct {
+	CAFile   string `required:"false" validate:"path" json:"ca_file,omitempty"`   // certificate authority used to verify remote hosts
+	CertFile string `required:"false" validate:"path" json:"cert_file,omitempty"` // certificate that identifies the local host
+	KeyFile  string `required:"false" validate:"path" json:"key_file,omitempty"`  // private key of the local host's certificate
+}
+
+// Enabled returns true if any of the TLS paths are configured.
+func (c *TLSConfig) Enabled() bool {
+	return c.CAFile != "" || c.CertFile != "" || c.KeyFile != ""
+}
+
+// Validate that the certificate authority is specified if TLS is enabled and that the
+// certificate and key are specified together.
+func (c *TLSConfig) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+
+	if c.CAFile == "" {
+		return errors.New("TLS requires a certificate authority to verify remote hosts")
+	}
+
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return errors.New("TLS requires both a certificate and a private key")
+	}
+
+	return nil
+}
+
+// ServerConfig returns the TLS configuration for a replica's server. Replicas must
+// have a certificate; client certificates are verified by the certificate authority
+// if they are given but are only required for peer to peer consensus streams.
+func (c *TLSConfig) ServerConfig() (conf *tls.Config, err error) {
+	if c.CertFile == "" {
+		return nil, errors.New("TLS requires a certificate to identify the replica")
+	}
+
+	if conf, err = c.config(); err != nil {
+		return nil, err
+	}
+
+	conf.ClientCAs = conf.RootCAs
+	conf.RootCAs = nil
+	conf.ClientAuth = tls.VerifyClientCertIfGiven
+	return conf, nil
+}
+
+// ClientConfig returns the TLS configuration to connect to the named replica; the
+// certificate presented by the replica must be issued to its name by the certificate
+// authority. If a certificate is configured it is presented to identify the client.
+func (c *TLSConfig) ClientConfig(server string) (conf *tls.Config, err error) {
+	if conf, err = c.config(); err != nil {
+		return nil, err
+	}
+
+	conf.ServerName = server
+	return conf, nil
+}
+
+// Loads the certificate authority and the certificate if one is configured.
+func (c *TLSConfig) config() (conf *tls.Config, err error) {
+	if err = c.Validate(); err != nil {
+		return nil, err
+	}
+
+	conf = &tls.Config{MinVersion: tls.VersionTLS12}
+
+	var pem []byte
+	if pem, err = ioutil.ReadFile(c.CAFile); err != nil {
+		return nil, fmt.Errorf("could not read certificate authority: %s", err)
+	}
+
+	conf.RootCAs = x509.NewCertPool()
+	if !conf.RootCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", c.CAFile)
+	}
+
+	if c.CertFile != "" {
+		var cert tls.Certificate
+		if cert, err = tls.LoadX509KeyPair(c.CertFile, c.KeyFile); err != nil {
+			return nil, fmt.Errorf("could not load certificate: %s", err)
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+
+	return conf, nil
+}
+
+//===========================================================================
+// Transport Credentials
+//===========================================================================
+
+// GetServerOptions returns the gRPC server options to serve with TLS if it is
+// configured, otherwise no options are returned and the server is plaintext.
+func (c *Config) GetServerOptions() ([]grpc.ServerOption, error) {
+	if !c.TLS.Enabled() {
+		return nil, nil
+	}
+
+	conf, err := c.TLS.ServerConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return []grpc.ServerOption{grpc.Creds(credentials.NewTLS(conf))}, nil
+}
+
+// GetDialOption returns the gRPC dial option to connect to the named replica with TLS
+// if it is configured, otherwise an insecure connection option is returned.
+func (c *Config) GetDialOption(server string) (grpc.DialOption, error) {
+	if !c.TLS.Enabled() {
+		return grpc.WithInsecure(), nil
+	}
+
+	conf, err := c.TLS.ClientConfig(server)
+	if err != nil {
+		return nil, err
+	}
+
+	return grpc.WithTransportCredentials(credentials.NewTLS(conf)), nil
+}
+
+// IdentifyPeer returns the name of the configured peer that the verified certificate
+// was issued to, matching the subject alternative DNS names then the common name of
+// the certificate against the names of the peers in the configuration.
+func (c *Config) IdentifyPeer(cert *x509.Certificate) (string, error) {
+	for _, name := range certNames(cert) {
+		if _, ok := c.lookupPeer(name); ok {
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf("certificate for %q does not identify a configured peer", cert.Subject.CommonName)
+}
+
+// Returns the names that the certificate was issued to in the order they are matched:
+// the subject alternative DNS names then the common name.
+func certNames(cert *x509.Certificate) []string {
+	names := make([]string, 0, len(cert.DNSNames)+1)
+	for _, name := range append(cert.DNSNames, cert.Subject.CommonName) {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
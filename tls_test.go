@@ -0,0 +1,268 @@
+package epaxos_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/bbengfort/epaxos"
+	"github.com/bbengfort/epaxos/pb"
+	"github.com/bbengfort/x/peers"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var _ = Describe("TLS", func() {
+
+	var (
+		dir  string
+		ca   *x509.Certificate
+		key  *ecdsa.PrivateKey
+		conf *Config
+	)
+
+	// Writes a certificate and key issued by the test CA to the temporary directory.
+	issue := func(name string, dnsNames ...string) *TLSConfig {
+		priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(time.Now().UnixNano()),
+			Subject:      pkix.Name{CommonName: name},
+			DNSNames:     dnsNames,
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		}
+
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &priv.PublicKey, key)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		keyder, err := x509.MarshalECPrivateKey(priv)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		tlsc := &TLSConfig{
+			CAFile:   filepath.Join(dir, "ca.pem"),
+			CertFile: filepath.Join(dir, name+".pem"),
+			KeyFile:  filepath.Join(dir, name+".key"),
+		}
+
+		Ω(ioutil.WriteFile(tlsc.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)).Should(Succeed())
+		Ω(ioutil.WriteFile(tlsc.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyder}), 0600)).Should(Succeed())
+		return tlsc
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "epaxos-tls")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		tmpl := &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: "epaxos test ca"},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(time.Hour),
+			KeyUsage:              x509.KeyUsageCertSign,
+			BasicConstraintsValid: true,
+			IsCA:                  true,
+		}
+
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		ca, err = x509.ParseCertificate(der)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(ioutil.WriteFile(filepath.Join(dir, "ca.pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)).Should(Succeed())
+
+		conf = &Config{
+			Timeout:  "500ms",
+			LogLevel: 3,
+			Peers: []peers.Peer{
+				{PID: 1, Name: "alpha", Port: 3264},
+				{PID: 2, Name: "bravo", Port: 3265},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("should validate that the TLS paths exist", func() {
+		conf.TLS = *issue("alpha", "alpha")
+		Ω(conf.Validate()).Should(Succeed())
+
+		conf.TLS.KeyFile = filepath.Join(dir, "missing.key")
+		Ω(conf.Validate()).Should(MatchError(ContainSubstring("could not validate TLS.KeyFile")))
+	})
+
+	It("should require a CA and both a certificate and key", func() {
+		tlsc := issue("alpha", "alpha")
+
+		conf.TLS = TLSConfig{CertFile: tlsc.CertFile, KeyFile: tlsc.KeyFile}
+		Ω(conf.Validate()).Should(HaveOccurred())
+
+		conf.TLS = TLSConfig{CAFile: tlsc.CAFile, CertFile: tlsc.CertFile}
+		Ω(conf.Validate()).Should(HaveOccurred())
+
+		conf.TLS = TLSConfig{CAFile: tlsc.CAFile}
+		Ω(conf.Validate()).Should(Succeed())
+
+		_, err := conf.TLS.ServerConfig()
+		Ω(err).Should(HaveOccurred())
+	})
+
+	It("should identify configured peers by certificate", func() {
+		tlsc := issue("alpha", "alpha.example.com", "alpha")
+		cert, err := tls.LoadX509KeyPair(tlsc.CertFile, tlsc.KeyFile)
+		Ω(err).ShouldNot(HaveOccurred())
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Ω(conf.IdentifyPeer(leaf)).Should(Equal("alpha"))
+
+		tlsc = issue("bravo")
+		cert, err = tls.LoadX509KeyPair(tlsc.CertFile, tlsc.KeyFile)
+		Ω(err).ShouldNot(HaveOccurred())
+		leaf, err = x509.ParseCertificate(cert.Certificate[0])
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Ω(conf.IdentifyPeer(leaf)).Should(Equal("bravo"))
+
+		tlsc = issue("mallory", "mallory")
+		cert, err = tls.LoadX509KeyPair(tlsc.CertFile, tlsc.KeyFile)
+		Ω(err).ShouldNot(HaveOccurred())
+		leaf, err = x509.ParseCertificate(cert.Certificate[0])
+		Ω(err).ShouldNot(HaveOccurred())
+
+		_, err = conf.IdentifyPeer(leaf)
+		Ω(err).Should(HaveOccurred())
+	})
+
+	It("should mutually authenticate replicas", func() {
+		server, err := issue("alpha", "alpha").ServerConfig()
+		Ω(err).ShouldNot(HaveOccurred())
+
+		client, err := issue("bravo", "bravo").ClientConfig("alpha")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		sconn, cconn := net.Pipe()
+		defer sconn.Close()
+		defer cconn.Close()
+
+		srv := tls.Server(sconn, server)
+		errc := make(chan error, 1)
+		go func() { errc <- srv.Handshake() }()
+
+		Ω(tls.Client(cconn, client).Handshake()).Should(Succeed())
+		Ω(<-errc).Should(Succeed())
+
+		chains := srv.ConnectionState().VerifiedChains
+		Ω(chains).Should(HaveLen(1))
+		Ω(conf.IdentifyPeer(chains[0][0])).Should(Equal("bravo"))
+	})
+
+	It("should reject a replica presenting another name", func() {
+		server, err := issue("alpha", "alpha").ServerConfig()
+		Ω(err).ShouldNot(HaveOccurred())
+
+		client, err := issue("bravo", "bravo").ClientConfig("charlie")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		sconn, cconn := net.Pipe()
+		defer sconn.Close()
+		defer cconn.Close()
+
+		go tls.Server(sconn, server).Handshake()
+		Ω(tls.Client(cconn, client).Handshake()).Should(HaveOccurred())
+	})
+
+	It("should authenticate removed replicas until the instances of their epochs are committed", func() {
+		network := makeNetwork(62264, 3)
+		certs := make(map[string]*TLSConfig)
+		for _, peer := range network {
+			tlsc := issue(peer.Name, peer.Name)
+			certs[peer.Name] = tlsc
+			runReplica(peer.Name, network, withAdmin, func(conf *Config) { conf.TLS = *tlsc })
+		}
+
+		// Open consensus streams to bravo with the certificate of charlie
+		opt, err := (&Config{TLS: *certs["charlie"]}).GetDialOption("bravo")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		conn, err := grpc.Dial(fmt.Sprintf("127.0.0.1:%d", network[1].Port), opt, grpc.WithBlock(), grpc.WithTimeout(2*time.Second))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer conn.Close()
+
+		send := func(req *pb.PeerRequest) (*pb.PeerReply, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			stream, err := pb.NewEpaxosClient(conn).Consensus(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			if err = stream.Send(req); err != nil {
+				return nil, err
+			}
+			return stream.Recv()
+		}
+
+		// Bravo does not execute the removal of charlie until the pause ends
+		bclient, err := NewClient("bravo", &Config{Timeout: "2s", Token: adminToken, Peers: network[1:2], TLS: TLSConfig{CAFile: certs["bravo"].CAFile}})
+		Ω(err).ShouldNot(HaveOccurred())
+		_, err = bclient.Propose(pb.AccessType_PAUSE, PhaseExecute, []byte("500ms"))
+		Ω(err).ShouldNot(HaveOccurred())
+
+		client, err := NewClient("alpha", &Config{Timeout: "2s", Token: adminToken, Peers: network[:1], TLS: TLSConfig{CAFile: certs["alpha"].CAFile}})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(client.RemovePeer("charlie")).Should(Succeed())
+
+		// Charlie leads an instance in epoch 0 that the removal does not depend on and
+		// that is not committed when bravo executes the removal
+		inst := &pb.Instance{Replica: network[2].PID, Slot: 0, Epoch: 0, Seq: 1, Deps: map[uint32]uint64{}}
+		rep, err := send(pb.WrapPreacceptRequest("charlie", &pb.PreacceptRequest{Inst: inst}))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(rep.Success).Should(BeTrue())
+
+		Eventually(func() uint64 {
+			status, err := bclient.Status()
+			if err != nil {
+				return 0
+			}
+			return status.Epoch
+		}, "2s").Should(Equal(uint64(1)))
+
+		// Charlie is still authenticated to commit the instance it led as a member
+		inst.Status = pb.Status_COMMITTED
+		_, err = send(pb.WrapCommitRequest("charlie", &pb.CommitRequest{Inst: inst}))
+		Ω(err).ShouldNot(HaveOccurred())
+
+		// Once every instance of epoch 0 is committed, charlie is no longer authenticated
+		Eventually(func() codes.Code {
+			_, err := send(pb.WrapBeaconRequest("charlie", &pb.BeaconRequest{}))
+			return status.Code(err)
+		}, "2s").Should(Equal(codes.Unauthenticated))
+	})
+})
@@ -0,0 +1,238 @@
+package epaxos
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/bbengfort/epaxos/pb"
+	"github.com/golang/protobuf/proto"
+)
+
+//===========================================================================
+// Event Trace Recorder
+//===========================================================================
+
+// NewRecorder creates a trace file at the specified path, truncating it if it
+// already exists, and returns a recorder that writes handled events to it.
+func NewRecorder(path string) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not create trace file: %s", err)
+	}
+
+	return &Recorder{
+		start: time.Now(),
+		file:  file,
+		buf:   bufio.NewWriter(file),
+	}, nil
+}
+
+// Recorder writes events handled by a replica to a trace file so that the events
+// can be replayed offline to reproduce the state of the replica's log. Each event is
+// written as a length prefixed TraceRecord with the time elapsed since the recorder
+// was created. The recorder is not thread safe and must be used by the event loop.
+// Records are flushed as they're written so the trace is complete if the replica fails.
+type Recorder struct {
+	start time.Time     // used to compute the monotonic time of events
+	file  *os.File      // the open trace file
+	buf   *bufio.Writer // buffer writes to the trace file
+}
+
+// Record the event to the trace file. Read-only admin events and configuration reloads,
+// which do not change the state of the log, are not recorded.
+func (t *Recorder) Record(e Event) (err error) {
+	if !traceable(e.Type()) {
+		return nil
+	}
+
+	record := &pb.TraceRecord{
+		Type: uint32(e.Type()),
+		Time: int64(time.Since(t.start)),
+	}
+
+	if evt, ok := e.(*event); ok {
+		record.Peer = evt.peer
+	}
+
+	switch val := e.Value().(type) {
+	case proto.Message:
+		if record.Value, err = proto.Marshal(val); err != nil {
+			return err
+		}
+	case error:
+		record.Value = []byte(val.Error())
+	case nil:
+	default:
+		return ErrEventTypeError
+	}
+
+	var data []byte
+	if data, err = proto.Marshal(record); err != nil {
+		return err
+	}
+
+	prefix := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(prefix, uint64(len(data)))
+	if _, err = t.buf.Write(prefix[:n]); err != nil {
+		return err
+	}
+
+	if _, err = t.buf.Write(data); err != nil {
+		return err
+	}
+
+	// Flush every record so that the trace survives a crash of the replica.
+	return t.buf.Flush()
+}
+
+// Close the recorder, flushing any buffered records to the trace file.
+func (t *Recorder) Close() error {
+	if err := t.buf.Flush(); err != nil {
+		t.file.Close()
+		return err
+	}
+	return t.file.Close()
+}
+
+//===========================================================================
+// Event Trace Replay
+//===========================================================================
+
+// ReadTrace reads all of the records from the trace file at the specified path.
+func ReadTrace(path string) (records []*pb.TraceRecord, err error) {
+	var file *os.File
+	if file, err = os.Open(path); err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	buf := bufio.NewReader(file)
+	records = make([]*pb.TraceRecord, 0)
+
+	for {
+		var size uint64
+		if size, err = binary.ReadUvarint(buf); err != nil {
+			if err == io.EOF {
+				return records, nil
+			}
+			return nil, err
+		}
+
+		data := make([]byte, size)
+		if _, err = io.ReadFull(buf, data); err != nil {
+			return nil, fmt.Errorf("could not read trace record %d: %s", len(records), err)
+		}
+
+		record := new(pb.TraceRecord)
+		if err = proto.Unmarshal(data, record); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+}
+
+// Replay the events in the trace file at the specified path on a new replica created
+// from the configuration. Networking is stubbed out, so messages to remote peers and
+// replies to clients and peers are discarded, and snapshots are not written to disk.
+// Events are handled in the order they were recorded and the replica is returned when
+// all events have been handled so that its log can be inspected. If handling an event
+// returns an error, replay stops and the replica is returned with the error.
+func Replay(path string, options *Config) (replica *Replica, err error) {
+	var records []*pb.TraceRecord
+	if records, err = ReadTrace(path); err != nil {
+		return nil, err
+	}
+
+	// The trace is replayed from the initial state rather than from the snapshot
+	if replica, err = newReplica(options, false); err != nil {
+		return nil, err
+	}
+
+	// Stub out networking so that broadcasts do not send messages to remotes.
+	replica.remotes = make(Remotes)
+	replica.thrifty = nil
+
+	// Do not replace the snapshot of the replica that recorded the trace.
+	replica.config.Data = ""
+
+	for idx, record := range records {
+		var e *event
+		if e, err = replayEvent(record); err != nil {
+			return replica, fmt.Errorf("could not replay trace record %d: %s", idx, err)
+		}
+
+		if err = replica.Handle(e); err != nil {
+			return replica, err
+		}
+	}
+
+	return replica, nil
+}
+
+// Create an event from the trace record with a buffered source to discard replies.
+func replayEvent(record *pb.TraceRecord) (e *event, err error) {
+	e = &event{etype: EventType(record.Type), peer: record.Peer}
+
+	var msg proto.Message
+	switch e.etype {
+	case ErrorEvent:
+		e.value = errors.New(string(record.Value))
+		return e, nil
+	case ProposeRequestEvent:
+		msg = new(pb.ProposeRequest)
+		e.source = make(chan *pb.ProposeReply, 1)
+	case PreacceptRequestEvent:
+		msg = new(pb.PreacceptRequest)
+		e.source = make(chan *pb.PeerReply, 1)
+	case PreacceptReplyEvent:
+		msg = new(pb.PreacceptReply)
+	case AcceptRequestEvent:
+		msg = new(pb.AcceptRequest)
+		e.source = make(chan *pb.PeerReply, 1)
+	case AcceptReplyEvent:
+		msg = new(pb.AcceptReply)
+	case CommitRequestEvent:
+		msg = new(pb.CommitRequest)
+		e.source = make(chan *pb.PeerReply, 1)
+	case CommitReplyEvent:
+		msg = new(pb.CommitReply)
+	case BeaconRequestEvent:
+		msg = new(pb.BeaconRequest)
+		e.source = make(chan *pb.PeerReply, 1)
+	case BeaconReplyEvent:
+		msg = new(pb.BeaconReply)
+	case ResumeEvent:
+		msg = new(pb.Resume)
+	case SnapshotEvent:
+		return e, nil
+	case SnapshotRequestEvent:
+		msg = new(pb.SnapshotRequest)
+		e.source = make(chan *pb.PeerReply, 1)
+	case SnapshotReplyEvent:
+		msg = new(pb.SnapshotReply)
+	default:
+		return nil, fmt.Errorf("cannot replay %s event", e.etype)
+	}
+
+	if err = proto.Unmarshal(record.Value, msg); err != nil {
+		return nil, err
+	}
+
+	e.value = msg
+	return e, nil
+}
+
+// Returns true if the event type modifies the state of the replica and is recorded.
+func traceable(etype EventType) bool {
+	switch etype {
+	case StatusRequestEvent, FetchRequestEvent, WatchRequestEvent, UnwatchRequestEvent, GraphRequestEvent, ReloadEvent:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,127 @@
+package epaxos_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/bbengfort/epaxos"
+	"github.com/bbengfort/epaxos/pb"
+)
+
+//===========================================================================
+// Mock Recorded Event
+//===========================================================================
+
+type recordedEvent struct {
+	etype EventType
+	value interface{}
+}
+
+func (e *recordedEvent) Type() EventType {
+	return e.etype
+}
+
+func (e *recordedEvent) Source() interface{} {
+	return nil
+}
+
+func (e *recordedEvent) Value() interface{} {
+	return e.value
+}
+
+var _ = Describe("Trace", func() {
+
+	var tmpdir, path string
+	var config *Config
+
+	BeforeEach(func() {
+		var err error
+		tmpdir, err = ioutil.TempDir("", "epaxos-trace")
+		Ω(err).ShouldNot(HaveOccurred())
+		path = filepath.Join(tmpdir, "trace.pb")
+
+		data, err := ioutil.ReadFile("testdata/config.json")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(json.Unmarshal(data, &config)).Should(Succeed())
+		config.Peers = config.Peers[:3]
+		config.LogLevel = int(LogSilent)
+
+		// Record two local proposals and a preaccept from a remote replica
+		recorder, err := NewRecorder(path)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		events := []*recordedEvent{
+			{ProposeRequestEvent, &pb.ProposeRequest{Identity: "test", Op: &pb.Operation{Type: pb.AccessType_WRITE, Key: "foo", Value: []byte("a")}}},
+			{PreacceptRequestEvent, &pb.PreacceptRequest{Inst: &pb.Instance{Replica: 1, Slot: 0, Seq: 1, Deps: map[uint32]uint64{}, Ops: []*pb.Operation{{Type: pb.AccessType_WRITE, Key: "foo", Value: []byte("b")}}}}},
+			{ProposeRequestEvent, &pb.ProposeRequest{Identity: "test", Op: &pb.Operation{Type: pb.AccessType_WRITE, Key: "bar", Value: []byte("c")}}},
+			{StatusRequestEvent, &pb.StatusRequest{}},
+		}
+
+		for _, e := range events {
+			Ω(recorder.Record(e)).Should(Succeed())
+		}
+		Ω(recorder.Close()).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		Ω(os.RemoveAll(tmpdir)).Should(Succeed())
+	})
+
+	It("should record handled events except for admin events", func() {
+		records, err := ReadTrace(path)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(records).Should(HaveLen(3))
+
+		Ω(EventType(records[0].Type)).Should(Equal(ProposeRequestEvent))
+		Ω(EventType(records[1].Type)).Should(Equal(PreacceptRequestEvent))
+		Ω(EventType(records[2].Type)).Should(Equal(ProposeRequestEvent))
+		Ω(records[2].Time).Should(BeNumerically(">=", records[0].Time))
+	})
+
+	It("should replay the events to reproduce the log", func() {
+		replica, err := Replay(path, config)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		logs := replica.Logs()
+		Ω(logs.NextSlots()).Should(Equal(map[uint32]uint64{1: 1, 2: 2, 3: 0}))
+
+		inst, err := logs.Get(1, 0)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(inst.Ops[0].Value).Should(Equal([]byte("b")))
+		Ω(inst.Deps).Should(HaveKeyWithValue(uint32(2), uint64(0)))
+
+		inst, err = logs.Get(2, 1)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(inst.Ops[0].Key).Should(Equal("bar"))
+	})
+
+	It("should ignore accept replies for instances that are not in the log", func() {
+		recorder, err := NewRecorder(path + ".accept")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(recorder.Record(&recordedEvent{AcceptReplyEvent, &pb.AcceptReply{Slot: 42}})).Should(Succeed())
+		Ω(recorder.Close()).Should(Succeed())
+
+		_, err = Replay(path+".accept", config)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("should not write snapshots when replaying the events", func() {
+		recorder, err := NewRecorder(path + ".snapshot")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(recorder.Record(&recordedEvent{SnapshotEvent, nil})).Should(Succeed())
+		Ω(recorder.Close()).Should(Succeed())
+
+		config.Data = tmpdir
+		_, err = Replay(path+".snapshot", config)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		_, err = os.Stat(SnapshotPath(tmpdir, config.Name))
+		Ω(os.IsNotExist(err)).Should(BeTrue())
+	})
+
+})
@@ -0,0 +1,149 @@
+package epaxos_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/bbengfort/epaxos"
+	"github.com/bbengfort/epaxos/pb"
+)
+
+var _ = Describe("Transactions", func() {
+
+	var (
+		port   = 49264
+		client *Client
+	)
+
+	BeforeEach(func() {
+		network := runNetwork(port)
+		port += len(network)
+
+		var err error
+		client, err = NewClient("alpha", &Config{Timeout: "2s", Peers: network})
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("should apply multiple keys atomically", func() {
+		results, err := client.Transact(
+			&pb.Operation{Type: pb.AccessType_CHECK, Key: "alice"},
+			&pb.Operation{Type: pb.AccessType_WRITE, Key: "alice", Value: []byte("10")},
+			&pb.Operation{Type: pb.AccessType_WRITE, Key: "bob", Value: []byte("20")},
+			&pb.Operation{Type: pb.AccessType_READ, Key: "alice"},
+		)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(results).Should(HaveLen(4))
+		Ω(results[0].Version).Should(BeZero())
+		Ω(results[3].Value).Should(Equal([]byte("10")))
+		Ω(results[3].Version).Should(Equal(results[1].Version))
+		Ω(results[2].Version).Should(BeNumerically(">", results[1].Version))
+
+		// Compare-and-set both keys on the versions that were read
+		alice, bob := results[1].Version, results[2].Version
+		results, err = client.Transact(
+			&pb.Operation{Type: pb.AccessType_CHECK, Key: "alice", Version: alice},
+			&pb.Operation{Type: pb.AccessType_CHECK, Key: "bob", Version: bob},
+			&pb.Operation{Type: pb.AccessType_WRITE, Key: "alice", Value: []byte("5")},
+			&pb.Operation{Type: pb.AccessType_WRITE, Key: "bob", Value: []byte("25")},
+		)
+		Ω(err).ShouldNot(HaveOccurred())
+		for _, res := range results {
+			Ω(res.Success).Should(BeTrue())
+		}
+
+		val, err := client.Get("bob")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(val).Should(Equal([]byte("25")))
+	})
+
+	It("should not apply any operation if a condition is not met", func() {
+		Ω(client.Put("alice", []byte("10"), false)).Should(Succeed())
+
+		results, err := client.Transact(
+			&pb.Operation{Type: pb.AccessType_WRITE, Key: "bob", Value: []byte("20")},
+			&pb.Operation{Type: pb.AccessType_CHECK, Key: "alice", Version: 42},
+			&pb.Operation{Type: pb.AccessType_DELETE, Key: "alice"},
+		)
+		Ω(IsRejected(err)).Should(BeTrue())
+		Ω(results).Should(HaveLen(3))
+		Ω(results[0].Error).Should(Equal("transaction aborted"))
+		Ω(results[1].Error).Should(ContainSubstring("not 42"))
+		Ω(results[1].Version).ShouldNot(BeZero())
+
+		val, err := client.Get("alice")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(val).Should(Equal([]byte("10")))
+
+		val, err = client.Get("bob")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(val).Should(BeEmpty())
+	})
+
+	It("should roll back operations applied before a failed operation", func() {
+		Ω(client.Put("name", []byte("alice"), false)).Should(Succeed())
+
+		results, err := client.Transact(
+			&pb.Operation{Type: pb.AccessType_WRITE, Key: "count", Value: []byte("1")},
+			&pb.Operation{Type: pb.AccessType_INCREMENT, Key: "count"},
+			&pb.Operation{Type: pb.AccessType_INCREMENT, Key: "name"},
+		)
+		Ω(IsRejected(err)).Should(BeTrue())
+		Ω(results[1].Error).Should(Equal("transaction aborted"))
+		Ω(results[2].Error).Should(ContainSubstring("not an integer"))
+
+		val, err := client.Get("count")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(val).Should(BeEmpty())
+	})
+
+	Describe("Conditional Operations", func() {
+
+		It("should compare and swap on the value or version", func() {
+			version, err := client.CompareAndSwap("lock", nil, 0, []byte("alice"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(version).ShouldNot(BeZero())
+
+			_, err = client.CompareAndSwap("lock", nil, 0, []byte("bob"))
+			Ω(IsRejected(err)).Should(BeTrue())
+
+			_, err = client.CompareAndSwap("lock", []byte("bob"), 0, nil)
+			Ω(IsRejected(err)).Should(BeTrue())
+
+			next, err := client.CompareAndSwap("lock", []byte("alice"), 0, []byte("bob"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(next).Should(BeNumerically(">", version))
+
+			_, err = client.CompareAndSwap("lock", nil, next, []byte("carol"))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			val, err := client.Get("lock")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(val).Should(Equal([]byte("carol")))
+		})
+
+		It("should only put absent keys", func() {
+			Ω(client.PutIfAbsent("foo", []byte("bar"))).Should(Succeed())
+			err := client.PutIfAbsent("foo", []byte("baz"))
+			Ω(IsRejected(err)).Should(BeTrue())
+
+			Ω(client.Del("foo")).Should(Succeed())
+			Ω(client.PutIfAbsent("foo", []byte("baz"))).Should(Succeed())
+		})
+
+		It("should increment counters atomically", func() {
+			errs := make(chan error, 20)
+			for i := 0; i < 20; i++ {
+				go func() {
+					_, err := client.Increment("counter", 2)
+					errs <- err
+				}()
+			}
+
+			for i := 0; i < 20; i++ {
+				Ω(<-errs).ShouldNot(HaveOccurred())
+			}
+
+			Ω(client.Increment("counter", -10)).Should(Equal(int64(30)))
+		})
+	})
+})